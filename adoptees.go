@@ -11,21 +11,55 @@ type AdopteesService service
 
 // Adoptee represents an adoptee within an Animal Rescue organization.
 type Adoptee struct {
-	ID     int    `json:"id,omitempty"`
-	Name   string `json:"name,omitempty"`
-	Breed  string `json:"breed,omitempty"`
-	Gender string `json:"gender,omitempty"`
-	Age    string `json:"age,omitempty"`
+	ID     int      `json:"id,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Breed  string   `json:"breed,omitempty"`
+	Gender Gender   `json:"gender,omitempty"`
+	Age    AgeGroup `json:"age,omitempty"`
 }
 
 func (a Adoptee) String() string {
 	return Stringify(a)
 }
 
-// ListAll lists all of the adoptees for an animal rescue.
-func (s *AdopteesService) ListAll(ctx context.Context) ([]*Adoptee, *Response, error) {
-	u := "adoptees"
-	req, err := s.client.NewRequest("GET", u, nil)
+// AdopteeListOptions specifies the optional parameters to the
+// AdopteesService.ListAll and AdopteesService.Iter methods.
+type AdopteeListOptions struct {
+	// Breed filters adoptees by breed, ignoring case.
+	Breed string `url:"breed,omitempty"`
+
+	// Gender filters adoptees by gender.
+	Gender Gender `url:"gender,omitempty"`
+
+	// Age filters adoptees by age group.
+	Age AgeGroup `url:"age,omitempty"`
+
+	// Name filters adoptees by name, ignoring case.
+	Name string `url:"name,omitempty"`
+
+	// Sort indicates how to sort the results. Can be one of id, name, breed
+	// or age. Default: id.
+	Sort string `url:"sort,omitempty"`
+
+	// Direction in which to sort the results. Can be either asc or desc.
+	// Default: asc.
+	Direction string `url:"direction,omitempty"`
+
+	ListOptions
+}
+
+// ListAll lists all of the adoptees for an animal rescue, filtered and sorted
+// according to opts.
+func (s *AdopteesService) ListAll(ctx context.Context, opts *AdopteeListOptions, reqOpts ...RequestOption) ([]*Adoptee, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptees.ListAll", "adoptee", 0)
+	defer span.End()
+
+	u, err := addOptions("adoptees", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -38,10 +72,53 @@ func (s *AdopteesService) ListAll(ctx context.Context) ([]*Adoptee, *Response, e
 	return adoptees, resp, nil
 }
 
+// AdopteeIterator iterates over the adoptees of an animal rescue, fetching
+// pages of results from the API as they are needed.
+type AdopteeIterator struct {
+	iterator
+}
+
+// Value returns the adoptee at the current position of the iterator.
+func (it *AdopteeIterator) Value() *Adoptee {
+	v, _ := it.cur.(*Adoptee)
+	return v
+}
+
+// WithPrefetch makes the iterator fetch the next page of results in the
+// background while the current one is consumed. It must be called before
+// the first call to Next.
+func (it *AdopteeIterator) WithPrefetch() *AdopteeIterator {
+	it.prefetch = true
+	return it
+}
+
+// Iter returns an iterator over all of the adoptees for an animal rescue,
+// filtered and sorted according to opts and starting from the page it
+// describes. Iteration stops when ctx is canceled.
+func (s *AdopteesService) Iter(ctx context.Context, opts *AdopteeListOptions, reqOpts ...RequestOption) *AdopteeIterator {
+	var o AdopteeListOptions
+	if opts != nil {
+		o = *opts
+	}
+	return &AdopteeIterator{newIterator(ctx, &o.ListOptions, func(ctx context.Context, page *ListOptions) ([]interface{}, *Response, error) {
+		opts := o
+		opts.ListOptions = *page
+		adoptees, resp, err := s.ListAll(ctx, &opts, reqOpts...)
+		items := make([]interface{}, len(adoptees))
+		for i, v := range adoptees {
+			items[i] = v
+		}
+		return items, resp, err
+	})}
+}
+
 // GetAdopteeByID fetches an adoptee by ID.
-func (s *AdopteesService) GetAdopteeByID(ctx context.Context, adopteeID int64) (*Adoptee, *Response, error) {
+func (s *AdopteesService) GetAdopteeByID(ctx context.Context, adopteeID int64, reqOpts ...RequestOption) (*Adoptee, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptees.GetAdopteeByID", "adoptee", adopteeID)
+	defer span.End()
+
 	u := fmt.Sprintf("adoptee/%v", adopteeID)
-	req, err := s.client.NewRequest("GET", u, nil)
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -57,16 +134,22 @@ func (s *AdopteesService) GetAdopteeByID(ctx context.Context, adopteeID int64) (
 
 // NewAdoptee represents an adoptee to be created or modified.
 type NewAdoptee struct {
-	Name   string `json:"name,omitempty"`
-	Breed  string `json:"breed,omitempty"`
-	Gender string `json:"gender,omitempty"`
-	Age    string `json:"age,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Breed  string   `json:"breed,omitempty"`
+	Gender Gender   `json:"gender,omitempty"`
+	Age    AgeGroup `json:"age,omitempty"`
 }
 
 // CreateAdoptee creates a new adoptee within an animal rescue.
-func (s *AdopteesService) CreateAdoptee(ctx context.Context, adoptee NewAdoptee) (*Adoptee, *Response, error) {
+func (s *AdopteesService) CreateAdoptee(ctx context.Context, adoptee NewAdoptee, reqOpts ...RequestOption) (*Adoptee, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptees.CreateAdoptee", "adoptee", 0)
+	defer span.End()
+
 	u := "adoptees"
-	req, err := s.client.NewRequest("POST", u, adoptee)
+	if err := s.client.validate(ctx, adoptee); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("POST", u, adoptee, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -80,9 +163,15 @@ func (s *AdopteesService) CreateAdoptee(ctx context.Context, adoptee NewAdoptee)
 }
 
 // EditAdopteeByID edits an adoptee selected by ID.
-func (s *AdopteesService) EditAdopteeByID(ctx context.Context, adopteeID int64, adoptee NewAdoptee) (*Adoptee, *Response, error) {
+func (s *AdopteesService) EditAdopteeByID(ctx context.Context, adopteeID int64, adoptee NewAdoptee, reqOpts ...RequestOption) (*Adoptee, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptees.EditAdopteeByID", "adoptee", adopteeID)
+	defer span.End()
+
 	u := fmt.Sprintf("adoptee/%v", adopteeID)
-	req, err := s.client.NewRequest("PATCH", u, adoptee)
+	if err := s.client.validate(ctx, adoptee); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("PATCH", u, adoptee, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -98,12 +187,71 @@ func (s *AdopteesService) EditAdopteeByID(ctx context.Context, adopteeID int64,
 }
 
 // DeleteAdopteeByID deletes an adoptee referenced by ID.
-func (s *AdopteesService) DeleteAdopteeByID(ctx context.Context, adopteeID int64) (*Response, error) {
-	u := fmt.Sprintf("/adoptee/%v", adopteeID)
-	req, err := s.client.NewRequest("DELETE", u, nil)
+func (s *AdopteesService) DeleteAdopteeByID(ctx context.Context, adopteeID int64, reqOpts ...RequestOption) (*Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptees.DeleteAdopteeByID", "adoptee", adopteeID)
+	defer span.End()
+
+	u := fmt.Sprintf("adoptee/%v", adopteeID)
+	req, err := s.client.NewRequest("DELETE", u, nil, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return s.client.Do(ctx, req, nil)
 }
+
+// AdopteeResult is the outcome of the item at Index of a CreateMany or
+// EditMany call on the AdopteesService.
+type AdopteeResult struct {
+	Index    int
+	Adoptee  *Adoptee
+	Response *Response
+	Err      error
+}
+
+// CreateMany creates each of adoptees, sending up to opts.Concurrency
+// requests at once. The result at index i reports the outcome of adoptees[i].
+func (s *AdopteesService) CreateMany(ctx context.Context, adoptees []NewAdoptee, opts BatchOptions, reqOpts ...RequestOption) []AdopteeResult {
+	results := make([]AdopteeResult, len(adoptees))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(adoptees), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.Adoptee, r.Response, r.Err = s.CreateAdoptee(ctx, adoptees[i], reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// AdopteeEdit pairs an adoptee ID with the edits to apply to it.
+type AdopteeEdit struct {
+	ID      int64
+	Adoptee NewAdoptee
+}
+
+// EditMany applies each of edits, sending up to opts.Concurrency requests at
+// once. The result at index i reports the outcome of edits[i].
+func (s *AdopteesService) EditMany(ctx context.Context, edits []AdopteeEdit, opts BatchOptions, reqOpts ...RequestOption) []AdopteeResult {
+	results := make([]AdopteeResult, len(edits))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(edits), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.Adoptee, r.Response, r.Err = s.EditAdopteeByID(ctx, edits[i].ID, edits[i].Adoptee, reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// DeleteMany deletes the adoptees referenced by ids, sending up to
+// opts.Concurrency requests at once. The result at index i reports the
+// outcome of deleting ids[i].
+func (s *AdopteesService) DeleteMany(ctx context.Context, ids []int64, opts BatchOptions, reqOpts ...RequestOption) []DeleteResult {
+	return deleteMany(ctx, ids, opts, s.DeleteAdopteeByID, reqOpts)
+}
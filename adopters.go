@@ -2,6 +2,7 @@ package animalrescue
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 )
 
@@ -16,8 +17,8 @@ type Adopter struct {
 	LastName       *string          `json:"last_name,omitempty"`
 	Phone          *string          `json:"phone,omitempty"`
 	Email          *string          `json:"email,omitempty"`
-	Gender         *string          `json:"gender,omitempty"`
-	Birthdate      *string          `json:"birthdate,omitempty"`
+	Gender         *Gender          `json:"gender,omitempty"`
+	Birthdate      *Timestamp       `json:"birthdate,omitempty"`
 	Address        *string          `json:"address,omitempty"`
 	Country        *string          `json:"country,omitempty"`
 	State          *string          `json:"state,omitempty"`
@@ -30,10 +31,56 @@ func (a Adopter) String() string {
 	return Stringify(a)
 }
 
-// ListAll lists all of the adopters for an animal rescue.
-func (s *AdoptersService) ListAll(ctx context.Context) ([]*Adopter, *Response, error) {
-	u := "adopters"
-	req, err := s.client.NewRequest("GET", u, nil)
+// MarshalJSON encodes a with its Birthdate as a date alone.
+func (a Adopter) MarshalJSON() ([]byte, error) {
+	type adopter Adopter // without this method
+	return json.Marshal(struct {
+		adopter
+		Birthdate *dateOnly `json:"birthdate,omitempty"`
+	}{adopter(a), (*dateOnly)(a.Birthdate)})
+}
+
+// AdopterListOptions specifies the optional parameters to the
+// AdoptersService.ListAll and AdoptersService.Iter methods.
+type AdopterListOptions struct {
+	// State filters adopters by state, ignoring case.
+	State string `url:"state,omitempty"`
+
+	// City filters adopters by city, ignoring case.
+	City string `url:"city,omitempty"`
+
+	// Country filters adopters by country, ignoring case.
+	Country string `url:"country,omitempty"`
+
+	// ZipCode filters adopters by zip code.
+	ZipCode string `url:"zip_code,omitempty"`
+
+	// Email filters adopters by email address, ignoring case.
+	Email string `url:"email,omitempty"`
+
+	// Sort indicates how to sort the results. Can be one of id,
+	// first_name, last_name, state or city. Default: id.
+	Sort string `url:"sort,omitempty"`
+
+	// Direction in which to sort the results. Can be either asc or desc.
+	// Default: asc.
+	Direction string `url:"direction,omitempty"`
+
+	ListOptions
+}
+
+// ListAll lists all of the adopters for an animal rescue, filtered and sorted
+// according to opts.
+func (s *AdoptersService) ListAll(ctx context.Context, opts *AdopterListOptions, reqOpts ...RequestOption) ([]*Adopter, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adopters.ListAll", "adopter", 0)
+	defer span.End()
+
+	u, err := addOptions("adopters", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -46,10 +93,53 @@ func (s *AdoptersService) ListAll(ctx context.Context) ([]*Adopter, *Response, e
 	return adopters, resp, nil
 }
 
+// AdopterIterator iterates over the adopters of an animal rescue, fetching
+// pages of results from the API as they are needed.
+type AdopterIterator struct {
+	iterator
+}
+
+// Value returns the adopter at the current position of the iterator.
+func (it *AdopterIterator) Value() *Adopter {
+	v, _ := it.cur.(*Adopter)
+	return v
+}
+
+// WithPrefetch makes the iterator fetch the next page of results in the
+// background while the current one is consumed. It must be called before
+// the first call to Next.
+func (it *AdopterIterator) WithPrefetch() *AdopterIterator {
+	it.prefetch = true
+	return it
+}
+
+// Iter returns an iterator over all of the adopters for an animal rescue,
+// filtered and sorted according to opts and starting from the page it
+// describes. Iteration stops when ctx is canceled.
+func (s *AdoptersService) Iter(ctx context.Context, opts *AdopterListOptions, reqOpts ...RequestOption) *AdopterIterator {
+	var o AdopterListOptions
+	if opts != nil {
+		o = *opts
+	}
+	return &AdopterIterator{newIterator(ctx, &o.ListOptions, func(ctx context.Context, page *ListOptions) ([]interface{}, *Response, error) {
+		opts := o
+		opts.ListOptions = *page
+		adopters, resp, err := s.ListAll(ctx, &opts, reqOpts...)
+		items := make([]interface{}, len(adopters))
+		for i, v := range adopters {
+			items[i] = v
+		}
+		return items, resp, err
+	})}
+}
+
 // GetAdopterByID fetches an adopter by ID.
-func (s *AdoptersService) GetAdopterByID(ctx context.Context, adopterID int64) (*Adopter, *Response, error) {
+func (s *AdoptersService) GetAdopterByID(ctx context.Context, adopterID int64, reqOpts ...RequestOption) (*Adopter, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adopters.GetAdopterByID", "adopter", adopterID)
+	defer span.End()
+
 	u := fmt.Sprintf("adopter/%v", adopterID)
-	req, err := s.client.NewRequest("GET", u, nil)
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -69,8 +159,8 @@ type NewAdopter struct {
 	LastName       *string          `json:"last_name,omitempty"`
 	Phone          *string          `json:"phone,omitempty"`
 	Email          *string          `json:"email,omitempty"`
-	Gender         *string          `json:"gender,omitempty"`
-	Birthdate      *string          `json:"birthdate,omitempty"`
+	Gender         *Gender          `json:"gender,omitempty"`
+	Birthdate      *Timestamp       `json:"birthdate,omitempty"`
 	Address        *string          `json:"address,omitempty"`
 	Country        *string          `json:"country,omitempty"`
 	State          *string          `json:"state,omitempty"`
@@ -79,10 +169,25 @@ type NewAdopter struct {
 	PetPreferences []*PetPreference `json:"pet_preferences,omitempty"`
 }
 
+// MarshalJSON encodes a with its Birthdate as a date alone.
+func (a NewAdopter) MarshalJSON() ([]byte, error) {
+	type newAdopter NewAdopter // without this method
+	return json.Marshal(struct {
+		newAdopter
+		Birthdate *dateOnly `json:"birthdate,omitempty"`
+	}{newAdopter(a), (*dateOnly)(a.Birthdate)})
+}
+
 // CreateAdopter creates a new adopter within an animal rescue.
-func (s *AdoptersService) CreateAdopter(ctx context.Context, adopter NewAdopter) (*Adopter, *Response, error) {
+func (s *AdoptersService) CreateAdopter(ctx context.Context, adopter NewAdopter, reqOpts ...RequestOption) (*Adopter, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adopters.CreateAdopter", "adopter", 0)
+	defer span.End()
+
 	u := "adopters"
-	req, err := s.client.NewRequest("POST", u, adopter)
+	if err := s.client.validate(ctx, adopter); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("POST", u, adopter, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -96,9 +201,15 @@ func (s *AdoptersService) CreateAdopter(ctx context.Context, adopter NewAdopter)
 }
 
 // EditAdopterByID edits an adopter by ID.
-func (s *AdoptersService) EditAdopterByID(ctx context.Context, adopterID int64, adopter NewAdopter) (*Adopter, *Response, error) {
+func (s *AdoptersService) EditAdopterByID(ctx context.Context, adopterID int64, adopter NewAdopter, reqOpts ...RequestOption) (*Adopter, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adopters.EditAdopterByID", "adopter", adopterID)
+	defer span.End()
+
 	u := fmt.Sprintf("adopter/%v", adopterID)
-	req, err := s.client.NewRequest("PATCH", u, adopter)
+	if err := s.client.validate(ctx, adopter); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("PATCH", u, adopter, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -114,12 +225,71 @@ func (s *AdoptersService) EditAdopterByID(ctx context.Context, adopterID int64,
 }
 
 // DeleteAdopterByID deletes an adopter referenced by ID
-func (s *AdoptersService) DeleteAdopterByID(ctx context.Context, adopterID int64) (*Response, error) {
-	u := fmt.Sprintf("/adopter/%v", adopterID)
-	req, err := s.client.NewRequest("DELETE", u, nil)
+func (s *AdoptersService) DeleteAdopterByID(ctx context.Context, adopterID int64, reqOpts ...RequestOption) (*Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adopters.DeleteAdopterByID", "adopter", adopterID)
+	defer span.End()
+
+	u := fmt.Sprintf("adopter/%v", adopterID)
+	req, err := s.client.NewRequest("DELETE", u, nil, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return s.client.Do(ctx, req, nil)
 }
+
+// AdopterResult is the outcome of the item at Index of a CreateMany or
+// EditMany call on the AdoptersService.
+type AdopterResult struct {
+	Index    int
+	Adopter  *Adopter
+	Response *Response
+	Err      error
+}
+
+// CreateMany creates each of adopters, sending up to opts.Concurrency
+// requests at once. The result at index i reports the outcome of adopters[i].
+func (s *AdoptersService) CreateMany(ctx context.Context, adopters []NewAdopter, opts BatchOptions, reqOpts ...RequestOption) []AdopterResult {
+	results := make([]AdopterResult, len(adopters))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(adopters), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.Adopter, r.Response, r.Err = s.CreateAdopter(ctx, adopters[i], reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// AdopterEdit pairs an adopter ID with the edits to apply to it.
+type AdopterEdit struct {
+	ID      int64
+	Adopter NewAdopter
+}
+
+// EditMany applies each of edits, sending up to opts.Concurrency requests at
+// once. The result at index i reports the outcome of edits[i].
+func (s *AdoptersService) EditMany(ctx context.Context, edits []AdopterEdit, opts BatchOptions, reqOpts ...RequestOption) []AdopterResult {
+	results := make([]AdopterResult, len(edits))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(edits), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.Adopter, r.Response, r.Err = s.EditAdopterByID(ctx, edits[i].ID, edits[i].Adopter, reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// DeleteMany deletes the adopters referenced by ids, sending up to
+// opts.Concurrency requests at once. The result at index i reports the
+// outcome of deleting ids[i].
+func (s *AdoptersService) DeleteMany(ctx context.Context, ids []int64, opts BatchOptions, reqOpts ...RequestOption) []DeleteResult {
+	return deleteMany(ctx, ids, opts, s.DeleteAdopterByID, reqOpts)
+}
@@ -13,20 +13,55 @@ type AdoptionsService service
 // Adoption represents an adoption event within an animal rescue. Adoptions
 // are used to store adopter and adoptee relationships.
 type Adoption struct {
-	ID        int      `json:"id,omitempty"`
-	Adopter   *Adopter `json:"adopter,omitempty"`
-	Adoptee   *Adoptee `json:"adoptee,omitempty"`
-	CreatedAt string   `json:"created_at,omitempty"`
+	ID        int        `json:"id,omitempty"`
+	Adopter   *Adopter   `json:"adopter,omitempty"`
+	Adoptee   *Adoptee   `json:"adoptee,omitempty"`
+	CreatedAt *Timestamp `json:"created_at,omitempty"`
 }
 
 func (a Adoption) String() string {
 	return Stringify(a)
 }
 
-// ListAll lists all of the adoptions for an animal rescue.
-func (s *AdoptionsService) ListAll(ctx context.Context) ([]*Adoption, *Response, error) {
-	u := "adoptions"
-	req, err := s.client.NewRequest("GET", u, nil)
+// AdoptionListOptions specifies the optional parameters to the
+// AdoptionsService.ListAll and AdoptionsService.Iter methods.
+type AdoptionListOptions struct {
+	// AdopterID filters adoptions by the ID of their adopter.
+	AdopterID int64 `url:"adopter_id,omitempty"`
+
+	// AdopteeID filters adoptions by the ID of their adoptee.
+	AdopteeID int64 `url:"adoptee_id,omitempty"`
+
+	// CreatedAfter only returns adoptions created at or after the given
+	// time.
+	CreatedAfter time.Time `url:"created_after,omitempty"`
+
+	// CreatedBefore only returns adoptions created before the given time.
+	CreatedBefore time.Time `url:"created_before,omitempty"`
+
+	// Sort indicates how to sort the results. Can be either id or
+	// created_at. Default: id.
+	Sort string `url:"sort,omitempty"`
+
+	// Direction in which to sort the results. Can be either asc or desc.
+	// Default: asc.
+	Direction string `url:"direction,omitempty"`
+
+	ListOptions
+}
+
+// ListAll lists all of the adoptions for an animal rescue, filtered and sorted
+// according to opts.
+func (s *AdoptionsService) ListAll(ctx context.Context, opts *AdoptionListOptions, reqOpts ...RequestOption) ([]*Adoption, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptions.ListAll", "adoption", 0)
+	defer span.End()
+
+	u, err := addOptions("adoptions", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -39,10 +74,53 @@ func (s *AdoptionsService) ListAll(ctx context.Context) ([]*Adoption, *Response,
 	return adoptions, resp, nil
 }
 
+// AdoptionIterator iterates over the adoptions of an animal rescue, fetching
+// pages of results from the API as they are needed.
+type AdoptionIterator struct {
+	iterator
+}
+
+// Value returns the adoption at the current position of the iterator.
+func (it *AdoptionIterator) Value() *Adoption {
+	v, _ := it.cur.(*Adoption)
+	return v
+}
+
+// WithPrefetch makes the iterator fetch the next page of results in the
+// background while the current one is consumed. It must be called before
+// the first call to Next.
+func (it *AdoptionIterator) WithPrefetch() *AdoptionIterator {
+	it.prefetch = true
+	return it
+}
+
+// Iter returns an iterator over all of the adoptions for an animal rescue,
+// filtered and sorted according to opts and starting from the page it
+// describes. Iteration stops when ctx is canceled.
+func (s *AdoptionsService) Iter(ctx context.Context, opts *AdoptionListOptions, reqOpts ...RequestOption) *AdoptionIterator {
+	var o AdoptionListOptions
+	if opts != nil {
+		o = *opts
+	}
+	return &AdoptionIterator{newIterator(ctx, &o.ListOptions, func(ctx context.Context, page *ListOptions) ([]interface{}, *Response, error) {
+		opts := o
+		opts.ListOptions = *page
+		adoptions, resp, err := s.ListAll(ctx, &opts, reqOpts...)
+		items := make([]interface{}, len(adoptions))
+		for i, v := range adoptions {
+			items[i] = v
+		}
+		return items, resp, err
+	})}
+}
+
 // GetAdoptionByID fetches an adoption by ID.
-func (s *AdoptionsService) GetAdoptionByID(ctx context.Context, adoptionID int64) (*Adoption, *Response, error) {
+func (s *AdoptionsService) GetAdoptionByID(ctx context.Context, adoptionID int64, reqOpts ...RequestOption) (*Adoption, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptions.GetAdoptionByID", "adoption", adoptionID)
+	defer span.End()
+
 	u := fmt.Sprintf("adoption/%v", adoptionID)
-	req, err := s.client.NewRequest("GET", u, nil)
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -60,13 +138,16 @@ func (s *AdoptionsService) GetAdoptionByID(ctx context.Context, adoptionID int64
 type NewAdoption struct {
 	Adopter   *Adopter   `json:"adopter,omitempty"`
 	Adoptee   *Adoptee   `json:"adoptee,omitempty"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
+	CreatedAt *Timestamp `json:"created_at,omitempty"`
 }
 
 // CreateAdoption creates a new adoption within an animal rescue.
-func (s *AdoptionsService) CreateAdoption(ctx context.Context, adoption NewAdoption) (*Adoption, *Response, error) {
+func (s *AdoptionsService) CreateAdoption(ctx context.Context, adoption NewAdoption, reqOpts ...RequestOption) (*Adoption, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptions.CreateAdoption", "adoption", 0)
+	defer span.End()
+
 	u := "adoptions"
-	req, err := s.client.NewRequest("POST", u, adoption)
+	req, err := s.client.NewRequest("POST", u, adoption, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -80,12 +161,48 @@ func (s *AdoptionsService) CreateAdoption(ctx context.Context, adoption NewAdopt
 }
 
 // DeleteAdoptionByID delets an adoption referenced by ID.
-func (s *AdoptionsService) DeleteAdoptionByID(ctx context.Context, adoptionID int64) (*Response, error) {
-	u := fmt.Sprintf("/adoption/%v", adoptionID)
-	req, err := s.client.NewRequest("DELETE", u, nil)
+func (s *AdoptionsService) DeleteAdoptionByID(ctx context.Context, adoptionID int64, reqOpts ...RequestOption) (*Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Adoptions.DeleteAdoptionByID", "adoption", adoptionID)
+	defer span.End()
+
+	u := fmt.Sprintf("adoption/%v", adoptionID)
+	req, err := s.client.NewRequest("DELETE", u, nil, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return s.client.Do(ctx, req, nil)
 }
+
+// AdoptionResult is the outcome of the item at Index of a CreateMany call on
+// the AdoptionsService.
+type AdoptionResult struct {
+	Index    int
+	Adoption *Adoption
+	Response *Response
+	Err      error
+}
+
+// CreateMany creates each of adoptions, sending up to opts.Concurrency
+// requests at once. The result at index i reports the outcome of adoptions[i].
+func (s *AdoptionsService) CreateMany(ctx context.Context, adoptions []NewAdoption, opts BatchOptions, reqOpts ...RequestOption) []AdoptionResult {
+	results := make([]AdoptionResult, len(adoptions))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(adoptions), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.Adoption, r.Response, r.Err = s.CreateAdoption(ctx, adoptions[i], reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// DeleteMany deletes the adoptions referenced by ids, sending up to
+// opts.Concurrency requests at once. The result at index i reports the
+// outcome of deleting ids[i].
+func (s *AdoptionsService) DeleteMany(ctx context.Context, ids []int64, opts BatchOptions, reqOpts ...RequestOption) []DeleteResult {
+	return deleteMany(ctx, ids, opts, s.DeleteAdoptionByID, reqOpts)
+}
@@ -3,6 +3,7 @@ package animalrescue
 import (
 	"bytes"
 	"context"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,7 +11,11 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -25,6 +30,48 @@ type Client struct {
 	BaseURL   *url.URL
 	UserAgent string
 
+	// Header holds headers added to every request created by NewRequest.
+	Header http.Header
+
+	// Logger, if set, receives a line for every request sent, along with
+	// diagnostic messages such as retry notices.
+	Logger Logger
+
+	// LogBodies makes the client log request and response bodies as well.
+	// Adopter PII is redacted from logged bodies and URLs; see RedactPII.
+	LogBodies bool
+
+	// RetryPolicy controls how failed requests are retried. A nil policy,
+	// the default, sends each request exactly once.
+	RetryPolicy *RetryPolicy
+
+	// WaitForRateLimit makes the client hold requests back until the rate
+	// limit resets once the API reports that it has been used up, instead
+	// of sending them only to have them rejected.
+	WaitForRateLimit bool
+
+	// ValidateRequests makes Create* and Edit*ByID methods validate the
+	// entity with its Validate method before sending it, and return the
+	// resulting *ValidationError without making a request.
+	ValidateRequests bool
+
+	// Tracer, if set, starts a span around every service call and adds
+	// the trace context to the headers of its requests. See Tracer.
+	Tracer Tracer
+
+	// Metrics, if set, receives measurements of every request sent.
+	Metrics Metrics
+
+	// ApplicationStore holds the applications managed by Applications,
+	// which the API does not store. NewClient sets it to a new
+	// MemoryApplicationStore; use a FileApplicationStore, or a store of
+	// your own shared by every client, to keep them.
+	ApplicationStore ApplicationStore
+
+	rateMu           sync.Mutex
+	rate             Rate      // rate limit reported by the last response
+	rateBlockedUntil time.Time // requests wait until then if WaitForRateLimit is set
+
 	common service // Resuse a single struct instead of allocating one for each service in the heap
 
 	// Services used for talking to different parts of the AnimalRescue API
@@ -32,6 +79,7 @@ type Client struct {
 	Adopters       *AdoptersService
 	Adoptees       *AdopteesService
 	Adoptions      *AdoptionsService
+	Applications   *ApplicationsService
 	PetPreferences *PetPreferencesService
 }
 
@@ -39,6 +87,136 @@ type service struct {
 	client *Client
 }
 
+// ListOptions specifies the optional parameters to various ListAll methods that
+// support pagination.
+type ListOptions struct {
+	// For paginated result sets, page of results to retrieve.
+	Page int `url:"page,omitempty"`
+
+	// For paginated result sets, the number of results to include per page.
+	PerPage int `url:"per_page,omitempty"`
+
+	// For cursor-paginated result sets, the opaque cursor returned in the
+	// NextCursor field of a previous Response. Takes precedence over Page.
+	Cursor string `url:"cursor,omitempty"`
+}
+
+// addOptions adds the parameters in opts as URL query parameters to s. opts
+// must be a struct whose fields may contain "url" tags.
+func addOptions(s string, opts interface{}) (string, error) {
+	v := reflect.ValueOf(opts)
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return s, nil
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return s, err
+	}
+
+	qs := u.Query()
+	if err := encodeQuery(qs, reflect.Indirect(v)); err != nil {
+		return s, err
+	}
+
+	u.RawQuery = qs.Encode()
+	return u.String(), nil
+}
+
+// encodeQuery adds the "url"-tagged fields of the struct v to qs. Embedded
+// structs are flattened into the same set of values.
+func encodeQuery(qs url.Values, v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("options must be a struct, got %v", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous { // unexported
+			continue
+		}
+
+		tag := sf.Tag.Get("url")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx != -1 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		if sf.Anonymous && name == "" {
+			fv = reflect.Indirect(fv)
+			if fv.Kind() == reflect.Struct {
+				if err := encodeQuery(qs, fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		// A non-nil pointer is sent even if it points to an empty value,
+		// so that zero values can be set explicitly.
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		} else if strings.Contains(opts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+
+		// Times and the typed enumerations encode themselves.
+		if m, ok := fv.Interface().(encoding.TextMarshaler); ok {
+			text, err := m.MarshalText()
+			if err != nil {
+				return err
+			}
+			qs.Add(name, string(text))
+			continue
+		}
+
+		switch fv.Kind() {
+		case reflect.String:
+			qs.Add(name, fv.String())
+		case reflect.Bool:
+			qs.Add(name, strconv.FormatBool(fv.Bool()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			qs.Add(name, strconv.FormatInt(fv.Int(), 10))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			qs.Add(name, strconv.FormatUint(fv.Uint(), 10))
+		default:
+			qs.Add(name, fmt.Sprint(fv.Interface()))
+		}
+	}
+	return nil
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		return v.IsZero()
+	}
+	return false
+}
+
 // NewClient returns a new Animal Rescue API client.
 func NewClient(httpClient *http.Client) *Client {
 	if httpClient == nil {
@@ -47,22 +225,65 @@ func NewClient(httpClient *http.Client) *Client {
 
 	baseURL, _ := url.Parse(defaultBaseURL)
 
-	c := &Client{client: httpClient, BaseURL: baseURL, UserAgent: userAgent}
+	c := &Client{
+		client:           httpClient,
+		BaseURL:          baseURL,
+		UserAgent:        userAgent,
+		ApplicationStore: NewMemoryApplicationStore(),
+	}
+	c.initialize()
+	return c
+}
 
+// initialize wires up the services of c.
+func (c *Client) initialize() {
 	c.common.client = c
 	c.Adopters = (*AdoptersService)(&c.common)
 	c.Adoptees = (*AdopteesService)(&c.common)
 	c.Adoptions = (*AdoptionsService)(&c.common)
+	c.Applications = (*ApplicationsService)(&c.common)
 	c.PetPreferences = (*PetPreferencesService)(&c.common)
-	return c
+}
+
+// copy returns a copy of the current client. It must be initialized before
+// use.
+func (c *Client) copy() *Client {
+	clone := *c.client
+	baseURL := *c.BaseURL
+	return &Client{
+		client:           &clone,
+		BaseURL:          &baseURL,
+		UserAgent:        c.UserAgent,
+		Header:           c.Header.Clone(),
+		Logger:           c.Logger,
+		LogBodies:        c.LogBodies,
+		RetryPolicy:      c.RetryPolicy,
+		WaitForRateLimit: c.WaitForRateLimit,
+		ValidateRequests: c.ValidateRequests,
+		Tracer:           c.Tracer,
+		Metrics:          c.Metrics,
+		ApplicationStore: c.ApplicationStore,
+	}
+}
+
+// WithAuthToken returns a copy of the client configured to use the provided
+// token for the Authorization header.
+func (c *Client) WithAuthToken(token string) *Client {
+	c2 := c.copy()
+	defer c2.initialize()
+	c2.client.Transport = &BearerTokenTransport{
+		Token:     token,
+		Transport: c2.client.Transport,
+	}
+	return c2
 }
 
 // NewRequest creates an API request. A relative URL can be provided in urlStr,
 // in which case it is resolved relative to the BaseURL of the Client.
 // Relative URLs should always be specified without a preceding slash. If
 // specified, the value pointed to by body is JSON encoded and included as the
-// request body.
-func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+// request body. opts customize the request; see RequestOption.
+func (c *Client) NewRequest(method, urlStr string, body interface{}, opts ...RequestOption) (*http.Request, error) {
 	if !strings.HasSuffix(c.BaseURL.Path, "/") {
 		return nil, fmt.Errorf("BaseURL must have a trailing slash, but %q does not", c.BaseURL)
 	}
@@ -82,11 +303,19 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		}
 	}
 
+	// buf is always a *bytes.Buffer, for which http.NewRequest sets GetBody,
+	// so the body can be replayed when the request is retried.
 	req, err := http.NewRequest(method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
 
+	for k, v := range c.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if req, err = applyRequestOptions(req, opts); err != nil {
+		return nil, err
+	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -97,19 +326,101 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	return req, nil
 }
 
-// Response is a GitHub API response. This wraps the standard http.Response
-// returned from GitHub
+// Response is an Animal Rescue API response. This wraps the standard
+// http.Response returned from the Animal Rescue API and provides convenient
+// access to things like pagination links.
 type Response struct {
 	*http.Response
+
+	// These fields provide the page values for paginating through a set of
+	// results. Any or all of these may be set to the zero value for
+	// responses that are not part of a paginated set, or for which there
+	// are no additional pages.
+	NextPage  int
+	PrevPage  int
+	FirstPage int
+	LastPage  int
+
+	// NextCursor is set for cursor-paginated result sets and should be
+	// passed as ListOptions.Cursor to fetch the following page.
+	NextCursor string
+
+	// Rate is the rate limit reported by the API in this response.
+	Rate Rate
+
+	// Attempts is the number of times the request was sent, including any
+	// retries made according to the Client's RetryPolicy.
+	Attempts int
+
+	// FromCache reports whether the response was served from the cache of
+	// a CachingTransport after the server answered 304 Not Modified.
+	FromCache bool
 }
 
 // newResponse creates a new Response for the provided http.Response.
 // r must not be nil.
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
+	response.populatePageValues()
+	response.Rate = parseRate(r)
+	response.FromCache = r.Header.Get(headerFromCache) != ""
 	return response
 }
 
+// populatePageValues parses the HTTP Link response headers and populates the
+// various pagination link values in the Response.
+func (r *Response) populatePageValues() {
+	if links, ok := r.Response.Header["Link"]; ok && len(links) > 0 {
+		for _, link := range strings.Split(links[0], ",") {
+			segments := strings.Split(strings.TrimSpace(link), ";")
+
+			// link must at least have href and rel
+			if len(segments) < 2 {
+				continue
+			}
+
+			// ensure href is properly formatted
+			if !strings.HasPrefix(segments[0], "<") || !strings.HasSuffix(segments[0], ">") {
+				continue
+			}
+
+			// try to pull out page parameter
+			u, err := url.Parse(segments[0][1 : len(segments[0])-1])
+			if err != nil {
+				continue
+			}
+			q := u.Query()
+
+			if cursor := q.Get("cursor"); cursor != "" {
+				for _, segment := range segments[1:] {
+					if strings.TrimSpace(segment) == `rel="next"` {
+						r.NextCursor = cursor
+					}
+				}
+				continue
+			}
+
+			page := q.Get("page")
+			if page == "" {
+				continue
+			}
+
+			for _, segment := range segments[1:] {
+				switch strings.TrimSpace(segment) {
+				case `rel="next"`:
+					r.NextPage, _ = strconv.Atoi(page)
+				case `rel="prev"`:
+					r.PrevPage, _ = strconv.Atoi(page)
+				case `rel="first"`:
+					r.FirstPage, _ = strconv.Atoi(page)
+				case `rel="last"`:
+					r.LastPage, _ = strconv.Atoi(page)
+				}
+			}
+		}
+	}
+}
+
 // Do sends an API request and returns the API response.
 // The API response is JSON decoded and stored in the value pointed to by v,
 // or returned as an error if an API error has occured.
@@ -117,9 +428,64 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	if ctx == nil {
 		return nil, errors.New("context must be non-nil")
 	}
+	if d, ok := req.Context().Value(timeoutKey{}).(time.Duration); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
+	cl := callFrom(ctx)
+	if c.Tracer != nil {
+		c.Tracer.Inject(ctx, req.Header)
+	}
+	stats := &RequestStats{Service: cl.service, Method: cl.method, HTTPMethod: req.Method}
+	if c.Metrics != nil {
+		c.Metrics.RequestStarted(stats)
+	}
+
+	start := time.Now()
+	response, err := c.do(ctx, req, v, stats)
+	stats.Duration = time.Since(start)
+	stats.Err = err
+	stats.ErrorCodes = errorCodes(ctx, err, stats.Status)
+
+	attrs := []Attribute{
+		{AttributeHTTPMethod, req.Method},
+		{AttributeRetryCount, stats.Retries},
+	}
+	if stats.Status != 0 {
+		attrs = append(attrs, Attribute{AttributeHTTPStatus, stats.Status})
+	}
+	cl.span.SetAttributes(attrs...)
+	if err != nil {
+		cl.span.RecordError(err)
+	}
+	if c.Metrics != nil {
+		c.Metrics.RequestFinished(stats)
+	}
+	return response, err
+}
+
+// do implements Do, once ctx has been checked and the trace context added
+// to req, recording the outcome of the request in stats.
+func (c *Client) do(ctx context.Context, req *http.Request, v interface{}, stats *RequestStats) (*Response, error) {
+	if c.WaitForRateLimit {
+		if err := c.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+	}
 
 	req = withContext(ctx, req)
-	resp, err := c.client.Do(req)
+	resp, attempts, drained, err := c.send(ctx, req)
+	stats.Retries = attempts - 1
+	stats.BytesReceived = drained
+	if req.ContentLength > 0 {
+		stats.BytesSent = req.ContentLength * int64(attempts)
+	}
+	if resp != nil {
+		stats.Status = resp.StatusCode
+		resp.Body = &countingBody{ReadCloser: resp.Body, n: &stats.BytesReceived}
+	}
 
 	if err != nil {
 		select {
@@ -139,6 +505,8 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 	defer resp.Body.Close()
 
 	response := newResponse(resp)
+	response.Attempts = attempts
+	c.updateRate(response)
 	err = CheckResponse(resp)
 	if err != nil {
 		return response, err
@@ -161,16 +529,51 @@ func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Res
 
 // An ErrorResponse reports one or more errors cause by an API request
 type ErrorResponse struct {
-	Response *http.Response
-	Message  service
-	Errors   []Error
+	Response *http.Response // HTTP response that caused this error
+	Message  string         `json:"message"` // error message
+	Errors   []Error        `json:"errors"`  // more detail on individual errors
 }
 
 func (r *ErrorResponse) Error() string {
+	if r.Response == nil || r.Response.Request == nil {
+		return fmt.Sprintf("%v %+v", r.Message, r.Errors)
+	}
 	return fmt.Sprintf("%v: %d %v %+v",
 		r.Response.Request.Method, r.Response.StatusCode, r.Message, r.Errors)
 }
 
+// Is reports whether the HTTP status of the response matches one of the
+// sentinel errors ErrNotFound, ErrConflict, ErrValidation or
+// ErrUnauthorized. Field-level error codes are matched through Unwrap.
+func (r *ErrorResponse) Is(target error) bool {
+	if r.Response == nil {
+		return false
+	}
+	switch target {
+	case ErrNotFound:
+		return r.Response.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return r.Response.StatusCode == http.StatusConflict
+	case ErrValidation:
+		// With field errors present, their codes decide instead.
+		return len(r.Errors) == 0 && (r.Response.StatusCode == http.StatusUnprocessableEntity ||
+			r.Response.StatusCode == http.StatusBadRequest)
+	case ErrUnauthorized:
+		return r.Response.StatusCode == http.StatusUnauthorized ||
+			r.Response.StatusCode == http.StatusForbidden
+	}
+	return false
+}
+
+// Unwrap returns the field-level errors of the response as a
+// *ValidationError, or nil if there are none.
+func (r *ErrorResponse) Unwrap() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: r.Errors}
+}
+
 // An Error reports more details on an individual error
 // Possible validation error codes include:
 // 	- missing:
@@ -213,6 +616,25 @@ func CheckResponse(r *http.Response) error {
 		json.Unmarshal(data, errorResponse)
 	}
 	r.Body = ioutil.NopCloser(bytes.NewBuffer(data))
+
+	if r.StatusCode == http.StatusTooManyRequests {
+		rate := parseRate(r)
+		if r.Header.Get(headerRateRemaining) == "0" {
+			return &RateLimitError{
+				Rate:     rate,
+				Response: r,
+				Message:  errorResponse.Message,
+			}
+		}
+		abuseErr := &AbuseRateLimitError{
+			Response: r,
+			Message:  errorResponse.Message,
+		}
+		if r.Header.Get(headerRetryAfter) != "" {
+			abuseErr.RetryAfter = &rate.RetryAfter
+		}
+		return abuseErr
+	}
 	return errorResponse
 
 }
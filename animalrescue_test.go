@@ -0,0 +1,204 @@
+package animalrescue
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAddOptions(t *testing.T) {
+	type inner struct {
+		Tag string `url:"tag,omitempty"`
+	}
+	type custom struct {
+		Name     string    `url:"name"`
+		Count    *int      `url:"count,omitempty"`
+		Active   bool      `url:"active"`
+		Since    Timestamp `url:"since,omitempty"`
+		Skipped  string    `url:"-"`
+		Untagged int
+		hidden   string
+		*inner
+	}
+	count := 0
+	at := time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		path string
+		opts interface{}
+		want string
+	}{
+		{"nil", "adopters", (*AdopterListOptions)(nil), "adopters"},
+		{"empty", "adopters", &AdopterListOptions{}, "adopters"},
+		{
+			"filters, sort and page",
+			"adopters",
+			&AdopterListOptions{
+				State:       "OR",
+				ZipCode:     "97201",
+				Sort:        "last_name",
+				Direction:   "desc",
+				ListOptions: ListOptions{Page: 2, PerPage: 50},
+			},
+			"adopters?direction=desc&page=2&per_page=50&sort=last_name&state=OR&zip_code=97201",
+		},
+		{
+			"cursor",
+			"adoptees",
+			&AdopteeListOptions{ListOptions: ListOptions{Cursor: "abc=="}},
+			"adoptees?cursor=abc%3D%3D",
+		},
+		{
+			"enumerations",
+			"adoptees",
+			&AdopteeListOptions{Gender: "F", Age: "baby", Breed: "Beagle"},
+			"adoptees?age=puppy&breed=Beagle&gender=female",
+		},
+		{
+			"unknown enumeration",
+			"adoptees",
+			&AdopteeListOptions{Gender: "robot"},
+			"adoptees?gender=robot",
+		},
+		{
+			"IDs and times",
+			"adoptions",
+			&AdoptionListOptions{AdopterID: 1, AdopteeID: 2, CreatedAfter: at},
+			"adoptions?adoptee_id=2&adopter_id=1&created_after=2020-05-01T12%3A30%3A00Z",
+		},
+		{
+			"existing query",
+			"adopters?foo=bar",
+			&AdopterListOptions{City: "Portland"},
+			"adopters?city=Portland&foo=bar",
+		},
+		{
+			"pet preferences",
+			"preferences",
+			&ListOptions{PerPage: 10},
+			"preferences?per_page=10",
+		},
+		{
+			"zero values",
+			"things",
+			&custom{Skipped: "x", hidden: "y"},
+			"things?Untagged=0&active=false&name=",
+		},
+		{
+			"set values",
+			"things",
+			custom{Name: "a b", Count: &count, Active: true, Since: Timestamp{at}, Untagged: 3, inner: &inner{Tag: "t"}},
+			"things?Untagged=3&active=true&count=0&name=a+b&since=2020-05-01T12%3A30%3A00Z&tag=t",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := addOptions(tt.path, tt.opts)
+		if err != nil {
+			t.Errorf("%v: addOptions returned error: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: addOptions = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddOptions_notStruct(t *testing.T) {
+	if _, err := addOptions("adopters", "state=OR"); err == nil {
+		t.Error("addOptions accepted a string")
+	}
+}
+
+func TestResponse_populatePageValues(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want Response
+	}{
+		{"none", "", Response{}},
+		{
+			"all pages",
+			`<https://api.example.com/adopters?page=1>; rel="first",` +
+				` <https://api.example.com/adopters?page=2>; rel="prev",` +
+				` <https://api.example.com/adopters?page=4>; rel="next",` +
+				` <https://api.example.com/adopters?page=5>; rel="last"`,
+			Response{FirstPage: 1, PrevPage: 2, NextPage: 4, LastPage: 5},
+		},
+		{
+			"other parameters",
+			`<https://api.example.com/adopters?per_page=10&page=3&sort=id>; rel="next"`,
+			Response{NextPage: 3},
+		},
+		{
+			"cursor",
+			`<https://api.example.com/adopters?cursor=abc%3D%3D&per_page=10>; rel="next"`,
+			Response{NextCursor: "abc=="},
+		},
+		{
+			"cursor without next",
+			`<https://api.example.com/adopters?cursor=abc>; rel="prev"`,
+			Response{},
+		},
+		{
+			"cursor before page",
+			`<https://api.example.com/adopters?cursor=abc&page=2>; rel="next"`,
+			Response{NextCursor: "abc"},
+		},
+		{
+			"missing rel",
+			`<https://api.example.com/adopters?page=2>, <https://api.example.com/adopters?page=5>; rel="last"`,
+			Response{LastPage: 5},
+		},
+		{
+			"unknown rel",
+			`<https://api.example.com/adopters?page=2>; rel="related"`,
+			Response{},
+		},
+		{
+			"missing page",
+			`<https://api.example.com/adopters>; rel="next"`,
+			Response{},
+		},
+		{
+			"page not a number",
+			`<https://api.example.com/adopters?page=two>; rel="next"`,
+			Response{},
+		},
+		{
+			"no angle brackets",
+			`https://api.example.com/adopters?page=2; rel="next"`,
+			Response{},
+		},
+		{
+			"invalid URL",
+			`<%zz?page=2>; rel="next", <https://api.example.com/adopters?page=5>; rel="last"`,
+			Response{LastPage: 5},
+		},
+		{
+			"extra parameters",
+			`<https://api.example.com/adopters?page=2>; type="application/json"; rel="next"`,
+			Response{NextPage: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.link != "" {
+			header.Set("Link", tt.link)
+		}
+		r := &Response{Response: &http.Response{Header: header}}
+		r.populatePageValues()
+		got := Response{
+			NextPage:   r.NextPage,
+			PrevPage:   r.PrevPage,
+			FirstPage:  r.FirstPage,
+			LastPage:   r.LastPage,
+			NextCursor: r.NextCursor,
+		}
+		if got != tt.want {
+			t.Errorf("%v: populatePageValues set %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
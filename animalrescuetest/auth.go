@@ -0,0 +1,122 @@
+package animalrescuetest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequireAuth makes the server answer 401 Unauthorized to every request for
+// which check returns false. A nil check disables authentication.
+func (s *Server) RequireAuth(check func(r *http.Request) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth = check
+}
+
+// BearerToken returns the bearer token sent in the Authorization header of r,
+// or the empty string if there is none.
+func BearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, prefix) {
+		return h[len(prefix):]
+	}
+	return ""
+}
+
+// TokenServer is a stub OAuth2 token endpoint that issues access tokens
+// through the client credentials grant.
+type TokenServer struct {
+	*httptest.Server
+
+	clientID     string
+	clientSecret string
+
+	mu       sync.Mutex
+	lifetime time.Duration
+	tokens   map[string]time.Time // access token to expiry
+	issued   int
+}
+
+// NewTokenServer starts a token endpoint that accepts the given client
+// credentials and issues tokens valid for lifetime. The caller should call
+// Close when finished, to shut it down.
+func NewTokenServer(clientID, clientSecret string, lifetime time.Duration) *TokenServer {
+	ts := &TokenServer{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		lifetime:     lifetime,
+		tokens:       make(map[string]time.Time),
+	}
+	ts.Server = httptest.NewServer(http.HandlerFunc(ts.serveHTTP))
+	return ts
+}
+
+// Valid reports whether token was issued by the server and has not expired.
+// It can be passed to Server.RequireAuth through BearerToken:
+//
+//	srv.RequireAuth(func(r *http.Request) bool {
+//		return ts.Valid(animalrescuetest.BearerToken(r))
+//	})
+func (ts *TokenServer) Valid(token string) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	expiry, ok := ts.tokens[token]
+	return ok && time.Now().Before(expiry)
+}
+
+// Issued returns the number of tokens issued so far.
+func (ts *TokenServer) Issued() int {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.issued
+}
+
+// Revoke invalidates every token issued so far.
+func (ts *TokenServer) Revoke() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.tokens = make(map[string]time.Time)
+}
+
+func (ts *TokenServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeJSON(w, http.StatusMethodNotAllowed, oauthError{Error: "invalid_request"})
+		return
+	}
+	if r.PostFormValue("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, oauthError{Error: "unsupported_grant_type"})
+		return
+	}
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if id != ts.clientID || secret != ts.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, oauthError{Error: "invalid_client"})
+		return
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	token := hex.EncodeToString(b)
+
+	ts.mu.Lock()
+	ts.tokens[token] = time.Now().Add(ts.lifetime)
+	ts.issued++
+	ts.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{token, "bearer", int64(ts.lifetime / time.Second)})
+}
+
+type oauthError struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,92 @@
+package animalrescuetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// errorBody mirrors the JSON body the Animal Rescue API returns on failure,
+// which the client decodes into an animalrescue.ErrorResponse.
+type errorBody struct {
+	Message string               `json:"message"`
+	Errors  []animalrescue.Error `json:"errors,omitempty"`
+}
+
+// apiError is a failure produced by a route handler, written out as an
+// errorBody with the given status.
+type apiError struct {
+	status  int
+	message string
+	errors  []animalrescue.Error
+}
+
+func (e *apiError) write(w http.ResponseWriter) {
+	writeError(w, e.status, e.message, e.errors)
+}
+
+func errInvalidBody(err error) *apiError {
+	return &apiError{status: http.StatusBadRequest, message: "Problems parsing JSON: " + err.Error()}
+}
+
+func errInternal() *apiError {
+	return &apiError{status: http.StatusInternalServerError, message: "Internal Server Error"}
+}
+
+func errMissingField(resource, field string) *apiError {
+	return &apiError{
+		status:  http.StatusUnprocessableEntity,
+		message: "Validation Failed",
+		errors:  []animalrescue.Error{{Resource: resource, Field: field, Code: animalrescue.CodeMissingField}},
+	}
+}
+
+func errMissingResource(resource string) *apiError {
+	return &apiError{
+		status:  http.StatusUnprocessableEntity,
+		message: "Validation Failed",
+		errors:  []animalrescue.Error{{Resource: resource, Field: "id", Code: animalrescue.CodeMissing}},
+	}
+}
+
+func errInvalidParam(name string) *apiError {
+	return &apiError{
+		status:  http.StatusUnprocessableEntity,
+		message: "Validation Failed",
+		errors:  []animalrescue.Error{{Resource: "ListOptions", Field: name, Code: animalrescue.CodeInvalid}},
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string, errs []animalrescue.Error) {
+	writeJSON(w, status, errorBody{Message: message, Errors: errs})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeEntity writes v as the 200 OK response to the GET request r, with an
+// ETag derived from its encoding. If r carries the same tag in If-None-Match,
+// 304 Not Modified is written instead.
+func writeEntity(w http.ResponseWriter, r *http.Request, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Internal Server Error", nil)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(append(body, '\n'))
+}
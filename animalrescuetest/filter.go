@@ -0,0 +1,187 @@
+package animalrescuetest
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// sortKey returns the value that the item at index i of a listing is
+// sorted by: a string, compared ignoring case, an int64 or a time.Time.
+type sortKey func(i int) interface{}
+
+// sortItems sorts the listing items, a slice, according to the sort and
+// direction query parameters of q. keys holds the sortable fields; listings
+// sorted by other fields are rejected.
+func sortItems(q url.Values, items interface{}, keys map[string]sortKey) *apiError {
+	field := q.Get("sort")
+	if field == "" {
+		field = "id"
+	}
+	key, ok := keys[field]
+	if !ok {
+		return errInvalidParam("sort")
+	}
+	var desc bool
+	switch q.Get("direction") {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return errInvalidParam("direction")
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			i, j = j, i
+		}
+		switch a := key(i).(type) {
+		case string:
+			return strings.ToLower(a) < strings.ToLower(key(j).(string))
+		case int64:
+			return a < key(j).(int64)
+		case time.Time:
+			return a.Before(key(j).(time.Time))
+		}
+		return false
+	})
+	return nil
+}
+
+// matchParam reports whether the filter parameter name of q is unset, or
+// equal to got ignoring case.
+func matchParam(q url.Values, name, got string) bool {
+	want := q.Get(name)
+	return want == "" || strings.EqualFold(want, got)
+}
+
+// idParam returns the ID filter parameter name of q, or 0 if it is unset.
+func idParam(q url.Values, name string) (int64, *apiError) {
+	s := q.Get(name)
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errInvalidParam(name)
+	}
+	return id, nil
+}
+
+// timeParam returns the time filter parameter name of q, or the zero time
+// if it is unset.
+func timeParam(q url.Values, name string) (time.Time, *apiError) {
+	var t animalrescue.Timestamp
+	if err := t.UnmarshalText([]byte(q.Get(name))); err != nil {
+		return time.Time{}, errInvalidParam(name)
+	}
+	return t.Time, nil
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func filterAdopters(q url.Values, adopters []*animalrescue.Adopter) ([]*animalrescue.Adopter, *apiError) {
+	var out []*animalrescue.Adopter
+	for _, a := range adopters {
+		if matchParam(q, "state", str(a.State)) && matchParam(q, "city", str(a.City)) &&
+			matchParam(q, "country", str(a.Country)) && matchParam(q, "zip_code", str(a.ZipCode)) &&
+			matchParam(q, "email", str(a.Email)) {
+			out = append(out, a)
+		}
+	}
+	if out == nil {
+		out = []*animalrescue.Adopter{}
+	}
+	return out, sortItems(q, out, map[string]sortKey{
+		"id":         func(i int) interface{} { return *out[i].ID },
+		"first_name": func(i int) interface{} { return str(out[i].FirstName) },
+		"last_name":  func(i int) interface{} { return str(out[i].LastName) },
+		"state":      func(i int) interface{} { return str(out[i].State) },
+		"city":       func(i int) interface{} { return str(out[i].City) },
+	})
+}
+
+func filterAdoptees(q url.Values, adoptees []*animalrescue.Adoptee) ([]*animalrescue.Adoptee, *apiError) {
+	gender := animalrescue.ParseGender(q.Get("gender"))
+	age := animalrescue.ParseAgeGroup(q.Get("age"))
+	var out []*animalrescue.Adoptee
+	for _, a := range adoptees {
+		if matchParam(q, "breed", a.Breed) && matchParam(q, "name", a.Name) &&
+			(gender == "" || a.Gender == gender) && (age == "" || a.Age == age) {
+			out = append(out, a)
+		}
+	}
+	if out == nil {
+		out = []*animalrescue.Adoptee{}
+	}
+	bands := animalrescue.DefaultAgeBands
+	return out, sortItems(q, out, map[string]sortKey{
+		"id":    func(i int) interface{} { return int64(out[i].ID) },
+		"name":  func(i int) interface{} { return out[i].Name },
+		"breed": func(i int) interface{} { return out[i].Breed },
+		"age": func(i int) interface{} {
+			for n, band := range bands {
+				if out[i].Age == band {
+					return int64(n)
+				}
+			}
+			return int64(len(bands))
+		},
+	})
+}
+
+func filterAdoptions(q url.Values, adoptions []*animalrescue.Adoption) ([]*animalrescue.Adoption, *apiError) {
+	adopterID, apiErr := idParam(q, "adopter_id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	adopteeID, apiErr := idParam(q, "adoptee_id")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	after, apiErr := timeParam(q, "created_after")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	before, apiErr := timeParam(q, "created_before")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+
+	var out []*animalrescue.Adoption
+	for _, a := range adoptions {
+		var created time.Time
+		if a.CreatedAt != nil {
+			created = a.CreatedAt.Time
+		}
+		switch {
+		case adopterID != 0 && (a.Adopter == nil || a.Adopter.ID == nil || *a.Adopter.ID != adopterID):
+		case adopteeID != 0 && (a.Adoptee == nil || int64(a.Adoptee.ID) != adopteeID):
+		case !after.IsZero() && created.Before(after):
+		case !before.IsZero() && !created.Before(before):
+		default:
+			out = append(out, a)
+		}
+	}
+	if out == nil {
+		out = []*animalrescue.Adoption{}
+	}
+	return out, sortItems(q, out, map[string]sortKey{
+		"id": func(i int) interface{} { return int64(out[i].ID) },
+		"created_at": func(i int) interface{} {
+			if out[i].CreatedAt == nil {
+				return time.Time{}
+			}
+			return out[i].CreatedAt.Time
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package animalrescuetest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const defaultPerPage = 30
+
+// paginate slices the listing items according to the page and per_page query
+// parameters of r and sets the matching Link header on w. Listings requested
+// without either parameter are returned whole, as the real API does.
+func paginate(w http.ResponseWriter, r *http.Request, items interface{}) (interface{}, *apiError) {
+	q := r.URL.Query()
+	if q.Get("page") == "" && q.Get("per_page") == "" {
+		return items, nil
+	}
+
+	page, err := queryInt(q.Get("page"), 1)
+	if err != nil || page < 1 {
+		return nil, errInvalidParam("page")
+	}
+	perPage, err := queryInt(q.Get("per_page"), defaultPerPage)
+	if err != nil || perPage < 1 {
+		return nil, errInvalidParam("per_page")
+	}
+
+	v := reflect.ValueOf(items)
+	n := v.Len()
+	lastPage := (n + perPage - 1) / perPage
+	if lastPage == 0 {
+		lastPage = 1
+	}
+
+	start, end := (page-1)*perPage, page*perPage
+	if start > n {
+		start = n
+	}
+	if end > n {
+		end = n
+	}
+
+	var links []string
+	link := func(p int, rel string) {
+		u := *r.URL
+		q := u.Query()
+		q.Set("page", strconv.Itoa(p))
+		q.Set("per_page", strconv.Itoa(perPage))
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf(`<http://%s%s>; rel="%s"`, r.Host, u.RequestURI(), rel))
+	}
+	if page < lastPage {
+		link(page+1, "next")
+		link(lastPage, "last")
+	}
+	if page > 1 {
+		link(1, "first")
+		link(page-1, "prev")
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	return v.Slice(start, end).Interface(), nil
+}
+
+func queryInt(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	return strconv.Atoi(s)
+}
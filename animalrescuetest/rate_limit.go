@@ -0,0 +1,61 @@
+package animalrescuetest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimit tracks the request budget of the fake server's current window.
+type rateLimit struct {
+	limit     int
+	window    time.Duration
+	remaining int
+	reset     time.Time
+}
+
+// SetRateLimit makes the server allow at most limit requests per window,
+// reporting its budget through X-RateLimit-* headers and answering 429 Too
+// Many Requests once it is used up. A limit of zero disables rate limiting.
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		s.rate = nil
+		return
+	}
+	s.rate = &rateLimit{limit: limit, window: window}
+}
+
+// checkRateLimit spends one request from the current window and writes the
+// rate limit headers. It reports false, after writing a 429 response, if the
+// window's budget was already used up.
+func (s *Server) checkRateLimit(w http.ResponseWriter) bool {
+	rl := s.rate
+	if rl == nil {
+		return true
+	}
+
+	now := time.Now()
+	if !now.Before(rl.reset) {
+		rl.remaining = rl.limit
+		// Reset is reported with second precision, so keep it on a whole
+		// second for clients waiting on the header value.
+		rl.reset = now.Add(rl.window).Truncate(time.Second)
+	}
+
+	ok := rl.remaining > 0
+	if ok {
+		rl.remaining--
+	}
+
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(rl.limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(rl.remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(rl.reset.Unix(), 10))
+	if !ok {
+		writeError(w, http.StatusTooManyRequests, "API rate limit exceeded", nil)
+	}
+	return ok
+}
@@ -0,0 +1,500 @@
+// Package animalrescuetest provides an in-memory fake of the Animal Rescue
+// API for use in tests.
+//
+// The fake server implements every route called by the animalrescue
+// services, stores entities in memory, assigns IDs, honors Idempotency-Key
+// headers on creations and reports failures using the same error body
+// shape as the real API:
+//
+//	srv := animalrescuetest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//	adoptee, _, err := client.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+package animalrescuetest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Server is a stateful, in-memory fake of the Animal Rescue API.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	nextID    int64
+	adopters  map[int64]*animalrescue.Adopter
+	adoptees  map[int64]*animalrescue.Adoptee
+	adoptions map[int64]*animalrescue.Adoption
+	petprefs  map[int64]*animalrescue.PetPreference
+
+	// created holds the entities created with an Idempotency-Key header,
+	// by path and key, so that repeated creations return them again.
+	created map[string]interface{}
+
+	rate *rateLimit
+	auth func(r *http.Request) bool
+}
+
+// NewServer starts and returns a new fake server with an empty store.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{}
+	s.Reset()
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns an animalrescue.Client whose BaseURL points at the server.
+func (s *Server) Client() *animalrescue.Client {
+	c := animalrescue.NewClient(s.Server.Client())
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	return c
+}
+
+// Reset discards every stored entity and restarts ID assignment.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID = 0
+	s.adopters = make(map[int64]*animalrescue.Adopter)
+	s.adoptees = make(map[int64]*animalrescue.Adoptee)
+	s.adoptions = make(map[int64]*animalrescue.Adoption)
+	s.petprefs = make(map[int64]*animalrescue.PetPreference)
+	s.created = make(map[string]interface{})
+}
+
+func (s *Server) newID() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.auth != nil && !s.auth(r) {
+		writeError(w, http.StatusUnauthorized, "Requires authentication", nil)
+		return
+	}
+	if !s.checkRateLimit(w) {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch len(parts) {
+	case 1:
+		switch parts[0] {
+		case "adopters":
+			s.handleCollection(w, r, s.listAdopters, s.createAdopter)
+			return
+		case "adoptees":
+			s.handleCollection(w, r, s.listAdoptees, s.createAdoptee)
+			return
+		case "adoptions":
+			s.handleCollection(w, r, s.listAdoptions, s.createAdoption)
+			return
+		case "petprefs":
+			s.handleCollection(w, r, s.listPetPreferences, s.createPetPreference)
+			return
+		}
+	case 2:
+		id, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "Not Found", nil)
+			return
+		}
+		switch parts[0] {
+		case "adopter":
+			s.handleItem(w, r, id, "Adopter", s.adopterByID, s.editAdopter, s.deleteAdopter)
+			return
+		case "adoptee":
+			s.handleItem(w, r, id, "Adoptee", s.adopteeByID, s.editAdoptee, s.deleteAdoptee)
+			return
+		case "adoption":
+			s.handleItem(w, r, id, "Adoption", s.adoptionByID, nil, s.deleteAdoption)
+			return
+		case "petpref":
+			s.handleItem(w, r, id, "PetPreference", s.petPreferenceByID, s.editPetPreference, s.deletePetPreference)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "Not Found", nil)
+}
+
+func (s *Server) handleCollection(w http.ResponseWriter, r *http.Request, list func(url.Values) (interface{}, *apiError), create func([]byte) (interface{}, *apiError)) {
+	switch r.Method {
+	case "GET":
+		items, apiErr := list(r.URL.Query())
+		if apiErr != nil {
+			apiErr.write(w)
+			return
+		}
+		v, apiErr := paginate(w, r, items)
+		if apiErr != nil {
+			apiErr.write(w)
+			return
+		}
+		writeEntity(w, r, v)
+	case "POST":
+		key := r.Header.Get("Idempotency-Key")
+		if key != "" {
+			key = r.URL.Path + " " + key
+			if v, ok := s.created[key]; ok {
+				writeJSON(w, http.StatusCreated, v)
+				return
+			}
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Problems reading request body", nil)
+			return
+		}
+		v, apiErr := create(body)
+		if apiErr != nil {
+			apiErr.write(w)
+			return
+		}
+		if key != "" {
+			s.created[key] = v
+		}
+		writeJSON(w, http.StatusCreated, v)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed", nil)
+	}
+}
+
+func (s *Server) handleItem(w http.ResponseWriter, r *http.Request, id int64, resource string,
+	get func(int64) interface{}, edit func(int64, []byte) (interface{}, *apiError), del func(int64)) {
+	v := get(id)
+	if v == nil {
+		writeError(w, http.StatusNotFound, "Not Found", []animalrescue.Error{
+			{Resource: resource, Field: "id", Code: animalrescue.CodeMissing},
+		})
+		return
+	}
+
+	switch {
+	case r.Method == "GET":
+		writeEntity(w, r, v)
+	case r.Method == "PATCH" && edit != nil:
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Problems reading request body", nil)
+			return
+		}
+		v, apiErr := edit(id, body)
+		if apiErr != nil {
+			apiErr.write(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	case r.Method == "DELETE":
+		del(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed", nil)
+	}
+}
+
+// Adopters
+
+func (s *Server) listAdopters(q url.Values) (interface{}, *apiError) {
+	adopters := make([]*animalrescue.Adopter, 0, len(s.adopters))
+	for _, id := range sortedIDs(s.adopters) {
+		adopters = append(adopters, s.adopters[id])
+	}
+	return filterAdopters(q, adopters)
+}
+
+func (s *Server) adopterByID(id int64) interface{} {
+	if a, ok := s.adopters[id]; ok {
+		return a
+	}
+	return nil
+}
+
+func (s *Server) createAdopter(body []byte) (interface{}, *apiError) {
+	a := new(animalrescue.Adopter)
+	if err := json.Unmarshal(body, a); err != nil {
+		return nil, errInvalidBody(err)
+	}
+	if a.FirstName == nil || *a.FirstName == "" {
+		return nil, errMissingField("Adopter", "first_name")
+	}
+	if apiErr := s.checkAdopterEmail(0, a.Email); apiErr != nil {
+		return nil, apiErr
+	}
+
+	a.ID = animalrescue.Int64(s.newID())
+	s.storePetPreferences(a.PetPreferences)
+	s.adopters[*a.ID] = a
+	return a, nil
+}
+
+func (s *Server) editAdopter(id int64, body []byte) (interface{}, *apiError) {
+	var a animalrescue.Adopter
+	if apiErr := clone(&a, s.adopters[id]); apiErr != nil {
+		return nil, apiErr
+	}
+	if err := json.Unmarshal(body, &a); err != nil {
+		return nil, errInvalidBody(err)
+	}
+	if apiErr := s.checkAdopterEmail(id, a.Email); apiErr != nil {
+		return nil, apiErr
+	}
+
+	a.ID = animalrescue.Int64(id)
+	s.storePetPreferences(a.PetPreferences)
+	s.adopters[id] = &a
+	return &a, nil
+}
+
+func (s *Server) deleteAdopter(id int64) {
+	delete(s.adopters, id)
+}
+
+// checkAdopterEmail reports an already_exists error if an adopter other than
+// the one referenced by id is registered with the given email.
+func (s *Server) checkAdopterEmail(id int64, email *string) *apiError {
+	if email == nil || *email == "" {
+		return nil
+	}
+	for otherID, other := range s.adopters {
+		if otherID != id && other.Email != nil && strings.EqualFold(*other.Email, *email) {
+			return &apiError{
+				status:  http.StatusUnprocessableEntity,
+				message: "Validation Failed",
+				errors:  []animalrescue.Error{{Resource: "Adopter", Field: "email", Code: animalrescue.CodeAlreadyExists}},
+			}
+		}
+	}
+	return nil
+}
+
+// Adoptees
+
+func (s *Server) listAdoptees(q url.Values) (interface{}, *apiError) {
+	adoptees := make([]*animalrescue.Adoptee, 0, len(s.adoptees))
+	for _, id := range sortedIDs(s.adoptees) {
+		adoptees = append(adoptees, s.adoptees[id])
+	}
+	return filterAdoptees(q, adoptees)
+}
+
+func (s *Server) adopteeByID(id int64) interface{} {
+	if a, ok := s.adoptees[id]; ok {
+		return a
+	}
+	return nil
+}
+
+func (s *Server) createAdoptee(body []byte) (interface{}, *apiError) {
+	a := new(animalrescue.Adoptee)
+	if err := json.Unmarshal(body, a); err != nil {
+		return nil, errInvalidBody(err)
+	}
+	if a.Name == "" {
+		return nil, errMissingField("Adoptee", "name")
+	}
+
+	a.ID = int(s.newID())
+	s.adoptees[int64(a.ID)] = a
+	return a, nil
+}
+
+func (s *Server) editAdoptee(id int64, body []byte) (interface{}, *apiError) {
+	var a animalrescue.Adoptee
+	if apiErr := clone(&a, s.adoptees[id]); apiErr != nil {
+		return nil, apiErr
+	}
+	if err := json.Unmarshal(body, &a); err != nil {
+		return nil, errInvalidBody(err)
+	}
+
+	a.ID = int(id)
+	s.adoptees[id] = &a
+	return &a, nil
+}
+
+func (s *Server) deleteAdoptee(id int64) {
+	delete(s.adoptees, id)
+}
+
+// Adoptions
+
+func (s *Server) listAdoptions(q url.Values) (interface{}, *apiError) {
+	adoptions := make([]*animalrescue.Adoption, 0, len(s.adoptions))
+	for _, id := range sortedIDs(s.adoptions) {
+		adoptions = append(adoptions, s.adoptions[id])
+	}
+	return filterAdoptions(q, adoptions)
+}
+
+func (s *Server) adoptionByID(id int64) interface{} {
+	if a, ok := s.adoptions[id]; ok {
+		return a
+	}
+	return nil
+}
+
+func (s *Server) createAdoption(body []byte) (interface{}, *apiError) {
+	na := new(animalrescue.NewAdoption)
+	if err := json.Unmarshal(body, na); err != nil {
+		return nil, errInvalidBody(err)
+	}
+	if na.Adopter == nil || na.Adopter.ID == nil {
+		return nil, errMissingField("Adoption", "adopter")
+	}
+	if na.Adoptee == nil || na.Adoptee.ID == 0 {
+		return nil, errMissingField("Adoption", "adoptee")
+	}
+
+	adopter, ok := s.adopters[*na.Adopter.ID]
+	if !ok {
+		return nil, errMissingResource("Adopter")
+	}
+	adoptee, ok := s.adoptees[int64(na.Adoptee.ID)]
+	if !ok {
+		return nil, errMissingResource("Adoptee")
+	}
+	for _, other := range s.adoptions {
+		if other.Adoptee != nil && other.Adoptee.ID == adoptee.ID {
+			return nil, &apiError{
+				status:  http.StatusUnprocessableEntity,
+				message: "Validation Failed",
+				errors:  []animalrescue.Error{{Resource: "Adoption", Field: "adoptee", Code: animalrescue.CodeAlreadyExists}},
+			}
+		}
+	}
+
+	createdAt := &animalrescue.Timestamp{Time: time.Now().UTC().Truncate(time.Second)}
+	if na.CreatedAt != nil && !na.CreatedAt.IsZero() {
+		createdAt = na.CreatedAt
+	}
+
+	a := &animalrescue.Adoption{
+		ID:        int(s.newID()),
+		Adopter:   adopter,
+		Adoptee:   adoptee,
+		CreatedAt: createdAt,
+	}
+	s.adoptions[int64(a.ID)] = a
+	return a, nil
+}
+
+func (s *Server) deleteAdoption(id int64) {
+	delete(s.adoptions, id)
+}
+
+// Pet preferences
+
+func (s *Server) listPetPreferences(q url.Values) (interface{}, *apiError) {
+	pp := make([]*animalrescue.PetPreference, 0, len(s.petprefs))
+	for _, id := range sortedIDs(s.petprefs) {
+		pp = append(pp, s.petprefs[id])
+	}
+	return pp, nil
+}
+
+func (s *Server) petPreferenceByID(id int64) interface{} {
+	if pp, ok := s.petprefs[id]; ok {
+		return pp
+	}
+	return nil
+}
+
+func (s *Server) createPetPreference(body []byte) (interface{}, *apiError) {
+	pp := new(animalrescue.PetPreference)
+	if err := json.Unmarshal(body, pp); err != nil {
+		return nil, errInvalidBody(err)
+	}
+
+	pp.ID = int(s.newID())
+	s.petprefs[int64(pp.ID)] = pp
+	return pp, nil
+}
+
+func (s *Server) editPetPreference(id int64, body []byte) (interface{}, *apiError) {
+	var pp animalrescue.PetPreference
+	if apiErr := clone(&pp, s.petprefs[id]); apiErr != nil {
+		return nil, apiErr
+	}
+	if err := json.Unmarshal(body, &pp); err != nil {
+		return nil, errInvalidBody(err)
+	}
+
+	pp.ID = int(id)
+	s.petprefs[id] = &pp
+	return &pp, nil
+}
+
+func (s *Server) deletePetPreference(id int64) {
+	delete(s.petprefs, id)
+}
+
+// storePetPreferences assigns IDs to any new preferences embedded in an
+// adopter and registers them so they are reachable through petprefs routes.
+func (s *Server) storePetPreferences(pp []*animalrescue.PetPreference) {
+	for _, p := range pp {
+		if p == nil {
+			continue
+		}
+		if _, ok := s.petprefs[int64(p.ID)]; !ok || p.ID == 0 {
+			p.ID = int(s.newID())
+		}
+		s.petprefs[int64(p.ID)] = p
+	}
+}
+
+// clone copies the stored entity src into dst through its JSON encoding, so
+// that decoding a patch into dst leaves src, and the entities it points to,
+// untouched.
+func clone(dst, src interface{}) *apiError {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return errInternal()
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return errInternal()
+	}
+	return nil
+}
+
+// sortedIDs returns the keys of m in ascending order, so listings are stable
+// across requests. m must be one of the Server's entity maps.
+func sortedIDs(m interface{}) []int64 {
+	var ids []int64
+	switch m := m.(type) {
+	case map[int64]*animalrescue.Adopter:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[int64]*animalrescue.Adoptee:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[int64]*animalrescue.Adoption:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	case map[int64]*animalrescue.PetPreference:
+		for id := range m {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
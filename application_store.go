@@ -0,0 +1,265 @@
+package animalrescue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ErrStaleApplication is returned by ApplicationStore.Update when the
+// application was changed since it was read.
+var ErrStaleApplication = errors.New("animalrescue: application changed since it was read")
+
+// An ApplicationStore stores the applications managed by an
+// ApplicationsService. Implementations hand out copies of the applications
+// they hold, so that changing an application never changes the store
+// without a call to Update. Its methods may be called concurrently.
+type ApplicationStore interface {
+	// List returns every stored application, in ascending ID order.
+	List(ctx context.Context) ([]*Application, error)
+
+	// Get returns the application with the given ID, or an error matching
+	// ErrNotFound.
+	Get(ctx context.Context, id int64) (*Application, error)
+
+	// Create stores a new application, setting its ID and its Version
+	// to 1.
+	Create(ctx context.Context, a *Application) error
+
+	// Update replaces the stored application with the ID of a, provided
+	// its Version is still that of a, and increments the Version of a.
+	// Otherwise it returns ErrStaleApplication and stores nothing.
+	Update(ctx context.Context, a *Application) error
+
+	// Delete removes the application with the given ID, if any.
+	Delete(ctx context.Context, id int64) error
+}
+
+// applicationTable holds applications, encoded as JSON so that stored
+// copies never share memory with those handed out. It implements
+// ApplicationStore, calling persist, if set, after every change.
+type applicationTable struct {
+	mu      sync.Mutex
+	nextID  int64
+	apps    map[int64][]byte
+	persist func() error
+}
+
+func (t *applicationTable) List(ctx context.Context) ([]*Application, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ids := t.ids()
+	apps := make([]*Application, len(ids))
+	for i, id := range ids {
+		apps[i] = new(Application)
+		if err := json.Unmarshal(t.apps[id], apps[i]); err != nil {
+			return nil, err
+		}
+	}
+	return apps, nil
+}
+
+func (t *applicationTable) Get(ctx context.Context, id int64) (*Application, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, ok := t.apps[id]
+	if !ok {
+		return nil, fmt.Errorf("animalrescue: application %d: %w", id, ErrNotFound)
+	}
+	a := new(Application)
+	if err := json.Unmarshal(data, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (t *applicationTable) Create(ctx context.Context, a *Application) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// The ID is only taken once the application is stored.
+	t.nextID++
+	stored := *a
+	stored.ID, stored.Version = t.nextID, 1
+	if err := t.set(&stored); err != nil {
+		t.nextID--
+		return err
+	}
+	a.ID, a.Version = stored.ID, stored.Version
+	return nil
+}
+
+func (t *applicationTable) Update(ctx context.Context, a *Application) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, ok := t.apps[a.ID]
+	if !ok {
+		return fmt.Errorf("animalrescue: application %d: %w", a.ID, ErrNotFound)
+	}
+	var current struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.Unmarshal(data, &current); err != nil {
+		return err
+	}
+	if current.Version != a.Version {
+		return ErrStaleApplication
+	}
+
+	stored := *a
+	stored.Version++
+	if err := t.set(&stored); err != nil {
+		return err
+	}
+	a.Version = stored.Version
+	return nil
+}
+
+func (t *applicationTable) Delete(ctx context.Context, id int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, ok := t.apps[id]
+	if !ok {
+		return nil
+	}
+	delete(t.apps, id)
+	if t.persist != nil {
+		if err := t.persist(); err != nil {
+			t.apps[id] = data
+			return err
+		}
+	}
+	return nil
+}
+
+// ids returns the IDs of the stored applications in ascending order. t.mu
+// must be held.
+func (t *applicationTable) ids() []int64 {
+	ids := make([]int64, 0, len(t.apps))
+	for id := range t.apps {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// set stores a, undoing the change if it cannot be persisted. t.mu must be
+// held.
+func (t *applicationTable) set(a *Application) error {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	old, existed := t.apps[a.ID]
+	t.apps[a.ID] = data
+	if t.persist != nil {
+		if err := t.persist(); err != nil {
+			if existed {
+				t.apps[a.ID] = old
+			} else {
+				delete(t.apps, a.ID)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// MemoryApplicationStore is an ApplicationStore that keeps applications in
+// memory, for the lifetime of the process. NewClient gives every client one.
+type MemoryApplicationStore struct {
+	applicationTable
+}
+
+// NewMemoryApplicationStore returns an empty MemoryApplicationStore.
+func NewMemoryApplicationStore() *MemoryApplicationStore {
+	return &MemoryApplicationStore{applicationTable{apps: make(map[int64][]byte)}}
+}
+
+// FileApplicationStore is an ApplicationStore that keeps applications in a
+// JSON file, rewritten after every change, so that they survive restarts.
+// A file must only be used by one store at a time.
+type FileApplicationStore struct {
+	applicationTable
+	path string
+}
+
+// applicationFile is the content of the file of a FileApplicationStore.
+type applicationFile struct {
+	NextID       int64             `json:"next_id"`
+	Applications []json.RawMessage `json:"applications"`
+}
+
+// NewFileApplicationStore returns a FileApplicationStore keeping
+// applications in the file at path, loading those it already holds. The
+// file is created on the first change if it does not exist.
+func NewFileApplicationStore(path string) (*FileApplicationStore, error) {
+	s := &FileApplicationStore{
+		applicationTable: applicationTable{apps: make(map[int64][]byte)},
+		path:             path,
+	}
+	s.persist = s.save
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f applicationFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("animalrescue: reading %v: %v", path, err)
+	}
+	s.nextID = f.NextID
+	for _, raw := range f.Applications {
+		var a Application
+		if err := json.Unmarshal(raw, &a); err != nil {
+			return nil, fmt.Errorf("animalrescue: reading %v: %v", path, err)
+		}
+		s.apps[a.ID] = raw
+		if a.ID > s.nextID {
+			s.nextID = a.ID
+		}
+	}
+	return s, nil
+}
+
+// save writes the applications to the file. s.mu must be held.
+func (s *FileApplicationStore) save() error {
+	f := applicationFile{NextID: s.nextID, Applications: []json.RawMessage{}}
+	for _, id := range s.ids() {
+		f.Applications = append(f.Applications, s.apps[id])
+	}
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// Rename is atomic, so the file is never left half written.
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
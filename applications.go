@@ -0,0 +1,393 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ApplicationsService manages adoption applications. The Animal Rescue API
+// only records finished adoptions, so applications are kept client-side, in
+// the client's ApplicationStore; the workflow they go through is enforced by
+// Application.Transition, and approving an application creates its adoption
+// through the API.
+type ApplicationsService service
+
+// ApplicationStatus is the stage of an adoption application.
+type ApplicationStatus string
+
+// Application statuses. An application is submitted, reviewed, checked at
+// the adopter's home, then approved or rejected; it may also be rejected
+// during review. An application being approved is approving until its
+// adoption has been created, and may still be rejected if the adoption
+// cannot be created, for example because the adoptee was adopted by
+// someone else.
+const (
+	ApplicationSubmitted ApplicationStatus = "submitted"
+	ApplicationInReview  ApplicationStatus = "in_review"
+	ApplicationHomeCheck ApplicationStatus = "home_check"
+	ApplicationApproving ApplicationStatus = "approving"
+	ApplicationApproved  ApplicationStatus = "approved"
+	ApplicationRejected  ApplicationStatus = "rejected"
+)
+
+// applicationTransitions lists the statuses each status may move to. The
+// empty status is that of an application not yet submitted.
+var applicationTransitions = map[ApplicationStatus][]ApplicationStatus{
+	"":                   {ApplicationSubmitted},
+	ApplicationSubmitted: {ApplicationInReview},
+	ApplicationInReview:  {ApplicationHomeCheck, ApplicationRejected},
+	ApplicationHomeCheck: {ApplicationApproving, ApplicationRejected},
+	ApplicationApproving: {ApplicationApproved, ApplicationRejected},
+}
+
+// CanTransitionTo reports whether an application may move from s to next.
+func (s ApplicationStatus) CanTransitionTo(next ApplicationStatus) bool {
+	for _, to := range applicationTransitions[s] {
+		if to == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Final reports whether s is a status applications never move out of.
+func (s ApplicationStatus) Final() bool {
+	return s == ApplicationApproved || s == ApplicationRejected
+}
+
+// ErrIllegalTransition is matched by every *TransitionError with errors.Is.
+var ErrIllegalTransition = errors.New("animalrescue: illegal application transition")
+
+// A TransitionError reports an attempt to move an application to a status
+// it cannot reach from its current one.
+type TransitionError struct {
+	ApplicationID int64
+	From          ApplicationStatus
+	To            ApplicationStatus
+}
+
+func (e *TransitionError) Error() string {
+	from := string(e.From)
+	if from == "" {
+		from = "unsubmitted"
+	}
+	return fmt.Sprintf("animalrescue: application %d cannot move from %v to %v", e.ApplicationID, from, e.To)
+}
+
+// Is reports whether target is ErrIllegalTransition.
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
+// ApplicationTransition records the move of an application from one status
+// to another.
+type ApplicationTransition struct {
+	From  ApplicationStatus `json:"from,omitempty"`
+	To    ApplicationStatus `json:"to"`
+	Actor string            `json:"actor"`
+	At    *Timestamp        `json:"at"`
+	Note  string            `json:"note,omitempty"`
+}
+
+func (t ApplicationTransition) String() string {
+	return Stringify(t)
+}
+
+// Application represents an application of an adopter to adopt an adoptee.
+// It is a state machine: its Status only changes through Transition, which
+// records each move in its History.
+type Application struct {
+	ID        int64                    `json:"id,omitempty"`
+	Adopter   *Adopter                 `json:"adopter,omitempty"`
+	Adoptee   *Adoptee                 `json:"adoptee,omitempty"`
+	Status    ApplicationStatus        `json:"status,omitempty"`
+	History   []*ApplicationTransition `json:"history,omitempty"`
+	CreatedAt *Timestamp               `json:"created_at,omitempty"`
+
+	// Version is incremented by the ApplicationStore every time the
+	// application is stored, so that concurrent changes are detected.
+	Version int64 `json:"version,omitempty"`
+
+	// AdoptionID is the ID of the adoption created when the application
+	// was approved.
+	AdoptionID int64 `json:"adoption_id,omitempty"`
+
+	// ApprovalKey is a random key set when the application moves to
+	// ApplicationApproving. The creation of its adoption is sent with an
+	// idempotency key derived from it, which is unique across stores and
+	// the same when a failed approval is resumed.
+	ApprovalKey string `json:"approval_key,omitempty"`
+}
+
+func (a Application) String() string {
+	return Stringify(a)
+}
+
+// Transition moves the application to the status to on behalf of actor,
+// recording the move at the given time along with an optional note. It
+// returns a *TransitionError if the move is illegal, and a
+// *ValidationError if actor is empty. Transition only changes a; see
+// ApplicationsService.TransitionApplicationByID to store the change.
+func (a *Application) Transition(to ApplicationStatus, actor, note string, at time.Time) error {
+	if actor == "" {
+		errs := fieldErrors{resource: "ApplicationTransition"}
+		errs.add("actor", CodeMissingField, "actor is empty")
+		return errs.err()
+	}
+	if !a.Status.CanTransitionTo(to) {
+		return &TransitionError{ApplicationID: a.ID, From: a.Status, To: to}
+	}
+	a.History = append(a.History, &ApplicationTransition{
+		From:  a.Status,
+		To:    to,
+		Actor: actor,
+		At:    &Timestamp{at},
+		Note:  note,
+	})
+	a.Status = to
+	return nil
+}
+
+// ApplicationListOptions specifies the optional parameters to the
+// ApplicationsService.ListAll method.
+type ApplicationListOptions struct {
+	// Status filters applications by status.
+	Status ApplicationStatus
+
+	// AdopterID filters applications by the ID of their adopter.
+	AdopterID int64
+
+	// AdopteeID filters applications by the ID of their adoptee.
+	AdopteeID int64
+}
+
+// matches reports whether a passes the filters of opts.
+func (opts *ApplicationListOptions) matches(a *Application) bool {
+	if opts == nil {
+		return true
+	}
+	switch {
+	case opts.Status != "" && a.Status != opts.Status:
+		return false
+	case opts.AdopterID != 0 && (a.Adopter == nil || a.Adopter.ID == nil || *a.Adopter.ID != opts.AdopterID):
+		return false
+	case opts.AdopteeID != 0 && (a.Adoptee == nil || int64(a.Adoptee.ID) != opts.AdopteeID):
+		return false
+	}
+	return true
+}
+
+// ListAll lists all of the stored applications, in ascending ID order,
+// filtered according to opts.
+func (s *ApplicationsService) ListAll(ctx context.Context, opts *ApplicationListOptions) ([]*Application, error) {
+	ctx, span := s.client.startSpan(ctx, "Applications.ListAll", "application", 0)
+	defer span.End()
+
+	all, err := s.client.ApplicationStore.List(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	applications := all[:0]
+	for _, a := range all {
+		if opts.matches(a) {
+			applications = append(applications, a)
+		}
+	}
+	return applications, nil
+}
+
+// GetApplicationByID fetches an application by ID. A missing application is
+// reported by an error matching ErrNotFound.
+func (s *ApplicationsService) GetApplicationByID(ctx context.Context, applicationID int64) (*Application, error) {
+	ctx, span := s.client.startSpan(ctx, "Applications.GetApplicationByID", "application", applicationID)
+	defer span.End()
+
+	a, err := s.client.ApplicationStore.Get(ctx, applicationID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// NewApplication represents an application to be submitted.
+type NewApplication struct {
+	// Adopter and Adoptee must have been created with the API, since
+	// approving the application creates an adoption referencing their IDs.
+	Adopter *Adopter
+	Adoptee *Adoptee
+
+	// SubmittedBy is recorded as the actor of the submission.
+	SubmittedBy string
+}
+
+// checkParties returns a *ValidationError unless adopter and adoptee have
+// the IDs needed to create their adoption.
+func checkParties(adopter *Adopter, adoptee *Adoptee) error {
+	errs := fieldErrors{resource: "Application"}
+	if adopter == nil || adopter.ID == nil {
+		errs.add("adopter", CodeMissingField, "adopter has no ID")
+	}
+	if adoptee == nil || adoptee.ID == 0 {
+		errs.add("adoptee", CodeMissingField, "adoptee has no ID")
+	}
+	return errs.err()
+}
+
+// CreateApplication submits and stores a new application.
+func (s *ApplicationsService) CreateApplication(ctx context.Context, application NewApplication) (*Application, error) {
+	ctx, span := s.client.startSpan(ctx, "Applications.CreateApplication", "application", 0)
+	defer span.End()
+
+	if err := checkParties(application.Adopter, application.Adoptee); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	now := time.Now()
+	a := &Application{
+		Adopter:   application.Adopter,
+		Adoptee:   application.Adoptee,
+		CreatedAt: &Timestamp{now},
+	}
+	if err := a.Transition(ApplicationSubmitted, application.SubmittedBy, "", now); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if err := s.client.ApplicationStore.Create(ctx, a); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// TransitionApplicationByID moves the application referenced by ID to the
+// status to on behalf of actor, with an optional note, and stores the
+// change. Illegal moves are reported by a *TransitionError, without making
+// any change. If the application was changed by someone else between the
+// time it was read and the time the change was stored, nothing is stored and
+// ErrStaleApplication is returned: the caller may try again, and the move is
+// then checked against the application as it now stands.
+//
+// Approving an application first moves it to ApplicationApproving and
+// stores it, so that no one else can decide on it; that move is recorded in
+// its History like any other, with no note. It then creates the adoption
+// of its adoptee by its adopter with AdoptionsService.CreateAdoption, sent
+// with reqOpts, and finally moves it to ApplicationApproved recording the ID
+// of the adoption. The returned *Response is that of the creation, and nil
+// for other moves. If the creation fails, the application is left approving:
+// approving it again looks for an adoption created by the failed attempt,
+// with reqOpts less any idempotency key, before creating one with the same
+// idempotency key as the failed attempt, and rejecting it
+// gives up on the adoption. An adoption created by a failed attempt is not
+// deleted by the rejection.
+func (s *ApplicationsService) TransitionApplicationByID(ctx context.Context, applicationID int64, to ApplicationStatus, actor, note string, reqOpts ...RequestOption) (*Application, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "Applications.TransitionApplicationByID", "application", applicationID)
+	defer span.End()
+
+	a, err := s.client.ApplicationStore.Get(ctx, applicationID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	// Applications are checked when created, but the store may hand out
+	// others.
+	if to == ApplicationApproved {
+		if err := checkParties(a.Adopter, a.Adoptee); err != nil {
+			span.RecordError(err)
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	resumed := a.Status == ApplicationApproving
+	if to == ApplicationApproved && a.Status == ApplicationHomeCheck {
+		if err := a.Transition(ApplicationApproving, actor, "", now); err != nil {
+			span.RecordError(err)
+			return nil, nil, err
+		}
+		if a.ApprovalKey, err = newIdempotencyKey(); err != nil {
+			span.RecordError(err)
+			return nil, nil, err
+		}
+		if err := s.client.ApplicationStore.Update(ctx, a); err != nil {
+			span.RecordError(err)
+			return nil, nil, err
+		}
+	}
+	if err := a.Transition(to, actor, note, now); err != nil {
+		span.RecordError(err)
+		return nil, nil, err
+	}
+
+	var resp *Response
+	if to == ApplicationApproved {
+		var adoption *Adoption
+		adoption, resp, err = s.adoption(ctx, a, resumed, reqOpts...)
+		if err != nil {
+			span.RecordError(err)
+			return nil, resp, err
+		}
+		a.AdoptionID = int64(adoption.ID)
+	}
+
+	if err := s.client.ApplicationStore.Update(ctx, a); err != nil {
+		span.RecordError(err)
+		return nil, resp, err
+	}
+	return a, resp, nil
+}
+
+// adoption creates the adoption of an approved application. If resumed, an
+// earlier attempt may have created it before failing, and the adoption of
+// the adoptee by the adopter is returned if there is one.
+func (s *ApplicationsService) adoption(ctx context.Context, a *Application, resumed bool, reqOpts ...RequestOption) (*Adoption, *Response, error) {
+	// A new key would let a resumed approval create a second adoption.
+	if a.ApprovalKey == "" {
+		return nil, nil, fmt.Errorf("animalrescue: application %d is approving without an approval key", a.ID)
+	}
+	adopterID, adopteeID := *a.Adopter.ID, int64(a.Adoptee.ID)
+	if resumed {
+		existing, resp, err := s.client.Adoptions.ListAll(ctx, &AdoptionListOptions{
+			AdopterID: adopterID,
+			AdopteeID: adopteeID,
+		}, readOptions(reqOpts)...)
+		if err != nil {
+			return nil, resp, err
+		}
+		// The filters are checked again, in case the API ignores them.
+		for _, adoption := range existing {
+			if adoption.Adopter != nil && adoption.Adopter.ID != nil && *adoption.Adopter.ID == adopterID &&
+				adoption.Adoptee != nil && int64(adoption.Adoptee.ID) == adopteeID {
+				return adoption, resp, nil
+			}
+		}
+	}
+
+	// The idempotency key guards against a creation retried by the
+	// client's RetryPolicy, or by a resumed approval, on APIs that honor
+	// it.
+	opts := append(reqOpts[:len(reqOpts):len(reqOpts)],
+		WithIdempotencyKey(fmt.Sprintf("application-%d-approval-%s", a.ID, a.ApprovalKey)))
+	return s.client.Adoptions.CreateAdoption(ctx, NewAdoption{
+		Adopter: a.Adopter,
+		Adoptee: a.Adoptee,
+	}, opts...)
+}
+
+// DeleteApplicationByID deletes an application referenced by ID
+func (s *ApplicationsService) DeleteApplicationByID(ctx context.Context, applicationID int64) error {
+	ctx, span := s.client.startSpan(ctx, "Applications.DeleteApplicationByID", "application", applicationID)
+	defer span.End()
+
+	if err := s.client.ApplicationStore.Delete(ctx, applicationID); err != nil {
+		span.RecordError(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,624 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestApplication_Transition(t *testing.T) {
+	const (
+		submitted = animalrescue.ApplicationSubmitted
+		inReview  = animalrescue.ApplicationInReview
+		homeCheck = animalrescue.ApplicationHomeCheck
+		approving = animalrescue.ApplicationApproving
+		approved  = animalrescue.ApplicationApproved
+		rejected  = animalrescue.ApplicationRejected
+	)
+	tests := []struct {
+		from, to animalrescue.ApplicationStatus
+		legal    bool
+	}{
+		{"", submitted, true},
+		{"", inReview, false},
+		{submitted, inReview, true},
+		{submitted, approved, false},
+		{submitted, rejected, false},
+		{inReview, homeCheck, true},
+		{inReview, rejected, true},
+		{inReview, approved, false},
+		{homeCheck, approving, true},
+		{homeCheck, approved, false},
+		{homeCheck, rejected, true},
+		{homeCheck, inReview, false},
+		{approving, approved, true},
+		{approving, rejected, true},
+		{approving, inReview, false},
+		{approved, rejected, false},
+		{rejected, inReview, false},
+	}
+
+	at := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		a := &animalrescue.Application{ID: 7, Status: tt.from}
+		err := a.Transition(tt.to, "bob", "note", at)
+
+		if !tt.legal {
+			var terr *animalrescue.TransitionError
+			if !errors.As(err, &terr) || !errors.Is(err, animalrescue.ErrIllegalTransition) {
+				t.Errorf("Transition(%q -> %q) returned %v, want *TransitionError", tt.from, tt.to, err)
+				continue
+			}
+			if terr.ApplicationID != 7 || terr.From != tt.from || terr.To != tt.to {
+				t.Errorf("Transition(%q -> %q) returned %+v", tt.from, tt.to, terr)
+			}
+			if a.Status != tt.from || len(a.History) != 0 {
+				t.Errorf("Transition(%q -> %q) changed the application to %v", tt.from, tt.to, a)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Transition(%q -> %q) returned error: %v", tt.from, tt.to, err)
+			continue
+		}
+		want := animalrescue.ApplicationTransition{From: tt.from, To: tt.to, Actor: "bob", At: &animalrescue.Timestamp{Time: at}, Note: "note"}
+		if a.Status != tt.to || len(a.History) != 1 || a.History[0].String() != want.String() {
+			t.Errorf("Transition(%q -> %q) left %v, want status %q and history [%v]", tt.from, tt.to, a, tt.to, want)
+		}
+	}
+}
+
+func TestApplication_Transition_noActor(t *testing.T) {
+	a := &animalrescue.Application{}
+	err := a.Transition(animalrescue.ApplicationSubmitted, "", "", time.Now())
+	if !errors.Is(err, animalrescue.ErrValidation) {
+		t.Errorf("Transition without actor returned %v, want a validation error", err)
+	}
+}
+
+// newApplication creates an adopter and an adoptee on srv and submits an
+// application for them.
+func newApplication(t *testing.T, ctx context.Context, c *animalrescue.Client) *animalrescue.Application {
+	t.Helper()
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+		FirstName: animalrescue.String("Jane"),
+		LastName:  animalrescue.String("Doe"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptee, _, err := c.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := c.Applications.CreateApplication(ctx, animalrescue.NewApplication{
+		Adopter:     adopter,
+		Adoptee:     adoptee,
+		SubmittedBy: "jane",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestApplicationsService_workflow(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	app := newApplication(t, ctx, c)
+	if app.Status != animalrescue.ApplicationSubmitted || app.Version != 1 {
+		t.Fatalf("CreateApplication returned %v, want a submitted application at version 1", app)
+	}
+
+	steps := []animalrescue.ApplicationStatus{
+		animalrescue.ApplicationInReview,
+		animalrescue.ApplicationHomeCheck,
+		animalrescue.ApplicationApproved,
+	}
+	for _, to := range steps {
+		if app, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, to, "bob", ""); err != nil {
+			t.Fatalf("TransitionApplicationByID(%q) returned error: %v", to, err)
+		} else if app.Status != to {
+			t.Fatalf("TransitionApplicationByID(%q) returned status %q", to, app.Status)
+		}
+	}
+
+	got, err := c.Applications.GetApplicationByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The approval is recorded as two transitions, through approving.
+	if len(got.History) != 5 || got.Version != 5 || got.History[3].To != animalrescue.ApplicationApproving {
+		t.Errorf("GetApplicationByID returned %v, want 5 transitions through approving at version 5", got)
+	}
+	if got.AdoptionID == 0 {
+		t.Fatal("approved application has no adoption")
+	}
+	adoption, _, err := c.Adoptions.GetAdoptionByID(ctx, got.AdoptionID)
+	if err != nil {
+		t.Fatalf("GetAdoptionByID returned error: %v", err)
+	}
+	if adoption.Adoptee.ID != got.Adoptee.ID {
+		t.Errorf("adoption is of adoptee %d, want %d", adoption.Adoptee.ID, got.Adoptee.ID)
+	}
+
+	_, _, err = c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationRejected, "bob", "")
+	if !errors.Is(err, animalrescue.ErrIllegalTransition) {
+		t.Errorf("rejecting an approved application returned %v, want ErrIllegalTransition", err)
+	}
+
+	approved, err := c.Applications.ListAll(ctx, &animalrescue.ApplicationListOptions{Status: animalrescue.ApplicationApproved})
+	if err != nil || len(approved) != 1 || approved[0].ID != app.ID {
+		t.Errorf("ListAll(approved) returned %v, %v", approved, err)
+	}
+
+	if err := c.Applications.DeleteApplicationByID(ctx, app.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Applications.GetApplicationByID(ctx, app.ID); !errors.Is(err, animalrescue.ErrNotFound) {
+		t.Errorf("GetApplicationByID after delete returned %v, want ErrNotFound", err)
+	}
+}
+
+func TestApplicationsService_concurrentReviewers(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	app := newApplication(t, ctx, c)
+	for _, to := range []animalrescue.ApplicationStatus{animalrescue.ApplicationInReview, animalrescue.ApplicationHomeCheck} {
+		if _, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, to, "bob", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Whichever decision is stored first, the other one is reported as
+	// stale or, if it read the decided application, illegal.
+	reviewers := []struct {
+		actor string
+		to    animalrescue.ApplicationStatus
+	}{
+		{"bob", animalrescue.ApplicationApproved},
+		{"carol", animalrescue.ApplicationRejected},
+	}
+	stale, err := c.Applications.GetApplicationByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(reviewers))
+	for i, r := range reviewers {
+		wg.Add(1)
+		go func(i int, actor string, to animalrescue.ApplicationStatus) {
+			defer wg.Done()
+			_, _, errs[i] = c.Applications.TransitionApplicationByID(ctx, app.ID, to, actor, "")
+		}(i, r.actor, r.to)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errors.Is(err, animalrescue.ErrStaleApplication), errors.Is(err, animalrescue.ErrIllegalTransition):
+		default:
+			t.Errorf("reviewer %v got error %v", reviewers[i].actor, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("%d reviewers recorded their decision, want 1", succeeded)
+	}
+	got, err := c.Applications.GetApplicationByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An approval goes through approving.
+	want := 4
+	if got.Status == animalrescue.ApplicationApproved {
+		want = 5
+	}
+	if len(got.History) != want {
+		t.Errorf("application history is %v, want %d transitions", got.History, want)
+	}
+
+	// Storing a change made to a copy read before those decisions reports
+	// the lost update.
+	stale.Transition(animalrescue.ApplicationRejected, "dave", "", time.Now())
+	if err := c.ApplicationStore.Update(ctx, stale); err != animalrescue.ErrStaleApplication {
+		t.Errorf("Update of a stale application returned %v, want ErrStaleApplication", err)
+	}
+}
+
+// errorTracer is a Tracer recording the errors of the spans it starts.
+type errorTracer struct {
+	animalrescue.Tracer
+
+	mu     sync.Mutex
+	errors map[string][]error // by span name
+}
+
+type errorSpan struct {
+	animalrescue.Span
+
+	name string
+	t    *errorTracer
+}
+
+func (t *errorTracer) Start(ctx context.Context, name string, attrs ...animalrescue.Attribute) (context.Context, animalrescue.Span) {
+	ctx, span := t.Tracer.Start(ctx, name, attrs...)
+	return ctx, errorSpan{Span: span, name: name, t: t}
+}
+
+func (s errorSpan) RecordError(err error) {
+	s.t.mu.Lock()
+	defer s.t.mu.Unlock()
+	s.t.errors[s.name] = append(s.t.errors[s.name], err)
+}
+
+func TestApplicationsService_resumedApproval(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	// The response to the first creation of an adoption is lost after the
+	// server created it. Idempotency keys are dropped, as by APIs that
+	// ignore them.
+	lost := errors.New("connection reset")
+	var (
+		created int
+		lookups []http.Header
+	)
+	loseFirst := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Method == "GET" && strings.HasSuffix(req.URL.Path, "/adoptions") {
+				lookups = append(lookups, req.Header.Clone())
+			}
+			req.Header.Del("Idempotency-Key")
+			resp, err := next.RoundTrip(req)
+			if req.Method == "POST" && strings.HasSuffix(req.URL.Path, "/adoptions") {
+				created++
+				if created == 1 && err == nil {
+					resp.Body.Close()
+					return nil, lost
+				}
+			}
+			return resp, err
+		})
+	}
+	tracer := &errorTracer{Tracer: animalrescue.NoopTracer, errors: map[string][]error{}}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(loseFirst),
+		animalrescue.WithTracer(tracer),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := newApplication(t, ctx, c)
+	for _, to := range []animalrescue.ApplicationStatus{animalrescue.ApplicationInReview, animalrescue.ApplicationHomeCheck} {
+		if _, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, to, "bob", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, _, err = c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", "")
+	if !errors.Is(err, lost) {
+		t.Fatalf("approval returned %v, want the lost response", err)
+	}
+	if errs := tracer.errors["Applications.TransitionApplicationByID"]; len(errs) != 1 || !errors.Is(errs[0], lost) {
+		t.Errorf("approval span recorded %v, want the lost response", errs)
+	}
+	got, err := c.Applications.GetApplicationByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != animalrescue.ApplicationApproving || got.AdoptionID != 0 {
+		t.Fatalf("failed approval left %v, want an approving application", got)
+	}
+	got, _, err = c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", "",
+		animalrescue.WithRequestID("resume-1"),
+		animalrescue.WithIdempotencyKey("approve-jane"))
+	if err != nil {
+		t.Fatalf("resumed approval returned error: %v", err)
+	}
+	// The lookup of the adoption is sent with the caller's options, but
+	// without the idempotency key meant for a creation.
+	if len(lookups) != 1 || lookups[0].Get("X-Request-ID") != "resume-1" || lookups[0].Get("Idempotency-Key") != "" {
+		t.Errorf("resumed approval looked adoptions up with headers %v, want X-Request-ID and no Idempotency-Key", lookups)
+	}
+	adoptions, _, err := c.Adoptions.ListAll(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 1 || len(adoptions) != 1 {
+		t.Fatalf("resumed approval left %d adoptions after %d creations, want the first one", len(adoptions), created)
+	}
+	if got.Status != animalrescue.ApplicationApproved || got.AdoptionID != int64(adoptions[0].ID) {
+		t.Errorf("resumed approval returned %v, want it approved with adoption %d", got, adoptions[0].ID)
+	}
+}
+
+func TestApplicationsService_rejectedWhileApproving(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	app := newApplication(t, ctx, c)
+	for _, to := range []animalrescue.ApplicationStatus{animalrescue.ApplicationInReview, animalrescue.ApplicationHomeCheck} {
+		if _, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, to, "bob", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The adoptee is gone, so its adoption can never be created.
+	if _, err := c.Adoptees.DeleteAdopteeByID(ctx, int64(app.Adoptee.ID)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", ""); err == nil {
+			t.Fatal("approval of an application for a deleted adoptee succeeded")
+		}
+	}
+
+	got, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationRejected, "carol", "adoptee gone")
+	if err != nil {
+		t.Fatalf("rejecting an approving application returned error: %v", err)
+	}
+	last := got.History[len(got.History)-1]
+	if got.Status != animalrescue.ApplicationRejected || got.AdoptionID != 0 ||
+		last.From != animalrescue.ApplicationApproving || last.Actor != "carol" || last.Note != "adoptee gone" {
+		t.Errorf("rejection returned %v, want it rejected from approving by carol", got)
+	}
+}
+
+func TestApplicationsService_approvalKeys(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	// The first creation of an adoption fails before reaching the server,
+	// and the API ignores the filters of adoption listings.
+	lost := errors.New("connection reset")
+	var (
+		mu     sync.Mutex
+		keys   []string
+		failed bool
+	)
+	network := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasSuffix(req.URL.Path, "/adoptions") {
+				return next.RoundTrip(req)
+			}
+			if req.Method == "GET" {
+				req.URL.RawQuery = ""
+				return next.RoundTrip(req)
+			}
+			mu.Lock()
+			keys = append(keys, req.Header.Get("Idempotency-Key"))
+			fail := !failed && len(keys) > 1
+			failed = failed || fail
+			mu.Unlock()
+			if fail {
+				return nil, lost
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	newClient := func() *animalrescue.Client {
+		c, err := animalrescue.NewClientWithOptions(
+			animalrescue.WithBaseURL(srv.URL+"/"),
+			animalrescue.WithMiddleware(network),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	approve := func(c *animalrescue.Client, id int64) (*animalrescue.Application, error) {
+		for _, to := range []animalrescue.ApplicationStatus{animalrescue.ApplicationInReview, animalrescue.ApplicationHomeCheck} {
+			if _, _, err := c.Applications.TransitionApplicationByID(ctx, id, to, "bob", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		a, _, err := c.Applications.TransitionApplicationByID(ctx, id, animalrescue.ApplicationApproved, "bob", "")
+		return a, err
+	}
+
+	c := newClient()
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("John")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptee, _, err := c.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Bella"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated, _, err := c.Adoptions.CreateAdoption(ctx, animalrescue.NewAdoption{Adopter: adopter, Adoptee: adoptee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newApplication(t, ctx, c)
+	if _, err := approve(c, app.ID); !errors.Is(err, lost) {
+		t.Fatalf("approval returned %v, want the lost request", err)
+	}
+	got, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", "")
+	if err != nil {
+		t.Fatalf("resumed approval returned error: %v", err)
+	}
+	if got.AdoptionID == int64(unrelated.ID) {
+		t.Fatalf("resumed approval recorded unrelated adoption %d", unrelated.ID)
+	}
+	adoption, _, err := c.Adoptions.GetAdoptionByID(ctx, got.AdoptionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *adoption.Adopter.ID != *app.Adopter.ID || adoption.Adoptee.ID != app.Adoptee.ID {
+		t.Errorf("approval recorded adoption %v, want one of adoptee %d by adopter %d", adoption, app.Adoptee.ID, *app.Adopter.ID)
+	}
+	keys = keys[1:] // drop that of the unrelated adoption
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Fatalf("approvals were sent with idempotency keys %q, want the same key twice", keys)
+	}
+
+	// A client with a new store numbers its applications from 1 again,
+	// but sends a different key.
+	other := newClient()
+	app = newApplication(t, ctx, other)
+	if app.ID != got.ID {
+		t.Fatalf("new store assigned ID %d, want %d", app.ID, got.ID)
+	}
+	if _, err := approve(other, app.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[2] == keys[0] {
+		t.Errorf("approvals were sent with idempotency keys %q, want a new key for the other store", keys)
+	}
+}
+
+func TestApplicationsService_missingApprovalKey(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	var sent int
+	count := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent++
+			return next.RoundTrip(req)
+		})
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(count),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The store lost the key of an application left approving.
+	app := newApplication(t, ctx, c)
+	app.Status = animalrescue.ApplicationApproving
+	if err := c.ApplicationStore.Update(ctx, app); err != nil {
+		t.Fatal(err)
+	}
+	sent = 0
+	if _, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", ""); err == nil {
+		t.Fatal("approval without an approval key succeeded")
+	}
+	if sent != 0 {
+		t.Errorf("approval without an approval key sent %d requests, want none", sent)
+	}
+	got, err := c.Applications.GetApplicationByID(ctx, app.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != animalrescue.ApplicationApproving || got.ApprovalKey != "" {
+		t.Errorf("failed approval left %v, want it approving without a key", got)
+	}
+}
+
+func TestApplicationsService_approvalWithoutParties(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	// A store may hold applications that CreateApplication would reject.
+	for _, app := range []*animalrescue.Application{
+		{Status: animalrescue.ApplicationHomeCheck, Adoptee: &animalrescue.Adoptee{ID: 1}},
+		{Status: animalrescue.ApplicationHomeCheck, Adopter: &animalrescue.Adopter{}, Adoptee: &animalrescue.Adoptee{ID: 1}},
+		{Status: animalrescue.ApplicationApproving, Adopter: &animalrescue.Adopter{ID: animalrescue.Int64(1)}, ApprovalKey: "key"},
+	} {
+		if err := c.ApplicationStore.Create(ctx, app); err != nil {
+			t.Fatal(err)
+		}
+		_, _, err := c.Applications.TransitionApplicationByID(ctx, app.ID, animalrescue.ApplicationApproved, "bob", "")
+		if !errors.Is(err, animalrescue.ErrValidation) {
+			t.Errorf("approving %v returned %v, want a validation error", app, err)
+		}
+		got, err := c.Applications.GetApplicationByID(ctx, app.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != app.Status || got.Version != 1 {
+			t.Errorf("failed approval stored %v, want it unchanged", got)
+		}
+	}
+}
+
+func TestFileApplicationStore(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "animalrescue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "applications.json")
+
+	s, err := animalrescue.NewFileApplicationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := s.Create(ctx, &animalrescue.Application{Status: animalrescue.ApplicationSubmitted}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Delete(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := animalrescue.NewFileApplicationStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apps, err := reopened.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 1 || apps[0].ID != 1 || apps[0].Status != animalrescue.ApplicationSubmitted || apps[0].Version != 1 {
+		t.Errorf("reopened store holds %v, want application 1", apps)
+	}
+
+	// A creation that cannot be written takes no ID: the file is replaced
+	// by a directory for a while.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "blocked"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Create(ctx, new(animalrescue.Application)); err == nil {
+		t.Error("Create succeeded without writing the file")
+	}
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// IDs are never reused, even after the latest application is deleted.
+	a := new(animalrescue.Application)
+	if err := reopened.Create(ctx, a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != 3 {
+		t.Errorf("Create assigned ID %d, want 3", a.ID)
+	}
+}
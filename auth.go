@@ -0,0 +1,263 @@
+package animalrescue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultAPIKeyHeader = "X-API-Key"
+
+// APIKeyTransport is an http.RoundTripper that authenticates all requests
+// with a static API key, sent either in a header or as a query parameter.
+type APIKeyTransport struct {
+	Key string // API key
+
+	// Header is the request header carrying the key. Default: X-API-Key.
+	// It is ignored when QueryParam is set.
+	Header string
+
+	// QueryParam, if set, sends the key as this URL query parameter
+	// instead of in a header.
+	QueryParam string
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *APIKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context()) // per RoundTripper contract
+	if t.QueryParam != "" {
+		q := req2.URL.Query()
+		q.Set(t.QueryParam, t.Key)
+		req2.URL.RawQuery = q.Encode()
+	} else {
+		header := t.Header
+		if header == "" {
+			header = defaultAPIKeyHeader
+		}
+		req2.Header.Set(header, t.Key)
+	}
+	return transport(t.Transport).RoundTrip(req2)
+}
+
+// Client returns an *http.Client that makes requests that are authenticated
+// with the API key.
+func (t *APIKeyTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// BearerTokenTransport is an http.RoundTripper that authenticates all
+// requests with a static bearer token.
+type BearerTokenTransport struct {
+	Token string // bearer token
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *BearerTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context()) // per RoundTripper contract
+	req2.Header.Set("Authorization", "Bearer "+t.Token)
+	return transport(t.Transport).RoundTrip(req2)
+}
+
+// Client returns an *http.Client that makes requests that are authenticated
+// with the bearer token.
+func (t *BearerTokenTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Token is an OAuth2 access token issued by a token endpoint.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type,omitempty"`
+	ExpiresIn   int64     `json:"expires_in,omitempty"` // lifetime in seconds, as issued
+	Expiry      time.Time `json:"-"`                    // computed from ExpiresIn; zero means no expiry
+}
+
+// ClientCredentialsTransport is an http.RoundTripper that authenticates all
+// requests with an access token obtained through the OAuth2 client
+// credentials grant. Tokens are cached and refreshed shortly before they
+// expire, or after the API rejects one with 401 Unauthorized. Requests
+// needing a token while one is being fetched wait for that fetch.
+type ClientCredentialsTransport struct {
+	TokenURL     string   // token endpoint
+	ClientID     string   // OAuth2 client ID
+	ClientSecret string   // OAuth2 client secret
+	Scopes       []string // optional requested scopes
+
+	// ExpiryDelta is how long before its expiry a token is refreshed.
+	// Default: 30s.
+	ExpiryDelta time.Duration
+
+	// Transport is the underlying HTTP transport to use when making requests,
+	// including those to the token endpoint. It will default to
+	// http.DefaultTransport if nil.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	token    *Token
+	fetching *tokenFetch // in progress, if any
+}
+
+// tokenFetch is a request to the token endpoint, whose result is shared by
+// the requests waiting for it.
+type tokenFetch struct {
+	done  chan struct{} // closed once token and err are set
+	token *Token
+	err   error
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *ClientCredentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token(req)
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close() // per RoundTripper contract
+		}
+		return nil, err
+	}
+
+	req2 := req.Clone(req.Context()) // per RoundTripper contract
+	req2.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	resp, err := transport(t.Transport).RoundTrip(req2)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		t.invalidate(token)
+	}
+	return resp, err
+}
+
+// Client returns an *http.Client that makes requests that are authenticated
+// with client credentials.
+func (t *ClientCredentialsTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Token returns the cached access token, fetching a new one from the token
+// endpoint if none is cached or the cached one is about to expire. The
+// fetch is shared by the requests needing a token meanwhile, and runs on a
+// context of its own, limited to tokenFetchTimeout, so that it is not
+// canceled along with the request that started it. req, if non-nil,
+// supplies the context for waiting on the fetch.
+func (t *ClientCredentialsTransport) Token(req *http.Request) (*Token, error) {
+	delta := t.ExpiryDelta
+	if delta == 0 {
+		delta = 30 * time.Second
+	}
+
+	t.mu.Lock()
+	if t.token != nil && (t.token.Expiry.IsZero() || time.Now().Add(delta).Before(t.token.Expiry)) {
+		token := t.token
+		t.mu.Unlock()
+		return token, nil
+	}
+	f := t.fetching
+	if f == nil {
+		f = &tokenFetch{done: make(chan struct{})}
+		t.fetching = f
+		go t.fetch(f)
+	}
+	t.mu.Unlock()
+
+	ctx := context.Background()
+	if req != nil {
+		ctx = req.Context()
+	}
+	select {
+	case <-f.done:
+		return f.token, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// tokenFetchTimeout limits the time taken by a request to the token
+// endpoint.
+const tokenFetchTimeout = time.Minute
+
+// fetch performs f and caches the token it fetched. The token endpoint is
+// called without holding the lock, so that requests with a valid token or a
+// done context are not held up.
+func (t *ClientCredentialsTransport) fetch(f *tokenFetch) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenFetchTimeout)
+	defer cancel()
+	f.token, f.err = t.fetchToken(ctx)
+
+	t.mu.Lock()
+	if f.err == nil {
+		t.token = f.token
+	}
+	t.fetching = nil
+	t.mu.Unlock()
+	close(f.done)
+}
+
+// invalidate drops token from the cache, unless it has already been replaced.
+func (t *ClientCredentialsTransport) invalidate(token *Token) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.token == token {
+		t.token = nil
+	}
+}
+
+func (t *ClientCredentialsTransport) fetchToken(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(t.Scopes) > 0 {
+		form.Set("scope", strings.Join(t.Scopes, " "))
+	}
+
+	tokenReq, err := http.NewRequest("POST", t.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	tokenReq = tokenReq.WithContext(ctx)
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	tokenReq.Header.Set("Accept", "application/json")
+	tokenReq.SetBasicAuth(url.QueryEscape(t.ClientID), url.QueryEscape(t.ClientSecret))
+
+	resp, err := transport(t.Transport).RoundTrip(tokenReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if c := resp.StatusCode; c < 200 || c > 299 {
+		return nil, fmt.Errorf("fetching token: %v %s", resp.Status, body)
+	}
+
+	token := new(Token)
+	if err := json.Unmarshal(body, token); err != nil {
+		return nil, fmt.Errorf("decoding token: %v", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token endpoint returned no access_token")
+	}
+	if token.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+func transport(rt http.RoundTripper) http.RoundTripper {
+	if rt != nil {
+		return rt
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,283 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestClientCredentialsTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		lifetime time.Duration
+		revoke   int    // request before which the tokens are revoked, if any
+		failed   []bool // whether each request fails
+		issued   int    // tokens issued afterwards
+	}{
+		{
+			name:     "cached",
+			lifetime: time.Hour,
+			failed:   []bool{false, false, false},
+			issued:   1,
+		},
+		{
+			name:     "expiring",
+			lifetime: 10 * time.Second, // within the default ExpiryDelta
+			failed:   []bool{false, false, false},
+			issued:   3,
+		},
+		{
+			name:     "revoked",
+			lifetime: time.Hour,
+			revoke:   2,
+			failed:   []bool{false, true, false, false},
+			issued:   2,
+		},
+		{
+			name:     "wrong secret",
+			secret:   "guess",
+			lifetime: time.Hour,
+			failed:   []bool{true, true},
+			issued:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := animalrescuetest.NewTokenServer("shelter", "s3cret", tt.lifetime)
+			defer ts.Close()
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			srv.RequireAuth(func(r *http.Request) bool {
+				return ts.Valid(animalrescuetest.BearerToken(r))
+			})
+
+			secret := tt.secret
+			if secret == "" {
+				secret = "s3cret"
+			}
+			tp := &animalrescue.ClientCredentialsTransport{
+				TokenURL:     ts.URL,
+				ClientID:     "shelter",
+				ClientSecret: secret,
+			}
+			c, err := animalrescue.NewClientWithOptions(
+				animalrescue.WithHTTPClient(tp.Client()),
+				animalrescue.WithBaseURL(srv.URL+"/"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, wantFail := range tt.failed {
+				if i+1 == tt.revoke {
+					ts.Revoke()
+				}
+				_, _, err := c.Adopters.ListAll(context.Background(), nil)
+				if (err != nil) != wantFail {
+					t.Errorf("request %d returned error %v, want error: %v", i+1, err, wantFail)
+				}
+				if err != nil && tt.revoke != 0 && !errors.Is(err, animalrescue.ErrUnauthorized) {
+					t.Errorf("request %d returned %v, want ErrUnauthorized", i+1, err)
+				}
+			}
+			if n := ts.Issued(); n != tt.issued {
+				t.Errorf("token server issued %d tokens, want %d", n, tt.issued)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsTransport_concurrent(t *testing.T) {
+	ts := animalrescuetest.NewTokenServer("shelter", "s3cret", time.Hour)
+	defer ts.Close()
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	srv.RequireAuth(func(r *http.Request) bool {
+		return ts.Valid(animalrescuetest.BearerToken(r))
+	})
+
+	tp := &animalrescue.ClientCredentialsTransport{
+		TokenURL:     ts.URL,
+		ClientID:     "shelter",
+		ClientSecret: "s3cret",
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithHTTPClient(tp.Client()),
+		animalrescue.WithBaseURL(srv.URL+"/"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = c.Adopters.ListAll(context.Background(), nil)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("request %d returned error: %v", i, err)
+		}
+	}
+	if n := ts.Issued(); n != 1 {
+		t.Errorf("token server issued %d tokens to concurrent requests, want 1", n)
+	}
+}
+
+// closeRecorder is a request body recording whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (b *closeRecorder) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestClientCredentialsTransport_tokenError(t *testing.T) {
+	ts := animalrescuetest.NewTokenServer("shelter", "s3cret", time.Hour)
+	defer ts.Close()
+
+	tp := &animalrescue.ClientCredentialsTransport{
+		TokenURL:     ts.URL,
+		ClientID:     "shelter",
+		ClientSecret: "guess",
+	}
+	body := &closeRecorder{Reader: strings.NewReader(`{"first_name":"Jane"}`)}
+	req, err := http.NewRequest("POST", "http://api.example.com/adopters", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tp.RoundTrip(req); err == nil {
+		t.Fatal("RoundTrip succeeded with the wrong secret")
+	}
+	if !body.closed {
+		t.Error("RoundTrip left the request body open after failing to get a token")
+	}
+}
+
+func TestClientCredentialsTransport_slowTokenEndpoint(t *testing.T) {
+	ts := animalrescuetest.NewTokenServer("shelter", "s3cret", time.Hour)
+	defer ts.Close()
+
+	// The token endpoint answers once release is closed.
+	release := make(chan struct{})
+	fetching := make(chan struct{}, 1)
+	tp := &animalrescue.ClientCredentialsTransport{
+		TokenURL:     ts.URL,
+		ClientID:     "shelter",
+		ClientSecret: "s3cret",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fetching <- struct{}{}
+			<-release
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := tp.Token(nil)
+		first <- err
+	}()
+	<-fetching
+
+	// A request whose context is done does not wait for the fetch.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequest("GET", "http://api.example.com/adopters", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tp.Token(req.WithContext(ctx)); err != context.Canceled {
+		t.Errorf("Token with a canceled context returned %v while a token was fetched, want context.Canceled", err)
+	}
+
+	// Requests waiting for the fetch share its token.
+	second := make(chan *animalrescue.Token, 1)
+	go func() {
+		token, _ := tp.Token(nil)
+		second <- token
+	}()
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("Token returned error: %v", err)
+	}
+	if token := <-second; token == nil || !ts.Valid(token.AccessToken) {
+		t.Errorf("waiting Token returned %v, want the fetched token", token)
+	}
+	if n := ts.Issued(); n != 1 {
+		t.Errorf("token server issued %d tokens, want 1", n)
+	}
+}
+
+func TestClientCredentialsTransport_firstCallerCanceled(t *testing.T) {
+	ts := animalrescuetest.NewTokenServer("shelter", "s3cret", time.Hour)
+	defer ts.Close()
+
+	// The token endpoint answers once release is closed.
+	release := make(chan struct{})
+	fetching := make(chan struct{}, 1)
+	tp := &animalrescue.ClientCredentialsTransport{
+		TokenURL:     ts.URL,
+		ClientID:     "shelter",
+		ClientSecret: "s3cret",
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			fetching <- struct{}{}
+			<-release
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+	req, err := http.NewRequest("GET", "http://api.example.com/adopters", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request starting the fetch is canceled while it is in progress.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := tp.Token(req.WithContext(ctx))
+		first <- err
+	}()
+	<-fetching
+
+	second := make(chan error, 1)
+	var token *animalrescue.Token
+	go func() {
+		var err error
+		token, err = tp.Token(req)
+		second <- err
+	}()
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("canceled Token returned %v, want context.Canceled", err)
+	}
+
+	close(release)
+	if err := <-second; err != nil {
+		t.Fatalf("waiting Token returned error: %v", err)
+	}
+	if token == nil || !ts.Valid(token.AccessToken) {
+		t.Errorf("waiting Token returned %v, want the fetched token", token)
+	}
+	if n := ts.Issued(); n != 1 {
+		t.Errorf("token server issued %d tokens, want 1", n)
+	}
+}
@@ -0,0 +1,40 @@
+package animalrescue
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		attempt    int
+		retryAfter string
+		min, max   time.Duration
+	}{
+		{"default initial", RetryPolicy{}, 1, "", 200 * time.Millisecond, 200 * time.Millisecond},
+		{"default growth", RetryPolicy{}, 3, "", 800 * time.Millisecond, 800 * time.Millisecond},
+		{"default cap", RetryPolicy{}, 10, "", 10 * time.Second, 10 * time.Second},
+		{"multiplier", RetryPolicy{InitialBackoff: time.Second, Multiplier: 3}, 3, "", 9 * time.Second, 9 * time.Second},
+		{"cap", RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}, 4, "", 3 * time.Second, 3 * time.Second},
+		{"jitter", RetryPolicy{InitialBackoff: time.Second, Jitter: 0.5}, 1, "", 500 * time.Millisecond, 1500 * time.Millisecond},
+		{"longer Retry-After", RetryPolicy{InitialBackoff: time.Second}, 1, "7", 7 * time.Second, 7 * time.Second},
+		{"shorter Retry-After", RetryPolicy{InitialBackoff: time.Second}, 2, "1", 2 * time.Second, 2 * time.Second},
+		{"invalid Retry-After", RetryPolicy{InitialBackoff: time.Second}, 1, "soon", time.Second, time.Second},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.retryAfter != "" {
+			resp.Header.Set(headerRetryAfter, tt.retryAfter)
+		}
+		for i := 0; i < 20; i++ {
+			if got := tt.policy.backoff(tt.attempt, resp); got < tt.min || got > tt.max {
+				t.Errorf("%v: backoff(%d) = %v, want between %v and %v", tt.name, tt.attempt, got, tt.min, tt.max)
+				break
+			}
+		}
+	}
+}
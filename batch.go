@@ -0,0 +1,123 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const defaultBatchConcurrency = 4
+
+// ErrBatchStopped is reported for the items of a batch that were never sent
+// because an earlier item failed and BatchOptions.StopOnError was set.
+var ErrBatchStopped = errors.New("animalrescue: batch stopped after an earlier error")
+
+// BatchOptions specifies the optional parameters to the CreateMany,
+// EditMany and DeleteMany methods.
+type BatchOptions struct {
+	// Concurrency is the maximum number of requests in flight at once.
+	// Default: 4.
+	Concurrency int
+
+	// StopOnError stops sending further items as soon as one fails. Items
+	// already in flight are canceled, and items never sent report
+	// ErrBatchStopped. By default, every item is attempted.
+	StopOnError bool
+}
+
+// DeleteResult is the outcome of deleting the item at Index of a DeleteMany
+// call.
+type DeleteResult struct {
+	Index    int
+	ID       int64
+	Response *Response
+	Err      error
+}
+
+// runBatch calls do for each index in [0, n) from a pool of workers bounded
+// by opts.Concurrency. Indices that are never passed to do, because ctx was
+// canceled or an earlier call failed with StopOnError set, are passed to
+// skip along with the reason instead.
+func runBatch(ctx context.Context, n int, opts BatchOptions, do func(ctx context.Context, i int) error, skip func(i int, err error)) {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = defaultBatchConcurrency
+	}
+	if workers > n {
+		workers = n
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		stopped bool
+		skipped []int // indices received after ctx was done
+	)
+	indices := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				// The dispatcher may hand out an index just as ctx is
+				// canceled, for instance by a failure in another worker.
+				if ctx.Err() != nil {
+					mu.Lock()
+					skipped = append(skipped, i)
+					mu.Unlock()
+					continue
+				}
+				if err := do(ctx, i); err != nil && opts.StopOnError {
+					mu.Lock()
+					stopped = true
+					mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
+	i := 0
+dispatch:
+	for ; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	reason := ctx.Err()
+	if stopped {
+		reason = ErrBatchStopped
+	}
+	for _, j := range skipped {
+		skip(j, reason)
+	}
+	for ; i < n; i++ {
+		skip(i, reason)
+	}
+}
+
+// deleteMany deletes each of ids with del, as described by opts.
+func deleteMany(ctx context.Context, ids []int64, opts BatchOptions, del func(context.Context, int64, ...RequestOption) (*Response, error), reqOpts []RequestOption) []DeleteResult {
+	results := make([]DeleteResult, len(ids))
+	for i, id := range ids {
+		results[i] = DeleteResult{Index: i, ID: id}
+	}
+	runBatch(ctx, len(ids), opts, func(ctx context.Context, i int) error {
+		results[i].Response, results[i].Err = del(ctx, ids[i], reqOpts...)
+		return results[i].Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
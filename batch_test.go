@@ -0,0 +1,248 @@
+package animalrescue_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// slowNetwork delays every request, tracks how many are in flight at once,
+// and fails those whose body contains "fail".
+type slowNetwork struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	sent        int
+	next        http.RoundTripper
+}
+
+func (n *slowNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	n.mu.Lock()
+	n.sent++
+	n.inFlight++
+	if n.inFlight > n.maxInFlight {
+		n.maxInFlight = n.inFlight
+	}
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		n.inFlight--
+		n.mu.Unlock()
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	if bytes.Contains(body, []byte("fail")) {
+		return nil, errors.New("connection reset by peer")
+	}
+	return n.next.RoundTrip(req)
+}
+
+func newSlowClient(t *testing.T, srv *animalrescuetest.Server, n *slowNetwork) *animalrescue.Client {
+	t.Helper()
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			n.next = next
+			return n
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAdoptersService_CreateMany(t *testing.T) {
+	tests := []struct {
+		name        string
+		names       []string
+		opts        animalrescue.BatchOptions
+		maxInFlight int
+		sent        int
+		failed      map[int]error // errors expected by index; nil matches any error
+	}{
+		{
+			name:        "default concurrency",
+			names:       strings.Split("a b c d e f g h i j", " "),
+			maxInFlight: 4,
+			sent:        10,
+		},
+		{
+			name:        "bounded concurrency",
+			names:       strings.Split("a b c d e f", " "),
+			opts:        animalrescue.BatchOptions{Concurrency: 2},
+			maxInFlight: 2,
+			sent:        6,
+		},
+		{
+			name:        "fewer items than workers",
+			names:       []string{"a", "b"},
+			opts:        animalrescue.BatchOptions{Concurrency: 8},
+			maxInFlight: 2,
+			sent:        2,
+		},
+		{
+			name:        "failures",
+			names:       []string{"a", "fail", "c", "fail", "e"},
+			opts:        animalrescue.BatchOptions{Concurrency: 2},
+			maxInFlight: 2,
+			sent:        5,
+			failed:      map[int]error{1: nil, 3: nil},
+		},
+		{
+			name:        "stop on error",
+			names:       []string{"a", "fail", "c", "d", "e"},
+			opts:        animalrescue.BatchOptions{Concurrency: 1, StopOnError: true},
+			maxInFlight: 1,
+			sent:        2,
+			failed:      map[int]error{1: nil, 2: animalrescue.ErrBatchStopped, 3: animalrescue.ErrBatchStopped, 4: animalrescue.ErrBatchStopped},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			net := &slowNetwork{}
+			c := newSlowClient(t, srv, net)
+
+			adopters := make([]animalrescue.NewAdopter, len(tt.names))
+			for i, name := range tt.names {
+				adopters[i].FirstName = animalrescue.String(name)
+			}
+			results := c.Adopters.CreateMany(context.Background(), adopters, tt.opts)
+
+			if len(results) != len(adopters) {
+				t.Fatalf("CreateMany returned %d results, want %d", len(results), len(adopters))
+			}
+			created := 0
+			for i, res := range results {
+				if res.Index != i {
+					t.Errorf("result %d has Index %d", i, res.Index)
+				}
+				want, fail := tt.failed[i]
+				switch {
+				case fail && res.Err == nil:
+					t.Errorf("result %d succeeded, want an error", i)
+				case fail && want != nil && !errors.Is(res.Err, want):
+					t.Errorf("result %d failed with %v, want %v", i, res.Err, want)
+				case !fail && res.Err != nil:
+					t.Errorf("result %d failed with %v", i, res.Err)
+				case !fail && *res.Adopter.FirstName != tt.names[i]:
+					t.Errorf("result %d holds adopter %v, want %v", i, res.Adopter, tt.names[i])
+				case !fail:
+					created++
+				}
+			}
+
+			if net.maxInFlight > tt.maxInFlight {
+				t.Errorf("%d requests were in flight at once, want at most %d", net.maxInFlight, tt.maxInFlight)
+			}
+			if net.sent != tt.sent {
+				t.Errorf("%d requests were sent, want %d", net.sent, tt.sent)
+			}
+			all, _, err := srv.Client().Adopters.ListAll(context.Background(), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != created {
+				t.Errorf("server holds %d adopters, want %d", len(all), created)
+			}
+		})
+	}
+}
+
+func TestAdoptersService_EditMany(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	var edits []animalrescue.AdopterEdit
+	for i := 0; i < 3; i++ {
+		a, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String(fmt.Sprint(i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		edits = append(edits, animalrescue.AdopterEdit{ID: *a.ID, Adopter: animalrescue.NewAdopter{City: animalrescue.String("Austin")}})
+	}
+	edits = append(edits, animalrescue.AdopterEdit{ID: 404, Adopter: animalrescue.NewAdopter{City: animalrescue.String("Austin")}})
+
+	c := newSlowClient(t, srv, &slowNetwork{})
+	for i, res := range c.Adopters.EditMany(ctx, edits, animalrescue.BatchOptions{}) {
+		if i == 3 {
+			if !errors.Is(res.Err, animalrescue.ErrNotFound) {
+				t.Errorf("editing a missing adopter returned %v, want ErrNotFound", res.Err)
+			}
+			continue
+		}
+		if res.Err != nil || res.Adopter.City == nil || *res.Adopter.City != "Austin" {
+			t.Errorf("result %d is %v, %v, want an adopter in Austin", i, res.Adopter, res.Err)
+		}
+	}
+}
+
+func TestAdoptersService_DeleteMany(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		a, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String(fmt.Sprint(i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, *a.ID)
+	}
+
+	c := newSlowClient(t, srv, &slowNetwork{})
+	results := c.Adopters.DeleteMany(ctx, append(ids, 404), animalrescue.BatchOptions{})
+	for i, res := range results {
+		if res.Index != i || (i < 3 && res.ID != ids[i]) {
+			t.Errorf("result %d is for item %d, ID %d", i, res.Index, res.ID)
+		}
+		if i < 3 && res.Err != nil {
+			t.Errorf("deleting adopter %d returned error: %v", res.ID, res.Err)
+		}
+	}
+	if err := results[3].Err; !errors.Is(err, animalrescue.ErrNotFound) {
+		t.Errorf("deleting a missing adopter returned %v, want ErrNotFound", err)
+	}
+	if all, _, _ := srv.Client().Adopters.ListAll(ctx, nil); len(all) != 0 {
+		t.Errorf("server holds adopters %v after DeleteMany", all)
+	}
+}
+
+func TestAdoptersService_CreateMany_canceled(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	net := &slowNetwork{}
+	c := newSlowClient(t, srv, net)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	adopters := []animalrescue.NewAdopter{{FirstName: animalrescue.String("a")}, {FirstName: animalrescue.String("b")}}
+	for i, res := range c.Adopters.CreateMany(ctx, adopters, animalrescue.BatchOptions{}) {
+		if !errors.Is(res.Err, context.Canceled) {
+			t.Errorf("result %d returned %v, want context.Canceled", i, res.Err)
+		}
+	}
+	if net.sent != 0 {
+		t.Errorf("%d requests were sent after the context was canceled", net.sent)
+	}
+}
@@ -0,0 +1,308 @@
+package animalrescue
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// headerFromCache is set on responses served from a cache by
+// CachingTransport, and reported by Response.FromCache.
+const headerFromCache = "X-From-Cache"
+
+// A Cache stores serialized HTTP responses for CachingTransport. Its
+// methods may be called concurrently.
+type Cache interface {
+	// Get returns the response stored under key, if any.
+	Get(key string) ([]byte, bool)
+
+	// Set stores resp under key, replacing any previous value.
+	Set(key string, resp []byte)
+
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+}
+
+// CachingTransport is an http.RoundTripper that caches the responses to GET
+// requests that carry an ETag or Last-Modified header. Cached responses are
+// revalidated with If-None-Match and If-Modified-Since on every request, and
+// served from the cache when the server answers 304 Not Modified.
+//
+// A successful POST, PUT, PATCH or DELETE request drops the cached response
+// for its URL and, for an entity such as adopter/1, for the unfiltered first
+// page of its collection, adopters. Other listings of the collection, with
+// filters or pages, are left to their revalidation.
+//
+// Responses are cached under their URL and the credentials of the request,
+// so that users sharing a Cache never see each other's responses. The
+// credentials must therefore be set before the request reaches the
+// CachingTransport: it must be the Transport of any transport adding them,
+// not the other way around. WithCache arranges this for the transports of
+// this package.
+type CachingTransport struct {
+	Cache Cache
+
+	// KeyHeaders lists the request headers that carry credentials, whose
+	// values are part of the cache key along with the URL.
+	// Default: Authorization and X-API-Key.
+	KeyHeaders []string
+
+	// Transport is the underlying HTTP transport to use when making requests.
+	// It will default to http.DefaultTransport if nil.
+	Transport http.RoundTripper
+}
+
+var defaultKeyHeaders = []string{"Authorization", defaultAPIKeyHeader}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case "GET":
+	case "POST", "PUT", "PATCH", "DELETE":
+		resp, err := transport(t.Transport).RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			// The resource changed; drop any copy of it and of the
+			// collection listing it.
+			t.Cache.Delete(t.key(req, req.URL))
+			if u := collectionURL(req.URL); u != nil {
+				t.Cache.Delete(t.key(req, u))
+			}
+		}
+		return resp, err
+	default:
+		return transport(t.Transport).RoundTrip(req)
+	}
+
+	key := t.key(req, req.URL)
+	var cached *http.Response
+	if data, ok := t.Cache.Get(key); ok {
+		if resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), req); err == nil {
+			cached = resp
+		} else {
+			t.Cache.Delete(key)
+		}
+	}
+
+	if cached != nil {
+		etag, lastModified := cached.Header.Get("ETag"), cached.Header.Get("Last-Modified")
+		req = req.Clone(req.Context()) // per RoundTripper contract
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := transport(t.Transport).RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		// Headers of the 304, such as rate limits or a later Expires, are
+		// newer than the cached ones: store them along with the body.
+		for k, v := range resp.Header {
+			if k != "Content-Length" {
+				cached.Header[k] = v
+			}
+		}
+		if data, err := httputil.DumpResponse(cached, true); err == nil {
+			t.Cache.Set(key, data)
+		}
+		cached.Header.Set(headerFromCache, "1")
+		return cached, nil
+	}
+
+	if resp.StatusCode == http.StatusOK {
+		if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+			if data, err := httputil.DumpResponse(resp, true); err == nil {
+				t.Cache.Set(key, data)
+			}
+		} else if cached != nil {
+			// The response can no longer be revalidated.
+			t.Cache.Delete(key)
+		}
+	}
+	return resp, nil
+}
+
+// Client returns an *http.Client that caches responses.
+func (t *CachingTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// key returns the key under which the response to a GET request for u with
+// the credentials of req is cached: u, which holds credentials sent as query
+// parameters, followed by a hash of the credentials sent in t.KeyHeaders, if
+// any.
+func (t *CachingTransport) key(req *http.Request, u *url.URL) string {
+	headers := t.KeyHeaders
+	if headers == nil {
+		headers = defaultKeyHeaders
+	}
+	h := sha256.New()
+	found := false
+	for _, name := range headers {
+		for _, v := range req.Header.Values(name) {
+			fmt.Fprintf(h, "%s: %s\n", http.CanonicalHeaderKey(name), v)
+			found = true
+		}
+	}
+
+	key := u.String()
+	if found {
+		key += " " + hex.EncodeToString(h.Sum(nil)[:16])
+	}
+	return key
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entries once it holds MaxEntries of them.
+type MemoryCache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewMemoryCache returns a MemoryCache holding up to maxEntries responses.
+// If maxEntries is zero, the cache has no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements the Cache interface.
+func (c *MemoryCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*memoryCacheEntry).data, true
+	}
+	return nil, false
+}
+
+// Set implements the Cache interface.
+func (c *MemoryCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.ll.MoveToFront(e)
+		e.Value.(*memoryCacheEntry).data = data
+		return
+	}
+	c.entries[key] = c.ll.PushFront(&memoryCacheEntry{key, data})
+	if c.maxEntries > 0 && c.ll.Len() > c.maxEntries {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete implements the Cache interface.
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.ll.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of responses in the cache.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+// DiskCache is a Cache that stores each response in a file of a directory,
+// so that it survives restarts.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache storing responses in dir, which is
+// created if it does not exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:]))
+}
+
+// Get implements the Cache interface.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Set implements the Cache interface. Errors writing the file are ignored,
+// since a missing entry only costs a full response.
+func (c *DiskCache) Set(key string, data []byte) {
+	f, err := ioutil.TempFile(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		// Rename is atomic, so readers never see a partial file.
+		err = os.Rename(f.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// Delete implements the Cache interface.
+func (c *DiskCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
+
+// collectionURL returns the URL of the collection holding the entity at u,
+// such as .../adopters for .../adopter/1, or nil if u is not an entity URL.
+func collectionURL(u *url.URL) *url.URL {
+	dir, id := path.Split(u.Path)
+	if _, err := strconv.Atoi(id); err != nil || strings.Trim(dir, "/") == "" {
+		return nil
+	}
+	c := *u
+	c.Path, c.RawPath, c.RawQuery = strings.TrimSuffix(dir, "/")+"s", "", ""
+	return &c
+}
@@ -0,0 +1,381 @@
+package animalrescue_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// recordingCache is a Cache that records the values stored in it.
+type recordingCache struct {
+	*animalrescue.MemoryCache
+
+	mu      sync.Mutex
+	sets    [][]byte
+	deletes int
+}
+
+func (c *recordingCache) Set(key string, data []byte) {
+	c.mu.Lock()
+	c.sets = append(c.sets, data)
+	c.mu.Unlock()
+	c.MemoryCache.Set(key, data)
+}
+
+func (c *recordingCache) Delete(key string) {
+	c.mu.Lock()
+	c.deletes++
+	c.mu.Unlock()
+	c.MemoryCache.Delete(key)
+}
+
+func TestCachingTransport_revalidation(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	srv.SetRateLimit(100, time.Hour)
+	ctx := context.Background()
+	adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &recordingCache{MemoryCache: animalrescue.NewMemoryCache(0)}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithCache(cache),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() (*animalrescue.Adopter, *animalrescue.Response) {
+		t.Helper()
+		a, resp, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a, resp
+	}
+
+	a, resp := get()
+	if resp.FromCache || len(cache.sets) != 1 {
+		t.Fatalf("first GET: FromCache = %v with %d entries stored, want a stored network response", resp.FromCache, len(cache.sets))
+	}
+
+	a, resp = get()
+	if !resp.FromCache || *a.FirstName != "Jane" {
+		t.Fatalf("second GET returned %v with FromCache = %v, want Jane from the cache", a, resp.FromCache)
+	}
+	// The entry is refreshed with the headers of the 304.
+	if len(cache.sets) != 2 {
+		t.Fatalf("cache was set %d times, want the entry refreshed after the 304", len(cache.sets))
+	}
+	stored := cache.sets[1]
+	if want := "X-Ratelimit-Remaining: " + strconv.Itoa(resp.Rate.Remaining); !bytes.Contains(stored, []byte(want)) {
+		t.Errorf("refreshed entry lacks %q:\n%s", want, stored)
+	}
+	if bytes.Contains(stored, []byte("X-From-Cache")) {
+		t.Errorf("refreshed entry holds the X-From-Cache marker:\n%s", stored)
+	}
+
+	if _, _, err := c.Adopters.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{FirstName: animalrescue.String("Janet")}); err != nil {
+		t.Fatal(err)
+	}
+	// The edit drops the adopter and the adopters listing.
+	if cache.deletes != 2 {
+		t.Errorf("edit deleted %d entries, want 2", cache.deletes)
+	}
+	a, resp = get()
+	if resp.FromCache || *a.FirstName != "Janet" {
+		t.Errorf("GET after edit returned %v with FromCache = %v, want Janet from the server", a, resp.FromCache)
+	}
+}
+
+// entityServer is a transport answering requests for any URL with a body
+// naming it, tagged with ETag unless noETag is set, and with status to
+// requests other than GET.
+type entityServer struct {
+	status int
+	noETag bool
+	sent   []*http.Request
+}
+
+func (s *entityServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.sent = append(s.sent, req)
+	resp := &http.Response{
+		StatusCode: s.status,
+		Header:     make(http.Header),
+		Body:       ioutil.NopCloser(strings.NewReader(req.URL.String())),
+		Request:    req,
+	}
+	if req.Method != "GET" {
+		return resp, nil
+	}
+	resp.StatusCode = http.StatusOK
+	if !s.noETag {
+		etag := `"` + req.URL.String() + `"`
+		resp.Header.Set("ETag", etag)
+		if req.Header.Get("If-None-Match") == etag {
+			resp.StatusCode = http.StatusNotModified
+			resp.Body = http.NoBody
+		}
+	}
+	return resp, nil
+}
+
+func TestCachingTransport_writes(t *testing.T) {
+	const base = "https://api.example.com/v1/"
+	cached := []string{"adopter/1", "adopter/2", "adopters", "adopters?page=2"}
+	tests := []struct {
+		method string
+		path   string
+		status int
+		want   []string // entries left
+	}{
+		{"POST", "adopters", http.StatusCreated, []string{"adopter/1", "adopter/2", "adopters?page=2"}},
+		{"PUT", "adopter/1", http.StatusOK, []string{"adopter/2", "adopters?page=2"}},
+		{"PATCH", "adopter/1", http.StatusOK, []string{"adopter/2", "adopters?page=2"}},
+		{"DELETE", "adopter/1", http.StatusNoContent, []string{"adopter/2", "adopters?page=2"}},
+		{"PATCH", "adopter/1", http.StatusConflict, cached},
+		{"PATCH", "adoptee/1", http.StatusOK, cached},
+		{"HEAD", "adopter/1", http.StatusOK, cached},
+		{"OPTIONS", "adopters", http.StatusNoContent, cached},
+	}
+
+	for _, tt := range tests {
+		srv := &entityServer{status: tt.status}
+		cache := animalrescue.NewMemoryCache(0)
+		c := &animalrescue.CachingTransport{Cache: cache, Transport: srv}
+		send := func(method, path string) {
+			t.Helper()
+			req, err := http.NewRequest(method, base+path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+		}
+		for _, path := range cached {
+			send("GET", path)
+		}
+
+		send(tt.method, tt.path)
+		var got []string
+		for _, path := range cached {
+			if _, ok := cache.Get(base + path); ok {
+				got = append(got, path)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v %v answered %d left %q cached, want %q", tt.method, tt.path, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestCachingTransport_noValidators(t *testing.T) {
+	srv := &entityServer{}
+	cache := animalrescue.NewMemoryCache(0)
+	c := &animalrescue.CachingTransport{Cache: cache, Transport: srv}
+	get := func() {
+		t.Helper()
+		req, err := http.NewRequest("GET", "https://api.example.com/adopter/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := c.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	get()
+	if cache.Len() != 1 {
+		t.Fatalf("cache holds %d entries after a tagged response, want 1", cache.Len())
+	}
+
+	// The server stops tagging the resource, whose copy can no longer be
+	// revalidated.
+	srv.noETag = true
+	get()
+	if cache.Len() != 0 {
+		t.Errorf("cache holds %d entries after an untagged response, want none", cache.Len())
+	}
+	get()
+	if inm := srv.sent[2].Header.Get("If-None-Match"); inm != "" {
+		t.Errorf("request after an untagged response was sent with If-None-Match %v", inm)
+	}
+}
+
+func TestWithCache_perCredentials(t *testing.T) {
+	ts := animalrescuetest.NewTokenServer("shelter", "s3cret", time.Hour)
+	defer ts.Close()
+
+	tests := []struct {
+		name   string
+		client func(user string) *http.Client
+	}{
+		{"api key", func(user string) *http.Client {
+			return (&animalrescue.APIKeyTransport{Key: user}).Client()
+		}},
+		{"api key in custom header", func(user string) *http.Client {
+			return (&animalrescue.APIKeyTransport{Key: user, Header: "X-Shelter-Key"}).Client()
+		}},
+		{"api key in query", func(user string) *http.Client {
+			return (&animalrescue.APIKeyTransport{Key: user, QueryParam: "key"}).Client()
+		}},
+		{"bearer token", func(user string) *http.Client {
+			return (&animalrescue.BearerTokenTransport{Token: user}).Client()
+		}},
+		{"client credentials", func(user string) *http.Client {
+			return (&animalrescue.ClientCredentialsTransport{TokenURL: ts.URL, ClientID: "shelter", ClientSecret: "s3cret"}).Client()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			ctx := context.Background()
+			adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir, err := ioutil.TempDir("", "animalrescue")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			cache, err := animalrescue.NewDiskCache(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fromCache := func(user string) []bool {
+				c, err := animalrescue.NewClientWithOptions(
+					animalrescue.WithHTTPClient(tt.client(user)),
+					animalrescue.WithBaseURL(srv.URL+"/"),
+					animalrescue.WithCache(cache),
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var got []bool
+				for i := 0; i < 2; i++ {
+					_, resp, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got = append(got, resp.FromCache)
+				}
+				return got
+			}
+
+			for _, user := range []string{"alice", "bob"} {
+				if got, want := fromCache(user), []bool{false, true}; !reflect.DeepEqual(got, want) {
+					t.Errorf("%v's requests were served from the cache: %v, want %v", user, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCache_eviction(t *testing.T) {
+	tests := []struct {
+		name string
+		max  int
+		ops  []string // "set k", "get k" or "del k"
+		want []string // keys held afterwards
+	}{
+		{"unbounded", 0, []string{"set a", "set b", "set c"}, []string{"a", "b", "c"}},
+		{"oldest evicted", 2, []string{"set a", "set b", "set c"}, []string{"b", "c"}},
+		{"get refreshes", 2, []string{"set a", "set b", "get a", "set c"}, []string{"a", "c"}},
+		{"set refreshes", 2, []string{"set a", "set b", "set a", "set c"}, []string{"a", "c"}},
+		{"delete frees", 2, []string{"set a", "set b", "del a", "set c"}, []string{"b", "c"}},
+	}
+
+	for _, tt := range tests {
+		c := animalrescue.NewMemoryCache(tt.max)
+		for _, op := range tt.ops {
+			key := op[4:]
+			switch op[:3] {
+			case "set":
+				c.Set(key, []byte(key))
+			case "get":
+				c.Get(key)
+			case "del":
+				c.Delete(key)
+			}
+		}
+
+		var got []string
+		for _, key := range []string{"a", "b", "c"} {
+			if data, ok := c.Get(key); ok {
+				if string(data) != key {
+					t.Errorf("%v: Get(%v) = %q", tt.name, key, data)
+				}
+				got = append(got, key)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) || c.Len() != len(tt.want) {
+			t.Errorf("%v: cache holds %v (Len %d), want %v", tt.name, got, c.Len(), tt.want)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "animalrescue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := animalrescue.NewDiskCache(dir + "/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("https://example.com/adopter/1"); ok {
+		t.Error("Get on an empty cache found an entry")
+	}
+	c.Set("https://example.com/adopter/1", []byte("one"))
+	c.Set("https://example.com/adopter/2", []byte("two"))
+	c.Set("https://example.com/adopter/1", []byte("uno"))
+
+	// A cache opened on the same directory sees the same entries.
+	reopened, err := animalrescue.NewDiskCache(dir + "/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"https://example.com/adopter/1": "uno", "https://example.com/adopter/2": "two"} {
+		if data, ok := reopened.Get(key); !ok || string(data) != want {
+			t.Errorf("Get(%v) = %q, %v, want %q", key, data, ok, want)
+		}
+	}
+
+	reopened.Delete("https://example.com/adopter/1")
+	if _, ok := c.Get("https://example.com/adopter/1"); ok {
+		t.Error("Get found a deleted entry")
+	}
+	files, err := ioutil.ReadDir(dir + "/cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Errorf("cache directory holds %d files, want 1", len(files))
+	}
+}
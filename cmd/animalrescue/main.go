@@ -0,0 +1,153 @@
+// Command animalrescue is a command-line client for the Animal Rescue API.
+//
+// Usage:
+//
+//	animalrescue [global flags] <resource> <action> [flags] [id]
+//
+// Resources are adopters, adoptees, adoptions and petprefs. Actions are
+// list, get, create, edit and delete; adoptions cannot be edited.
+//
+// Global flags may also be set through the environment:
+//
+//	-base-url  ANIMALRESCUE_BASE_URL  API base URL
+//	-token     ANIMALRESCUE_TOKEN     bearer token
+//	-api-key   ANIMALRESCUE_API_KEY   API key, sent in the X-API-Key header
+//
+// Create and edit read the entity from a JSON file given with -file ("-"
+// for standard input), from flags named after its fields, or both, with
+// flags taking precedence:
+//
+//	animalrescue adoptees create -name Rex -breed "Border Collie" -age puppy
+//	animalrescue adopters edit -file adopter.json 42
+//	animalrescue -o yaml adoptions list -all
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+const usage = `usage: animalrescue [global flags] <resource> <action> [flags] [id]
+
+Resources:
+  adopters, adoptees, adoptions, petprefs
+
+Actions:
+  list    list entities (-page, -per-page, -all, -sort, -direction, filters)
+  get     show the entity with the given ID
+  create  create an entity from -file and/or field flags
+  edit    edit the entity with the given ID from -file and/or field flags
+  delete  delete the entity with the given ID
+
+Run "animalrescue <resource> <action> -h" for the flags of an action.
+
+Global flags:
+`
+
+// errUsage reports a command line that could not be understood. The
+// details have already been printed.
+var errUsage = errors.New("usage")
+
+type config struct {
+	baseURL string
+	token   string
+	apiKey  string
+	output  string
+	timeout time.Duration
+	verbose bool
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("animalrescue: ")
+
+	err := run(context.Background(), os.Args[1:], os.Stdout, os.Stderr)
+	switch {
+	case err == errUsage:
+		os.Exit(2)
+	case err != nil:
+		log.Print(err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	var cfg config
+	fs := flag.NewFlagSet("animalrescue", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&cfg.baseURL, "base-url", os.Getenv("ANIMALRESCUE_BASE_URL"), "API base `URL`")
+	fs.StringVar(&cfg.token, "token", os.Getenv("ANIMALRESCUE_TOKEN"), "bearer `token` for authentication")
+	fs.StringVar(&cfg.apiKey, "api-key", os.Getenv("ANIMALRESCUE_API_KEY"), "API `key` for authentication")
+	fs.StringVar(&cfg.output, "o", "table", "output `format`: table, json or yaml")
+	fs.DurationVar(&cfg.timeout, "timeout", 30*time.Second, "time limit for each request")
+	fs.BoolVar(&cfg.verbose, "v", false, "log requests to standard error")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return errUsage
+	}
+
+	out, err := newPrinter(cfg.output, stdout)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return errUsage
+	}
+
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errUsage
+	}
+	res, ok := resources[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown resource %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+
+	client, err := newClient(cfg, stderr)
+	if err != nil {
+		return err
+	}
+	return res.run(ctx, client, fs.Arg(1), fs.Args()[2:], out, stderr)
+}
+
+func newClient(cfg config, stderr io.Writer) (*animalrescue.Client, error) {
+	opts := []animalrescue.Option{
+		animalrescue.WithUserAgent("animalrescue-cli"),
+		animalrescue.WithClientTimeout(cfg.timeout),
+		animalrescue.WithRetryPolicy(animalrescue.DefaultRetryPolicy()),
+		animalrescue.WithWaitForRateLimit(),
+	}
+	if cfg.baseURL != "" {
+		opts = append(opts, animalrescue.WithBaseURL(cfg.baseURL))
+	}
+	if cfg.apiKey != "" {
+		tp := &animalrescue.APIKeyTransport{Key: cfg.apiKey}
+		opts = append(opts, animalrescue.WithHTTPClient(tp.Client()))
+	}
+	if cfg.verbose {
+		opts = append(opts, animalrescue.WithLogger(log.New(stderr, "", 0)))
+	}
+
+	client, err := animalrescue.NewClientWithOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.token != "" {
+		client = client.WithAuthToken(cfg.token)
+	}
+	return client, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func TestRun_golden(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	for _, a := range []animalrescue.NewAdoptee{
+		{Name: "Rex", Breed: "Border Collie", Gender: animalrescue.GenderMale, Age: animalrescue.AgePuppy},
+		{Name: "Sir Barks: the Third", Breed: "Pug #1", Gender: animalrescue.GenderFemale, Age: animalrescue.AgeSenior},
+		{Name: "  Spot", Breed: "first line\nsecond line", Age: animalrescue.AgeAdult},
+		{Name: "yes", Breed: "2020-05-01"},
+	} {
+		if _, _, err := srv.Client().Adoptees.CreateAdoptee(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	birthdate := animalrescue.Timestamp{Time: time.Date(1990, time.March, 4, 0, 0, 0, 0, time.UTC)}
+	for _, a := range []animalrescue.NewAdopter{
+		{FirstName: animalrescue.String("Jane"), Birthdate: &birthdate},
+		{FirstName: animalrescue.String("John")},
+	} {
+		if _, _, err := srv.Client().Adopters.CreateAdopter(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		golden string
+		args   []string
+	}{
+		{"list.table", []string{"-o", "table", "adoptees", "list"}},
+		{"list.json", []string{"-o", "json", "adoptees", "list"}},
+		{"list.yaml", []string{"-o", "yaml", "adoptees", "list"}},
+		{"get.table", []string{"adoptees", "get", "2"}},
+		{"get.yaml", []string{"-o", "yaml", "adoptees", "get", "2"}},
+		{"adopters.table", []string{"adopters", "list"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := append([]string{"-base-url", srv.URL + "/"}, tt.args...)
+			if err := run(ctx, args, &stdout, &stderr); err != nil {
+				t.Fatalf("run(%q) returned error: %v\n%s", tt.args, err, stderr.Bytes())
+			}
+
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				if err := ioutil.WriteFile(path, stdout.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(stdout.Bytes(), want) {
+				t.Errorf("run(%q) wrote:\n%s\nwant:\n%s", tt.args, stdout.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestRun_usage(t *testing.T) {
+	tests := [][]string{
+		{},
+		{"adoptees"},
+		{"kittens", "list"},
+		{"-o", "xml", "adoptees", "list"},
+		{"adoptions", "edit", "1"},
+		{"adoptees", "get"},
+	}
+
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if err := run(context.Background(), args, &stdout, &stderr); err != errUsage {
+			t.Errorf("run(%q) returned %v, want errUsage", args, err)
+		}
+		if stdout.Len() != 0 || stderr.Len() == 0 {
+			t.Errorf("run(%q) wrote %q to stdout and %q to stderr, want usage on stderr", args, stdout.Bytes(), stderr.Bytes())
+		}
+	}
+}
+
+func TestYAMLScalar(t *testing.T) {
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, `null`},
+		{true, `true`},
+		{"Rex", `Rex`},
+		{"Border Collie", `Border Collie`},
+		{"http://example.com/a#b", `http://example.com/a#b`},
+		{"", `""`},
+		{"  Spot", `"  Spot"`},
+		{"Spot ", `"Spot "`},
+		{"Sir Barks: the Third", `"Sir Barks: the Third"`},
+		{"Barks:", `"Barks:"`},
+		{"Pug #1", `"Pug #1"`},
+		{"#1", `"#1"`},
+		{"first\nsecond", `"first\nsecond"`},
+		{"tab\there", `"tab\there"`},
+		{"no\u00a0break", `"no\u00a0break"`},
+		{"yes", `"yes"`},
+		{"Off", `"Off"`},
+		{"~", `"~"`},
+		{"<<", `"<<"`},
+		{"42", `"42"`},
+		{"0x1F", `"0x1F"`},
+		{"1_000", `"1_000"`},
+		{".inf", `".inf"`},
+		{"-1", `"-1"`},
+		{"2020-05-01", `"2020-05-01"`},
+		{"- item", `"- item"`},
+		{"[a]", `"[a]"`},
+		{"'quoted'", `"'quoted'"`},
+		{`say "hi"`, `say "hi"`},
+		{"*alias", `"*alias"`},
+	}
+
+	for _, tt := range tests {
+		if got := yamlScalar(tt.in); got != tt.want {
+			t.Errorf("yamlScalar(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// A printer writes entities, or slices of them, in one output format.
+type printer interface {
+	print(v interface{}) error
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case "table":
+		return tablePrinter{w}, nil
+	case "json":
+		return jsonPrinter{w}, nil
+	case "yaml":
+		return yamlPrinter{w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q", format)
+}
+
+type jsonPrinter struct {
+	w io.Writer
+}
+
+func (p jsonPrinter) print(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// tablePrinter writes one row per entity and one column per field. Nested
+// entities are shown by ID and nested lists by their length.
+type tablePrinter struct {
+	w io.Writer
+}
+
+func (p tablePrinter) print(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		s := reflect.MakeSlice(reflect.SliceOf(rv.Type()), 1, 1)
+		s.Index(0).Set(rv)
+		rv = s
+	}
+
+	t := rv.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var (
+		names  []string
+		fields []int
+		dates  []bool
+	)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names = append(names, strings.ToUpper(name))
+			fields = append(fields, i)
+			dates = append(dates, dateColumns[name])
+		}
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(names, "\t"))
+	cells := make([]string, len(fields))
+	for n := 0; n < rv.Len(); n++ {
+		row := reflect.Indirect(rv.Index(n))
+		if !row.IsValid() {
+			continue
+		}
+		for i, f := range fields {
+			if dates[i] {
+				cells[i] = dateCell(row.Field(f))
+				continue
+			}
+			cells[i] = cellEscaper.Replace(cell(row.Field(f)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// dateColumns are the fields holding a date alone, which are shown as
+// "2006-01-02" like the API encodes them.
+var dateColumns = map[string]bool{"birthdate": true}
+
+// dateCell returns the cell of v, the value of one of dateColumns.
+func dateCell(v reflect.Value) string {
+	t, ok := v.Interface().(*animalrescue.Timestamp)
+	if !ok {
+		return cellEscaper.Replace(cell(v))
+	}
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02")
+}
+
+// cellEscaper escapes the characters that would break the rows or columns
+// of a table.
+var cellEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+func cell(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "-"
+		}
+		if s, ok := v.Interface().(fmt.Stringer); ok && v.Elem().Kind() != reflect.Struct {
+			return s.String()
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice:
+		return strconv.Itoa(v.Len())
+	case reflect.Struct:
+		if id := v.FieldByName("ID"); id.IsValid() {
+			return "#" + cell(id)
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if b, err := m.MarshalText(); err == nil {
+				return string(b)
+			}
+		}
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			return s.String()
+		}
+	case reflect.String:
+		if v.Len() == 0 {
+			return "-"
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// yamlPrinter writes values as YAML, by way of their JSON encoding.
+type yamlPrinter struct {
+	w io.Writer
+}
+
+func (p yamlPrinter) print(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, doc, 0)
+	_, err = p.w.Write(buf.Bytes())
+	return err
+}
+
+// writeYAML writes the JSON value v as a YAML block at the given
+// indentation level.
+func writeYAML(buf *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			buf.WriteString(pad + yamlScalar(k) + ":")
+			writeYAMLValue(buf, v[k], indent)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			buf.WriteString(pad + "-")
+			writeYAMLValue(buf, item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue writes v after a "key:" or "-" already written at the
+// given indentation level.
+func writeYAMLValue(buf *bytes.Buffer, v interface{}, indent int) {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		if len(vv) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+	case []interface{}:
+		if len(vv) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+		return
+	}
+	buf.WriteString("\n")
+	writeYAML(buf, v, indent+1)
+}
+
+func yamlScalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		if yamlPlain(v) {
+			return v
+		}
+		return strconv.Quote(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// yamlPlain reports whether s can be written as a plain YAML scalar without
+// being mistaken for another type or structure. Strings starting with a
+// digit, a sign or a dot are quoted, since YAML 1.1 reads many of them as
+// numbers or timestamps.
+func yamlPlain(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n", "<<":
+		return false
+	}
+	if strings.ContainsAny(s[:1], "0123456789+.-?:,[]{}#&*!|>'\"%@`") {
+		return false
+	}
+	if strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	for _, r := range s {
+		if r != ' ' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
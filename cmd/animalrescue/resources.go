@@ -0,0 +1,444 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// A resource binds the actions of the command line to one of the client's
+// services. Values passed to and returned from the functions are pointers to
+// the service's entity types, or slices of them.
+type resource struct {
+	name string
+
+	list   func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, *animalrescue.Response, error)
+	all    func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, error)
+	get    func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error)
+	create func(ctx context.Context, c *animalrescue.Client, v interface{}) (interface{}, error)
+	edit   func(ctx context.Context, c *animalrescue.Client, id int64, v interface{}) (interface{}, error)
+	delete func(ctx context.Context, c *animalrescue.Client, id int64) error
+
+	// listOptions returns a pointer to a new value of the options type
+	// accepted by list and all.
+	listOptions func() interface{}
+
+	// input returns a pointer to a new value of the type accepted by create
+	// and edit, and binds its fields to flags of fs.
+	input func(fs *flag.FlagSet) (v interface{}, apply func() error)
+}
+
+var resources = map[string]*resource{
+	"adopters": {
+		name: "adopters",
+		list: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, *animalrescue.Response, error) {
+			return c.Adopters.ListAll(ctx, opts.(*animalrescue.AdopterListOptions))
+		},
+		all: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, error) {
+			var all []*animalrescue.Adopter
+			it := c.Adopters.Iter(ctx, opts.(*animalrescue.AdopterListOptions)).WithPrefetch()
+			for it.Next() {
+				all = append(all, it.Value())
+			}
+			return all, it.Err()
+		},
+		listOptions: func() interface{} { return new(animalrescue.AdopterListOptions) },
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adopters.GetAdopterByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, v interface{}) (interface{}, error) {
+			a, _, err := c.Adopters.CreateAdopter(ctx, *v.(*animalrescue.NewAdopter))
+			return a, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, v interface{}) (interface{}, error) {
+			a, _, err := c.Adopters.EditAdopterByID(ctx, id, *v.(*animalrescue.NewAdopter))
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adopters.DeleteAdopterByID(ctx, id)
+			return err
+		},
+		input: func(fs *flag.FlagSet) (interface{}, func() error) {
+			v := new(animalrescue.NewAdopter)
+			return v, bindFields(fs, v, "json")
+		},
+	},
+	"adoptees": {
+		name: "adoptees",
+		list: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, *animalrescue.Response, error) {
+			return c.Adoptees.ListAll(ctx, opts.(*animalrescue.AdopteeListOptions))
+		},
+		all: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, error) {
+			var all []*animalrescue.Adoptee
+			it := c.Adoptees.Iter(ctx, opts.(*animalrescue.AdopteeListOptions)).WithPrefetch()
+			for it.Next() {
+				all = append(all, it.Value())
+			}
+			return all, it.Err()
+		},
+		listOptions: func() interface{} { return new(animalrescue.AdopteeListOptions) },
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adoptees.GetAdopteeByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, v interface{}) (interface{}, error) {
+			a, _, err := c.Adoptees.CreateAdoptee(ctx, *v.(*animalrescue.NewAdoptee))
+			return a, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, v interface{}) (interface{}, error) {
+			a, _, err := c.Adoptees.EditAdopteeByID(ctx, id, *v.(*animalrescue.NewAdoptee))
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adoptees.DeleteAdopteeByID(ctx, id)
+			return err
+		},
+		input: func(fs *flag.FlagSet) (interface{}, func() error) {
+			v := new(animalrescue.NewAdoptee)
+			return v, bindFields(fs, v, "json")
+		},
+	},
+	"adoptions": {
+		name: "adoptions",
+		list: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, *animalrescue.Response, error) {
+			return c.Adoptions.ListAll(ctx, opts.(*animalrescue.AdoptionListOptions))
+		},
+		all: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, error) {
+			var all []*animalrescue.Adoption
+			it := c.Adoptions.Iter(ctx, opts.(*animalrescue.AdoptionListOptions)).WithPrefetch()
+			for it.Next() {
+				all = append(all, it.Value())
+			}
+			return all, it.Err()
+		},
+		listOptions: func() interface{} { return new(animalrescue.AdoptionListOptions) },
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adoptions.GetAdoptionByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, v interface{}) (interface{}, error) {
+			a, _, err := c.Adoptions.CreateAdoption(ctx, *v.(*animalrescue.NewAdoption))
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adoptions.DeleteAdoptionByID(ctx, id)
+			return err
+		},
+		input: func(fs *flag.FlagSet) (interface{}, func() error) {
+			v := new(animalrescue.NewAdoption)
+			adopterID := fs.Int64("adopter-id", 0, "ID of the adopter")
+			adopteeID := fs.Int("adoptee-id", 0, "ID of the adoptee")
+			createdAt := fs.String("created-at", "", "adoption date, as an RFC 3339 time or YYYY-MM-DD (default now)")
+			return v, func() error {
+				set := visited(fs)
+				if set["adopter-id"] {
+					v.Adopter = &animalrescue.Adopter{ID: adopterID}
+				}
+				if set["adoptee-id"] {
+					v.Adoptee = &animalrescue.Adoptee{ID: *adopteeID}
+				}
+				if set["created-at"] {
+					v.CreatedAt = new(animalrescue.Timestamp)
+					if err := v.CreatedAt.UnmarshalText([]byte(*createdAt)); err != nil {
+						return fmt.Errorf("invalid -created-at: %v", err)
+					}
+				}
+				return nil
+			}
+		},
+	},
+	"petprefs": {
+		name: "petprefs",
+		list: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, *animalrescue.Response, error) {
+			return c.PetPreferences.ListAll(ctx, opts.(*animalrescue.ListOptions))
+		},
+		all: func(ctx context.Context, c *animalrescue.Client, opts interface{}) (interface{}, error) {
+			var all []*animalrescue.PetPreference
+			it := c.PetPreferences.Iter(ctx, opts.(*animalrescue.ListOptions)).WithPrefetch()
+			for it.Next() {
+				all = append(all, it.Value())
+			}
+			return all, it.Err()
+		},
+		listOptions: func() interface{} { return new(animalrescue.ListOptions) },
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			pp, _, err := c.PetPreferences.GetPetPreferenceByID(ctx, id)
+			return pp, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, v interface{}) (interface{}, error) {
+			pp, _, err := c.PetPreferences.CreatePetPreference(ctx, *v.(*animalrescue.NewPetPreference))
+			return pp, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, v interface{}) (interface{}, error) {
+			pp, _, err := c.PetPreferences.EditPetPreferenceByID(ctx, id, *v.(*animalrescue.NewPetPreference))
+			return pp, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.PetPreferences.DeletePetPreferenceByID(ctx, id)
+			return err
+		},
+		input: func(fs *flag.FlagSet) (interface{}, func() error) {
+			v := new(animalrescue.NewPetPreference)
+			return v, bindFields(fs, v, "json")
+		},
+	},
+}
+
+// run performs action on the resource, with args holding the action's flags
+// and arguments.
+func (r *resource) run(ctx context.Context, c *animalrescue.Client, action string, args []string, out printer, stderr io.Writer) error {
+	fs := flag.NewFlagSet(r.name+" "+action, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	switch action {
+	case "list":
+		opts := r.listOptions()
+		apply := bindFields(fs, opts, "url")
+		all := fs.Bool("all", false, "retrieve every page of results")
+		if err := parseFlags(fs, args, 0); err != nil {
+			return err
+		}
+		if err := apply(); err != nil {
+			return err
+		}
+		if *all {
+			v, err := r.all(ctx, c, opts)
+			if err != nil {
+				return err
+			}
+			return out.print(v)
+		}
+		v, resp, err := r.list(ctx, c, opts)
+		if err != nil {
+			return err
+		}
+		if err := out.print(v); err != nil {
+			return err
+		}
+		if resp.NextPage != 0 {
+			fmt.Fprintf(stderr, "more results: -page %d\n", resp.NextPage)
+		}
+		return nil
+
+	case "get":
+		if err := parseFlags(fs, args, 1); err != nil {
+			return err
+		}
+		id, err := parseID(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		v, err := r.get(ctx, c, id)
+		if err != nil {
+			return err
+		}
+		return out.print(v)
+
+	case "create", "edit":
+		if action == "edit" && r.edit == nil {
+			break
+		}
+		file := fs.String("file", "", "read the entity from a JSON `file` (\"-\" for standard input)")
+		v, apply := r.input(fs)
+		nargs := 0
+		if action == "edit" {
+			nargs = 1
+		}
+		if err := parseFlags(fs, args, nargs); err != nil {
+			return err
+		}
+		if *file != "" {
+			if err := readJSON(*file, v); err != nil {
+				return err
+			}
+		}
+		if err := apply(); err != nil {
+			return err
+		}
+
+		var res interface{}
+		var err error
+		if action == "create" {
+			res, err = r.create(ctx, c, v)
+		} else {
+			var id int64
+			if id, err = parseID(fs.Arg(0)); err != nil {
+				return err
+			}
+			res, err = r.edit(ctx, c, id, v)
+		}
+		if err != nil {
+			return err
+		}
+		return out.print(res)
+
+	case "delete":
+		if err := parseFlags(fs, args, 1); err != nil {
+			return err
+		}
+		id, err := parseID(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return r.delete(ctx, c, id)
+	}
+
+	fmt.Fprintf(stderr, "unknown action %q for %v\n", action, r.name)
+	return errUsage
+}
+
+// parseFlags parses args into fs and checks that exactly nargs positional
+// arguments remain.
+func parseFlags(fs *flag.FlagSet, args []string, nargs int) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != nargs {
+		fmt.Fprintf(fs.Output(), "%v: expected %d arguments, got %d\n", fs.Name(), nargs, fs.NArg())
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid ID %q", s)
+	}
+	return id, nil
+}
+
+func readJSON(file string, v interface{}) error {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decoding %v: %v", file, err)
+	}
+	return nil
+}
+
+// bindFields defines a flag for every string, integer or
+// encoding.TextUnmarshaler field of the struct pointed to by v, including
+// those of embedded structs. Flags are named after the field's tag, such as
+// "json" or "url", with dashes for underscores. The returned function
+// copies the flags that were set into v.
+func bindFields(fs *flag.FlagSet, v interface{}, tag string) func() error {
+	rv := reflect.ValueOf(v).Elem()
+
+	values := make(map[string]*string)
+	fields := make(map[string][]int)
+	var bind func(t reflect.Type, index []int)
+	bind = func(t reflect.Type, index []int) {
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			fi := append(append([]int(nil), index...), i)
+			name := strings.Split(sf.Tag.Get(tag), ",")[0]
+			if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+				bind(sf.Type, fi)
+				continue
+			}
+			if name == "" || name == "-" {
+				continue
+			}
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			switch {
+			case reflect.PtrTo(ft).Implements(textUnmarshalerType):
+			case ft.Kind() == reflect.String, ft.Kind() == reflect.Int, ft.Kind() == reflect.Int64:
+			default:
+				continue
+			}
+
+			name = strings.Replace(name, "_", "-", -1)
+			usage, ok := flagUsage[name]
+			if !ok && tag == "url" {
+				usage = "only list entities with this " + strings.Replace(name, "-", " ", -1)
+			} else if !ok {
+				usage = "set the " + strings.Replace(name, "-", " ", -1) + " field"
+			}
+			values[name] = fs.String(name, "", usage)
+			fields[name] = fi
+		}
+	}
+	bind(rv.Type(), nil)
+
+	return func() error {
+		for name := range visited(fs) {
+			index, ok := fields[name]
+			if !ok {
+				continue
+			}
+			fv := rv.FieldByIndex(index)
+			if fv.Kind() == reflect.Ptr {
+				fv.Set(reflect.New(fv.Type().Elem()))
+				fv = fv.Elem()
+			}
+			s := *values[name]
+			if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+				if err := u.UnmarshalText([]byte(s)); err != nil {
+					return fmt.Errorf("invalid -%v: %v", name, err)
+				}
+				continue
+			}
+			switch fv.Kind() {
+			case reflect.String:
+				fv.SetString(s)
+			default:
+				n, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid -%v: %q is not a whole number", name, s)
+				}
+				fv.SetInt(n)
+			}
+		}
+		return nil
+	}
+}
+
+// flagUsage holds the usage of flags defined by bindFields whose meaning is
+// not evident from their name.
+var flagUsage = map[string]string{
+	"page":           "page of results to retrieve",
+	"per-page":       "number of results per page",
+	"cursor":         "cursor of the page of results to retrieve",
+	"sort":           "field to sort the results by",
+	"direction":      "direction to sort the results in: asc or desc",
+	"created-after":  "only list adoptions created at or after this RFC 3339 time",
+	"created-before": "only list adoptions created before this RFC 3339 time",
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// visited returns the names of the flags of fs that were set.
+func visited(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
@@ -0,0 +1,205 @@
+// Package csvio converts Animal Rescue API entities to and from CSV.
+//
+// Columns are named after the JSON fields of the entity types, such as
+// "first_name" or "zip_code", and can be renamed through Options.Header to
+// match an existing spreadsheet:
+//
+//	opts := &csvio.Options{Header: csvio.Mapping{"first_name": "First Name"}}
+//	adopters, _, err := client.Adopters.ListAll(ctx, nil)
+//	err = csvio.WriteAdopters(w, adopters, opts)
+//
+// Nested values, such as an adopter's pet preferences, are not represented.
+package csvio
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Mapping maps field names to the CSV header names used for them. Fields
+// without an entry use their field name as header.
+type Mapping map[string]string
+
+// Options specifies the optional parameters to the functions in this
+// package.
+type Options struct {
+	// Columns lists the fields to write, in order. Default: every field of
+	// the entity that can be represented in CSV.
+	Columns []string
+
+	// Header renames the CSV columns of fields.
+	Header Mapping
+
+	// Comma is the field delimiter. Default: ','.
+	Comma rune
+
+	// Batch controls how the Import functions send rows to the API.
+	Batch animalrescue.BatchOptions
+}
+
+func (o *Options) header(field string) string {
+	if o != nil {
+		if h, ok := o.Header[field]; ok {
+			return h
+		}
+	}
+	return field
+}
+
+func (o *Options) comma() rune {
+	if o != nil && o.Comma != 0 {
+		return o.Comma
+	}
+	return ','
+}
+
+// field is a struct field that is represented by a CSV column.
+type field struct {
+	name  string // JSON field name
+	index int
+	date  bool // whether the field holds a date alone
+}
+
+// dateFields are the fields holding a date alone, which are written as
+// "2006-01-02" like the API encodes them.
+var dateFields = map[string]bool{"birthdate": true}
+
+// dateLayout is the format of the cells of dateFields.
+const dateLayout = "2006-01-02"
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// fieldsOf returns the fields of struct type t that can be represented in
+// CSV, in declaration order: strings, integers, and values implementing
+// encoding.TextUnmarshaler, or pointers to them.
+func fieldsOf(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || sf.PkgPath != "" {
+			continue
+		}
+		if isScalar(sf.Type) {
+			fields = append(fields, field{name: name, index: i, date: dateFields[name]})
+		}
+	}
+	return fields
+}
+
+func isScalar(t reflect.Type) bool {
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) || t.Implements(textUnmarshalerType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// selectFields returns the fields of t named by columns, or every field if
+// columns is empty.
+func selectFields(t reflect.Type, columns []string) ([]field, error) {
+	all := fieldsOf(t)
+	if len(columns) == 0 {
+		return all, nil
+	}
+
+	byName := make(map[string]field, len(all))
+	for _, f := range all {
+		byName[f.name] = f
+	}
+	fields := make([]field, len(columns))
+	for i, c := range columns {
+		f, ok := byName[c]
+		if !ok {
+			return nil, fmt.Errorf("csvio: unknown column %q for %v", c, t.Name())
+		}
+		fields[i] = f
+	}
+	return fields, nil
+}
+
+// format returns the CSV representation of v, the value of f.
+func (f field) format(v reflect.Value) (string, error) {
+	if t, ok := v.Interface().(*animalrescue.Timestamp); ok && f.date {
+		if t == nil || t.IsZero() {
+			return "", nil
+		}
+		return t.Format(dateLayout), nil
+	}
+	return formatValue(v)
+}
+
+// formatValue returns the CSV representation of v. Nil pointers are written
+// as empty cells.
+func formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+		v = v.Elem()
+	}
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			return "", nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported type %v", v.Type())
+}
+
+// parseValue sets v from its CSV representation s. Empty cells leave v
+// unchanged.
+func parseValue(v reflect.Value, s string) error {
+	if s == "" {
+		return nil
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+		v = v.Elem()
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", s)
+		}
+		v.SetInt(n)
+		return nil
+	}
+	return fmt.Errorf("unsupported type %v", v.Type())
+}
@@ -0,0 +1,101 @@
+package csvio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// WriteAdopters writes adopters to w as CSV, preceded by a header row.
+func WriteAdopters(w io.Writer, adopters []*animalrescue.Adopter, opts *Options) error {
+	return writeRecords(w, reflect.ValueOf(adopters), opts)
+}
+
+// WriteAdoptees writes adoptees to w as CSV, preceded by a header row.
+func WriteAdoptees(w io.Writer, adoptees []*animalrescue.Adoptee, opts *Options) error {
+	return writeRecords(w, reflect.ValueOf(adoptees), opts)
+}
+
+// WritePetPreferences writes pp to w as CSV, preceded by a header row.
+func WritePetPreferences(w io.Writer, pp []*animalrescue.PetPreference, opts *Options) error {
+	return writeRecords(w, reflect.ValueOf(pp), opts)
+}
+
+// adoptionRow is the flattened CSV representation of an Adoption, which
+// references its adopter and adoptee by ID.
+type adoptionRow struct {
+	ID          int                     `json:"id"`
+	AdopterID   *int64                  `json:"adopter_id"`
+	AdopteeID   int                     `json:"adoptee_id"`
+	AdopteeName string                  `json:"adoptee_name"`
+	CreatedAt   *animalrescue.Timestamp `json:"created_at"`
+}
+
+// WriteAdoptions writes adoptions to w as CSV, preceded by a header row.
+// The adopter and adoptee of each adoption are written as adopter_id,
+// adoptee_id and adoptee_name columns.
+func WriteAdoptions(w io.Writer, adoptions []*animalrescue.Adoption, opts *Options) error {
+	rows := make([]*adoptionRow, 0, len(adoptions))
+	for _, a := range adoptions {
+		if a == nil {
+			continue
+		}
+		row := &adoptionRow{ID: a.ID, CreatedAt: a.CreatedAt}
+		if a.Adopter != nil {
+			row.AdopterID = a.Adopter.ID
+		}
+		if a.Adoptee != nil {
+			row.AdopteeID = a.Adoptee.ID
+			row.AdopteeName = a.Adoptee.Name
+		}
+		rows = append(rows, row)
+	}
+	return writeRecords(w, reflect.ValueOf(rows), opts)
+}
+
+// writeRecords writes the slice of struct pointers rows as CSV.
+func writeRecords(w io.Writer, rows reflect.Value, opts *Options) error {
+	var columns []string
+	if opts != nil {
+		columns = opts.Columns
+	}
+	fields, err := selectFields(rows.Type().Elem().Elem(), columns)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = opts.comma()
+
+	record := make([]string, len(fields))
+	for i, f := range fields {
+		record[i] = opts.header(f.name)
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+
+	for n := 0; n < rows.Len(); n++ {
+		row := rows.Index(n)
+		if row.IsNil() {
+			continue
+		}
+		row = row.Elem()
+		for i, f := range fields {
+			s, err := f.format(row.Field(f.index))
+			if err != nil {
+				return fmt.Errorf("csvio: row %d, column %q: %v", n+1, f.name, err)
+			}
+			record[i] = s
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
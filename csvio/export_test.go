@@ -0,0 +1,64 @@
+package csvio_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/csvio"
+)
+
+func TestWriteAdopters_dates(t *testing.T) {
+	birthdate := time.Date(1985, 7, 4, 0, 0, 0, 0, time.UTC)
+	adopters := []*animalrescue.Adopter{
+		{
+			ID:        animalrescue.Int64(1),
+			FirstName: animalrescue.String("Jane"),
+			Birthdate: &animalrescue.Timestamp{Time: birthdate},
+		},
+		{ID: animalrescue.Int64(2), FirstName: animalrescue.String("John")},
+		{ID: animalrescue.Int64(3), FirstName: animalrescue.String("Ann"), Birthdate: &animalrescue.Timestamp{}},
+	}
+
+	var buf bytes.Buffer
+	opts := &csvio.Options{Columns: []string{"id", "first_name", "birthdate"}}
+	if err := csvio.WriteAdopters(&buf, adopters, opts); err != nil {
+		t.Fatal(err)
+	}
+	// Birthdates are written as dates alone, like the API encodes them.
+	want := "id,first_name,birthdate\n" +
+		"1,Jane,1985-07-04\n" +
+		"2,John,\n" +
+		"3,Ann,\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteAdopters wrote\n%s\nwant\n%s", got, want)
+	}
+
+	// The written birthdates read back unchanged.
+	read, err := csvio.ReadAdopters(&buf, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 3 || read[0].Birthdate == nil || !read[0].Birthdate.Equal(animalrescue.Timestamp{Time: birthdate}) {
+		t.Fatalf("ReadAdopters returned %+v, want Jane born on 1985-07-04", read)
+	}
+	if read[1].Birthdate != nil || read[2].Birthdate != nil {
+		t.Errorf("ReadAdopters returned birthdates %v and %v for empty cells, want nil", read[1].Birthdate, read[2].Birthdate)
+	}
+}
+
+func TestWriteAdoptions_times(t *testing.T) {
+	created := time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)
+	adoptions := []*animalrescue.Adoption{{ID: 1, CreatedAt: &animalrescue.Timestamp{Time: created}}}
+
+	var buf bytes.Buffer
+	opts := &csvio.Options{Columns: []string{"id", "created_at"}}
+	if err := csvio.WriteAdoptions(&buf, adoptions, opts); err != nil {
+		t.Fatal(err)
+	}
+	// Times other than dates keep their time of day.
+	if want := "id,created_at\n1,2020-05-01T12:30:00Z\n"; buf.String() != want {
+		t.Errorf("WriteAdoptions wrote\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,460 @@
+package csvio
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// A RowError reports a problem with a single row of a CSV input. Err is an
+// *animalrescue.Error for cells that could not be parsed or required cells
+// that are empty, an *animalrescue.ValidationError for rows rejected by the
+// Validate method of the entity, or the error returned by the API for rows
+// that failed to import.
+type RowError struct {
+	Line int // line of the row in the input; the header is line 1
+	Err  error
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// RowErrors lists the rows of a CSV input that could not be read or
+// imported.
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, re := range e {
+		msgs[i] = re.Error()
+	}
+	return fmt.Sprintf("csvio: %d invalid rows: %v", len(e), strings.Join(msgs, "; "))
+}
+
+// ReadAdopters reads the adopters in the CSV input r, whose first row must
+// be a header. Rows that fail validation are left out of the result and
+// reported in a RowErrors error, returned alongside the valid rows.
+func ReadAdopters(r io.Reader, opts *Options) ([]animalrescue.NewAdopter, error) {
+	_, rows, err := readRows(r, adopterSchema, opts)
+	if err != nil {
+		return nil, err
+	}
+	var adopters []animalrescue.NewAdopter
+	for _, row := range rows {
+		if row.err == nil {
+			adopters = append(adopters, *row.value.Interface().(*animalrescue.NewAdopter))
+		}
+	}
+	return adopters, rowErrors(rows)
+}
+
+// ReadAdoptees reads the adoptees in the CSV input r, whose first row must
+// be a header. Rows that fail validation are left out of the result and
+// reported in a RowErrors error, returned alongside the valid rows.
+func ReadAdoptees(r io.Reader, opts *Options) ([]animalrescue.NewAdoptee, error) {
+	_, rows, err := readRows(r, adopteeSchema, opts)
+	if err != nil {
+		return nil, err
+	}
+	var adoptees []animalrescue.NewAdoptee
+	for _, row := range rows {
+		if row.err == nil {
+			adoptees = append(adoptees, *row.value.Interface().(*animalrescue.NewAdoptee))
+		}
+	}
+	return adoptees, rowErrors(rows)
+}
+
+// ReadPetPreferences reads the pet preferences in the CSV input r, whose
+// first row must be a header. Rows that fail validation are left out of the
+// result and reported in a RowErrors error, returned alongside the valid
+// rows.
+func ReadPetPreferences(r io.Reader, opts *Options) ([]animalrescue.NewPetPreference, error) {
+	_, rows, err := readRows(r, petPreferenceSchema, opts)
+	if err != nil {
+		return nil, err
+	}
+	var pp []animalrescue.NewPetPreference
+	for _, row := range rows {
+		if row.err == nil {
+			pp = append(pp, *row.value.Interface().(*animalrescue.NewPetPreference))
+		}
+	}
+	return pp, rowErrors(rows)
+}
+
+// ImportAdopters reads adopters from the CSV input r like ReadAdopters,
+// creates each valid row through client.Adopters.CreateMany, and writes a
+// results CSV to results: every input row followed by the ID assigned to
+// it, or the reason it was not imported. Rows that were not imported are
+// also reported in a RowErrors error.
+func ImportAdopters(ctx context.Context, client *animalrescue.Client, r io.Reader, results io.Writer, opts *Options) error {
+	return importRows(r, results, adopterSchema, opts, func(values []reflect.Value) []createResult {
+		adopters := make([]animalrescue.NewAdopter, len(values))
+		for i, v := range values {
+			adopters[i] = *v.Interface().(*animalrescue.NewAdopter)
+		}
+		out := make([]createResult, len(values))
+		for i, res := range client.Adopters.CreateMany(ctx, adopters, batchOptions(opts)) {
+			out[i].err = res.Err
+			if res.Adopter != nil && res.Adopter.ID != nil {
+				out[i].id = *res.Adopter.ID
+			}
+		}
+		return out
+	})
+}
+
+// ImportAdoptees reads adoptees from the CSV input r like ReadAdoptees,
+// creates each valid row through client.Adoptees.CreateMany, and writes a
+// results CSV to results: every input row followed by the ID assigned to
+// it, or the reason it was not imported. Rows that were not imported are
+// also reported in a RowErrors error.
+func ImportAdoptees(ctx context.Context, client *animalrescue.Client, r io.Reader, results io.Writer, opts *Options) error {
+	return importRows(r, results, adopteeSchema, opts, func(values []reflect.Value) []createResult {
+		adoptees := make([]animalrescue.NewAdoptee, len(values))
+		for i, v := range values {
+			adoptees[i] = *v.Interface().(*animalrescue.NewAdoptee)
+		}
+		out := make([]createResult, len(values))
+		for i, res := range client.Adoptees.CreateMany(ctx, adoptees, batchOptions(opts)) {
+			out[i].err = res.Err
+			if res.Adoptee != nil {
+				out[i].id = int64(res.Adoptee.ID)
+			}
+		}
+		return out
+	})
+}
+
+// ImportPetPreferences reads pet preferences from the CSV input r like
+// ReadPetPreferences, creates each valid row through
+// client.PetPreferences.CreateMany, and writes a results CSV to results:
+// every input row followed by the ID assigned to it, or the reason it was
+// not imported. Rows that were not imported are also reported in a
+// RowErrors error.
+func ImportPetPreferences(ctx context.Context, client *animalrescue.Client, r io.Reader, results io.Writer, opts *Options) error {
+	return importRows(r, results, petPreferenceSchema, opts, func(values []reflect.Value) []createResult {
+		pp := make([]animalrescue.NewPetPreference, len(values))
+		for i, v := range values {
+			pp[i] = *v.Interface().(*animalrescue.NewPetPreference)
+		}
+		out := make([]createResult, len(values))
+		for i, res := range client.PetPreferences.CreateMany(ctx, pp, batchOptions(opts)) {
+			out[i].err = res.Err
+			if res.PetPreference != nil {
+				out[i].id = int64(res.PetPreference.ID)
+			}
+		}
+		return out
+	})
+}
+
+// schema describes how rows are read into one of the New* types.
+type schema struct {
+	typ      reflect.Type
+	resource string   // resource name reported in validation errors
+	required []string // fields that must not be empty
+}
+
+var (
+	adopterSchema = schema{
+		typ:      reflect.TypeOf(animalrescue.NewAdopter{}),
+		resource: "Adopter",
+		required: []string{"first_name"},
+	}
+	adopteeSchema = schema{
+		typ:      reflect.TypeOf(animalrescue.NewAdoptee{}),
+		resource: "Adoptee",
+		required: []string{"name"},
+	}
+	petPreferenceSchema = schema{
+		typ:      reflect.TypeOf(animalrescue.NewPetPreference{}),
+		resource: "PetPreference",
+	}
+)
+
+// row is a single data row of a CSV input.
+type row struct {
+	line   int
+	record []string
+	value  reflect.Value // pointer to a value of the schema's type
+	err    *RowError
+}
+
+// readRows reads every row of the CSV input r into values of s.typ. The
+// header is returned for use in results files.
+func readRows(r io.Reader, s schema, opts *Options) ([]string, []*row, error) {
+	lr := &lineReader{r: bufio.NewReader(r)}
+	cr := csv.NewReader(lr)
+	cr.Comma = opts.comma()
+	cr.FieldsPerRecord = -1 // short rows are reported per row
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("csvio: missing header row")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	header = append([]string(nil), header...)
+
+	fields, err := headerFields(header, s.typ, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []*row
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			perr, ok := err.(*csv.ParseError)
+			if !ok {
+				return nil, nil, err
+			}
+			rows = append(rows, &row{line: perr.StartLine, record: record, err: &RowError{Line: perr.StartLine, Err: err}})
+			continue
+		}
+		if isBlank(record) {
+			continue
+		}
+		// The record ends on the last line read; quoted cells spanning
+		// several lines keep their line breaks, normalized to "\n".
+		line := lr.line
+		for _, cell := range record {
+			line -= strings.Count(cell, "\n")
+		}
+		rows = append(rows, parseRow(line, record, fields, s))
+	}
+	return header, rows, nil
+}
+
+// lineReader counts the lines read from r. Each Read returns at most one
+// line, so that a csv.Reader reading from it, which reads a line at a time
+// from its own buffer, never reads past the end of the current record.
+type lineReader struct {
+	r    *bufio.Reader
+	line int  // number of lines started
+	mid  bool // whether the last byte read was within a line
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		c, err := lr.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if !lr.mid {
+			lr.line++
+			lr.mid = true
+		}
+		p[n] = c
+		n++
+		if c == '\n' {
+			lr.mid = false
+			break
+		}
+	}
+	return n, nil
+}
+
+// headerFields resolves each column of header to a field of t, using the
+// header mapping of opts. Columns that do not map to a field are an error,
+// except for "id", which is ignored so that exported files can be
+// re-imported.
+func headerFields(header []string, t reflect.Type, opts *Options) ([]*field, error) {
+	byHeader := make(map[string]field)
+	for _, f := range fieldsOf(t) {
+		byHeader[strings.ToLower(f.name)] = f
+		byHeader[strings.ToLower(opts.header(f.name))] = f
+	}
+
+	fields := make([]*field, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if f, ok := byHeader[key]; ok {
+			f := f
+			fields[i] = &f
+			continue
+		}
+		if key != "id" && key != strings.ToLower(opts.header("id")) {
+			return nil, fmt.Errorf("csvio: unknown column %q for %v", h, t.Name())
+		}
+	}
+	return fields, nil
+}
+
+func parseRow(line int, record []string, fields []*field, s schema) *row {
+	rw := &row{line: line, record: record, value: reflect.New(s.typ)}
+	if len(record) != len(fields) {
+		rw.err = &RowError{Line: line, Err: fmt.Errorf("expected %d columns, found %d", len(fields), len(record))}
+		return rw
+	}
+
+	set := make(map[string]bool)
+	for i, f := range fields {
+		if f == nil {
+			continue
+		}
+		cell := strings.TrimSpace(record[i])
+		if err := parseValue(rw.value.Elem().Field(f.index), cell); err != nil {
+			rw.err = &RowError{Line: line, Err: &animalrescue.Error{
+				Resource: s.resource,
+				Field:    f.name,
+				Code:     animalrescue.CodeInvalid,
+				Message:  err.Error(),
+			}}
+			return rw
+		}
+		if cell != "" {
+			set[f.name] = true
+		}
+	}
+
+	for _, name := range s.required {
+		if !set[name] {
+			rw.err = &RowError{Line: line, Err: &animalrescue.Error{
+				Resource: s.resource,
+				Field:    name,
+				Code:     animalrescue.CodeMissingField,
+			}}
+			return rw
+		}
+	}
+
+	if v, ok := rw.value.Interface().(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			rw.err = &RowError{Line: line, Err: err}
+		}
+	}
+	return rw
+}
+
+func isBlank(record []string) bool {
+	for _, cell := range record {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func rowErrors(rows []*row) error {
+	var errs RowErrors
+	for _, r := range rows {
+		if r.err != nil {
+			errs = append(errs, r.err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// createResult is the outcome of creating the entity read from one row.
+type createResult struct {
+	id  int64
+	err error
+}
+
+// importRows reads the rows of r, passes the valid ones to create, and
+// writes the outcome of every row to results.
+func importRows(r io.Reader, results io.Writer, s schema, opts *Options, create func([]reflect.Value) []createResult) error {
+	header, rows, err := readRows(r, s, opts)
+	if err != nil {
+		return err
+	}
+
+	var (
+		valid  []*row
+		values []reflect.Value
+	)
+	for _, row := range rows {
+		if row.err == nil {
+			valid = append(valid, row)
+			values = append(values, row.value)
+		}
+	}
+
+	ids := make(map[*row]int64)
+	if len(values) > 0 {
+		for i, res := range create(values) {
+			if res.err != nil {
+				valid[i].err = &RowError{Line: valid[i].line, Err: res.err}
+			} else {
+				ids[valid[i]] = res.id
+			}
+		}
+	}
+
+	// Echo the input, minus any id column, followed by the outcome.
+	keep := make([]int, 0, len(header))
+	for i, h := range header {
+		key := strings.ToLower(strings.TrimSpace(h))
+		if key != "id" && key != strings.ToLower(opts.header("id")) {
+			keep = append(keep, i)
+		}
+	}
+
+	cw := csv.NewWriter(results)
+	cw.Comma = opts.comma()
+	out := make([]string, 0, len(keep)+2)
+	for _, i := range keep {
+		out = append(out, header[i])
+	}
+	if err := cw.Write(append(out, opts.header("id"), "error")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		out = out[:0]
+		for _, i := range keep {
+			if i < len(row.record) {
+				out = append(out, row.record[i])
+			} else {
+				out = append(out, "")
+			}
+		}
+		if row.err != nil {
+			out = append(out, "", row.err.Err.Error())
+		} else {
+			out = append(out, strconv.FormatInt(ids[row], 10), "")
+		}
+		if err := cw.Write(out); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+
+	return rowErrors(rows)
+}
+
+func batchOptions(opts *Options) animalrescue.BatchOptions {
+	if opts == nil {
+		return animalrescue.BatchOptions{}
+	}
+	return opts.Batch
+}
@@ -0,0 +1,164 @@
+package csvio_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+	"github.com/anGie44/go-animal-rescue/csvio"
+)
+
+func TestImportAdopters(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		opts      *csvio.Options
+		created   []string // first names of the adopters created, in order
+		errLines  []int    // lines reported in RowErrors
+		errFields []string // field of the *animalrescue.Error of each row error, if any
+	}{
+		{
+			name:    "valid rows",
+			input:   "first_name,last_name,email\nJane,Doe,jane@example.com\nJohn,Roe,\n",
+			created: []string{"Jane", "John"},
+		},
+		{
+			name: "invalid rows",
+			input: "first_name,email,phone\n" +
+				"Jane,jane@example.com,\n" +
+				",john@example.com,\n" +
+				"Ann,not-an-email,\n" +
+				"Bob,,555-0100,extra\n",
+			created:   []string{"Jane"},
+			errLines:  []int{3, 4, 5},
+			errFields: []string{"first_name", "", ""},
+		},
+		{
+			name: "multi-line cells",
+			input: "first_name,address,email\n" +
+				"Jane,\"1 Main St\nApt 2\nAustin\",jane@example.com\n" +
+				"John,\"2 Elm St\r\nDallas\",bad\n" +
+				"Ann,3 Oak St,bad\n",
+			created:  []string{"Jane"},
+			errLines: []int{5, 7},
+		},
+		{
+			name: "blank lines",
+			input: "first_name,email\n" +
+				"\n" +
+				"Jane,jane@example.com\n" +
+				" , \n" +
+				"\n" +
+				"John,bad",
+			created:  []string{"Jane"},
+			errLines: []int{6},
+		},
+		{
+			name: "parse error",
+			input: "first_name,email\n" +
+				"Jane,jane@example.com\n" +
+				"Jo\"hn,john@example.com\n" +
+				"Ann,\"ann\n@example.com\n",
+			created:  []string{"Jane"},
+			errLines: []int{3, 4},
+		},
+		{
+			name:  "renamed columns",
+			input: "First Name;E-mail;id\nJane;jane@example.com;17\n",
+			opts: &csvio.Options{
+				Header: csvio.Mapping{"first_name": "First Name", "email": "E-mail"},
+				Comma:  ';',
+			},
+			created: []string{"Jane"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			ctx := context.Background()
+
+			var results bytes.Buffer
+			err := csvio.ImportAdopters(ctx, srv.Client(), strings.NewReader(tt.input), &results, tt.opts)
+
+			var lines []int
+			var rowErrs csvio.RowErrors
+			if errors.As(err, &rowErrs) {
+				for i, re := range rowErrs {
+					lines = append(lines, re.Line)
+					var aerr *animalrescue.Error
+					if i < len(tt.errFields) && tt.errFields[i] != "" && (!errors.As(re, &aerr) || aerr.Field != tt.errFields[i]) {
+						t.Errorf("row error %v does not concern field %v", re, tt.errFields[i])
+					}
+				}
+			} else if err != nil {
+				t.Fatalf("ImportAdopters returned error: %v", err)
+			}
+			if !reflect.DeepEqual(lines, tt.errLines) {
+				t.Errorf("ImportAdopters reported lines %v, want %v (%v)", lines, tt.errLines, err)
+			}
+
+			adopters, _, err := srv.Client().Adopters.ListAll(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Rows are created concurrently, in no particular order.
+			var created []string
+			for _, a := range adopters {
+				created = append(created, *a.FirstName)
+			}
+			sort.Strings(created)
+			if !reflect.DeepEqual(created, tt.created) {
+				t.Errorf("server holds adopters %v, want %v", created, tt.created)
+			}
+
+			// The results file has a row for every row read, each with an
+			// ID or an error.
+			cr := csv.NewReader(&results)
+			if tt.opts != nil {
+				cr.Comma = tt.opts.Comma
+			}
+			cr.FieldsPerRecord = -1
+			records, err := cr.ReadAll()
+			if err != nil {
+				t.Fatalf("results file is not valid CSV: %v", err)
+			}
+			if n := len(records) - 1; n != len(tt.created)+len(tt.errLines) {
+				t.Errorf("results file has %d rows, want %d", n, len(tt.created)+len(tt.errLines))
+			}
+			for _, rec := range records[1:] {
+				id, msg := rec[len(rec)-2], rec[len(rec)-1]
+				if (id == "") == (msg == "") {
+					t.Errorf("results row %q has ID %q and error %q, want exactly one", rec, id, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestImportAdopters_multiLineCell(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	input := "first_name,address\nJane,\"1 Main St\r\nAustin\"\n"
+	if err := csvio.ImportAdopters(ctx, srv.Client(), strings.NewReader(input), new(bytes.Buffer), nil); err != nil {
+		t.Fatal(err)
+	}
+	adopter, _, err := srv.Client().Adopters.GetAdopterByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1 Main St\nAustin"
+	if adopter.Address == nil || *adopter.Address != want {
+		t.Errorf("adopter address is %v, want %q", adopter, want)
+	}
+}
@@ -0,0 +1,178 @@
+package animalrescue
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Gender is the gender of an adoptee or adopter, or the gender preferred by
+// a pet preference. Values read from JSON are normalized, so that "M",
+// "Male" and "male" all decode to GenderMale. Values the client does not
+// know are kept as they were received.
+type Gender string
+
+// Known genders.
+const (
+	GenderMale    Gender = "male"
+	GenderFemale  Gender = "female"
+	GenderUnknown Gender = "unknown"
+
+	// GenderAny states that a pet preference accepts any gender.
+	GenderAny Gender = "any"
+)
+
+// genderAliases maps normalized spellings onto the known genders.
+var genderAliases = map[string]Gender{
+	"male":    GenderMale,
+	"m":       GenderMale,
+	"boy":     GenderMale,
+	"female":  GenderFemale,
+	"f":       GenderFemale,
+	"girl":    GenderFemale,
+	"unknown": GenderUnknown,
+	"u":       GenderUnknown,
+	"any":     GenderAny,
+	"either":  GenderAny,
+}
+
+// ParseGender returns the gender spelled s, ignoring case and surrounding
+// space. Spellings that are not known are returned with surrounding space
+// removed.
+func ParseGender(s string) Gender {
+	if g, ok := genderAliases[normalize(s)]; ok {
+		return g
+	}
+	return Gender(strings.TrimSpace(s))
+}
+
+// Known reports whether g is one of the Gender constants.
+func (g Gender) Known() bool {
+	switch g {
+	case GenderMale, GenderFemale, GenderUnknown, GenderAny:
+		return true
+	}
+	return false
+}
+
+func (g Gender) String() string {
+	return string(g)
+}
+
+// MarshalJSON encodes known genders by their canonical spelling, whatever
+// spelling g was set to. It returns an error for other values, which are
+// kept when decoded but cannot be sent back.
+func (g Gender) MarshalJSON() ([]byte, error) {
+	p := ParseGender(string(g))
+	if !p.Known() {
+		return nil, fmt.Errorf("animalrescue: unknown gender %q", string(g))
+	}
+	return json.Marshal(string(p))
+}
+
+// UnmarshalJSON decodes a gender with ParseGender.
+func (g *Gender) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*g = ParseGender(s)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. Unlike MarshalJSON, it
+// encodes values that are not known unchanged, so that they can be used in
+// query strings and exports.
+func (g Gender) MarshalText() ([]byte, error) {
+	return []byte(ParseGender(string(g))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler like UnmarshalJSON.
+func (g *Gender) UnmarshalText(text []byte) error {
+	*g = ParseGender(string(text))
+	return nil
+}
+
+// AgeGroup is the age group of an adoptee, or the age group preferred by a
+// pet preference. Values read from JSON are normalized, so that "Puppy"
+// and "baby" both decode to AgePuppy. Values the client does not know are
+// kept as they were received.
+type AgeGroup string
+
+// Known age groups, youngest first.
+const (
+	AgePuppy  AgeGroup = "puppy"
+	AgeYoung  AgeGroup = "young"
+	AgeAdult  AgeGroup = "adult"
+	AgeSenior AgeGroup = "senior"
+)
+
+// ageAliases maps normalized spellings onto the known age groups.
+var ageAliases = map[string]AgeGroup{
+	"puppy":      AgePuppy,
+	"baby":       AgePuppy,
+	"young":      AgeYoung,
+	"juvenile":   AgeYoung,
+	"adolescent": AgeYoung,
+	"adult":      AgeAdult,
+	"mature":     AgeAdult,
+	"senior":     AgeSenior,
+	"elderly":    AgeSenior,
+	"old":        AgeSenior,
+}
+
+// ParseAgeGroup returns the age group spelled s, ignoring case and
+// surrounding space. Spellings that are not known are returned with
+// surrounding space removed.
+func ParseAgeGroup(s string) AgeGroup {
+	if a, ok := ageAliases[normalize(s)]; ok {
+		return a
+	}
+	return AgeGroup(strings.TrimSpace(s))
+}
+
+// Known reports whether a is one of the AgeGroup constants.
+func (a AgeGroup) Known() bool {
+	switch a {
+	case AgePuppy, AgeYoung, AgeAdult, AgeSenior:
+		return true
+	}
+	return false
+}
+
+func (a AgeGroup) String() string {
+	return string(a)
+}
+
+// MarshalJSON encodes known age groups by their canonical spelling,
+// whatever spelling a was set to. Like Gender.MarshalJSON, it returns an
+// error for other values.
+func (a AgeGroup) MarshalJSON() ([]byte, error) {
+	p := ParseAgeGroup(string(a))
+	if !p.Known() {
+		return nil, fmt.Errorf("animalrescue: unknown age group %q", string(a))
+	}
+	return json.Marshal(string(p))
+}
+
+// UnmarshalJSON decodes an age group with ParseAgeGroup.
+func (a *AgeGroup) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*a = ParseAgeGroup(s)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler. Like Gender.MarshalText,
+// it encodes values that are not known unchanged.
+func (a AgeGroup) MarshalText() ([]byte, error) {
+	return []byte(ParseAgeGroup(string(a))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler like UnmarshalJSON.
+func (a *AgeGroup) UnmarshalText(text []byte) error {
+	*a = ParseAgeGroup(string(text))
+	return nil
+}
@@ -0,0 +1,130 @@
+package animalrescue
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGender_JSON(t *testing.T) {
+	tests := []struct {
+		in, decoded, encoded string // encoded is empty if Marshal fails
+	}{
+		{`"M"`, "male", `"male"`},
+		{`"Girl"`, "female", `"female"`},
+		{`" Unknown "`, "unknown", `"unknown"`},
+		{`"either"`, "any", `"any"`},
+		{`" Robot "`, "Robot", ""},
+		{`"neutered male"`, "neutered male", ""},
+		{`""`, "", ""},
+	}
+
+	for _, tt := range tests {
+		var g Gender
+		if err := json.Unmarshal([]byte(tt.in), &g); err != nil {
+			t.Fatalf("Unmarshal(%v) returned error: %v", tt.in, err)
+		}
+		if string(g) != tt.decoded {
+			t.Errorf("Unmarshal(%v) = %q, want %q", tt.in, g, tt.decoded)
+		}
+		out, err := json.Marshal(g)
+		if (err != nil) != (tt.encoded == "") || string(out) != tt.encoded {
+			t.Errorf("Marshal(%q) = %s, %v, want %s", g, out, err, tt.encoded)
+		}
+	}
+}
+
+func TestAgeGroup_JSON(t *testing.T) {
+	tests := []struct {
+		in, decoded, encoded string // encoded is empty if Marshal fails
+	}{
+		{`"Puppy"`, "puppy", `"puppy"`},
+		{`"baby"`, "puppy", `"puppy"`},
+		{`"adolescent"`, "young", `"young"`},
+		{`" ELDERLY "`, "senior", `"senior"`},
+		{`"kitten"`, "kitten", ""},
+		{`""`, "", ""},
+	}
+
+	for _, tt := range tests {
+		var a AgeGroup
+		if err := json.Unmarshal([]byte(tt.in), &a); err != nil {
+			t.Fatalf("Unmarshal(%v) returned error: %v", tt.in, err)
+		}
+		if string(a) != tt.decoded {
+			t.Errorf("Unmarshal(%v) = %q, want %q", tt.in, a, tt.decoded)
+		}
+		out, err := json.Marshal(a)
+		if (err != nil) != (tt.encoded == "") || string(out) != tt.encoded {
+			t.Errorf("Marshal(%q) = %s, %v, want %s", a, out, err, tt.encoded)
+		}
+	}
+}
+
+func TestEnums_roundTrip(t *testing.T) {
+	// Every spelling known to the client decodes to a constant that
+	// encodes to its canonical spelling and decodes back to itself.
+	for spelling, want := range genderAliases {
+		var g, back Gender
+		in, _ := json.Marshal(spelling)
+		if err := json.Unmarshal(in, &g); err != nil || g != want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", in, g, err, want)
+			continue
+		}
+		out, err := json.Marshal(g)
+		if err != nil || string(out) != `"`+string(want)+`"` {
+			t.Errorf("Marshal(%q) = %s, %v, want %q", g, out, err, want)
+			continue
+		}
+		if err := json.Unmarshal(out, &back); err != nil || back != g {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", out, back, err, g)
+		}
+	}
+	for spelling, want := range ageAliases {
+		var a, back AgeGroup
+		in, _ := json.Marshal(spelling)
+		if err := json.Unmarshal(in, &a); err != nil || a != want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", in, a, err, want)
+			continue
+		}
+		out, err := json.Marshal(a)
+		if err != nil || string(out) != `"`+string(want)+`"` {
+			t.Errorf("Marshal(%q) = %s, %v, want %q", a, out, err, want)
+			continue
+		}
+		if err := json.Unmarshal(out, &back); err != nil || back != a {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", out, back, err, a)
+		}
+	}
+}
+
+func TestEnums_unknownText(t *testing.T) {
+	// Values the client does not know are kept when decoded, and can be
+	// used as text in query strings and exports, but not sent as JSON.
+	var a Adoptee
+	if err := json.Unmarshal([]byte(`{"id":1,"name":"Rex","gender":"neutered male","age":"geriatric"}`), &a); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if a.Gender != "neutered male" || a.Age != "geriatric" {
+		t.Errorf("Unmarshal kept gender %q, age %q, want %q, %q", a.Gender, a.Age, "neutered male", "geriatric")
+	}
+	if g, err := a.Gender.MarshalText(); err != nil || string(g) != "neutered male" {
+		t.Errorf("MarshalText(%q) = %s, %v", a.Gender, g, err)
+	}
+	if age, err := a.Age.MarshalText(); err != nil || string(age) != "geriatric" {
+		t.Errorf("MarshalText(%q) = %s, %v", a.Age, age, err)
+	}
+	if out, err := json.Marshal(a); err == nil {
+		t.Errorf("Marshal of an adoptee with unknown gender and age = %s, want an error", out)
+	}
+}
+
+func TestGender_omitted(t *testing.T) {
+	// Unset enumerations are omitted rather than rejected.
+	out, err := json.Marshal(NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if want := `{"name":"Rex"}`; string(out) != want {
+		t.Errorf("Marshal = %s, want %s", out, want)
+	}
+}
@@ -0,0 +1,116 @@
+package animalrescue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors for classifying API failures with errors.Is:
+//
+//	if errors.Is(err, animalrescue.ErrNotFound) {
+//		// the adopter no longer exists
+//	}
+var (
+	// ErrNotFound reports a missing resource: a 404 response, or a
+	// field error with code "missing".
+	ErrNotFound = errors.New("animalrescue: not found")
+
+	// ErrConflict reports a clash with an existing resource: a 409
+	// response, or a field error with code "already_exists".
+	ErrConflict = errors.New("animalrescue: conflict")
+
+	// ErrValidation reports invalid input: a field error with code
+	// "missing_field", "invalid" or "custom", or a 400 or 422 response
+	// without field errors.
+	ErrValidation = errors.New("animalrescue: validation failed")
+
+	// ErrUnauthorized reports missing or insufficient credentials: a 401
+	// or 403 response.
+	ErrUnauthorized = errors.New("animalrescue: unauthorized")
+)
+
+// Error codes reported in Error.Code.
+const (
+	CodeMissing       = "missing"
+	CodeMissingField  = "missing_field"
+	CodeInvalid       = "invalid"
+	CodeAlreadyExists = "already_exists"
+	CodeCustom        = "custom"
+)
+
+// Is reports whether the error code of e matches target, one of the
+// sentinel errors ErrNotFound, ErrConflict or ErrValidation.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == CodeMissing
+	case ErrConflict:
+		return e.Code == CodeAlreadyExists
+	case ErrValidation:
+		return e.Code == CodeMissingField || e.Code == CodeInvalid || e.Code == CodeCustom
+	}
+	return false
+}
+
+// A ValidationError reports the field-level errors of a failed request. It
+// is returned by ErrorResponse.Unwrap, so it can be retrieved from any
+// error returned by the client with errors.As:
+//
+//	var verr *animalrescue.ValidationError
+//	if errors.As(err, &verr) {
+//		for _, e := range verr.FieldErrors("email") {
+//			// show e.Code next to the email form field
+//		}
+//	}
+type ValidationError struct {
+	Errors []Error
+}
+
+func (v *ValidationError) Error() string {
+	msgs := make([]string, len(v.Errors))
+	for i := range v.Errors {
+		msgs[i] = v.Errors[i].Error()
+	}
+	return fmt.Sprintf("validation failed: %v", strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the field errors matches target.
+func (v *ValidationError) Is(target error) bool {
+	for i := range v.Errors {
+		if v.Errors[i].Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fields returns the names of the fields with errors, in the order they
+// were first reported.
+func (v *ValidationError) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, e := range v.Errors {
+		if e.Field != "" && !seen[e.Field] {
+			seen[e.Field] = true
+			fields = append(fields, e.Field)
+		}
+	}
+	return fields
+}
+
+// FieldErrors returns the errors reported for the named field.
+func (v *ValidationError) FieldErrors(field string) []Error {
+	var errs []Error
+	for _, e := range v.Errors {
+		if e.Field == field {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// HasField reports whether any error was reported for the named field.
+func (v *ValidationError) HasField(field string) bool {
+	return len(v.FieldErrors(field)) > 0
+}
@@ -0,0 +1,216 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+var sentinels = []struct {
+	name string
+	err  error
+}{
+	{"ErrNotFound", animalrescue.ErrNotFound},
+	{"ErrConflict", animalrescue.ErrConflict},
+	{"ErrValidation", animalrescue.ErrValidation},
+	{"ErrUnauthorized", animalrescue.ErrUnauthorized},
+}
+
+// matched returns the names of the sentinel errors err matches.
+func matched(err error) []string {
+	var names []string
+	for _, s := range sentinels {
+		if errors.Is(err, s.err) {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
+
+func TestErrorResponse_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []string
+	}{
+		{"not found", http.StatusNotFound, `{"message":"Not Found"}`, []string{"ErrNotFound"}},
+		{"conflict", http.StatusConflict, `{"message":"Conflict"}`, []string{"ErrConflict"}},
+		{"bad request", http.StatusBadRequest, `{"message":"Bad Request"}`, []string{"ErrValidation"}},
+		{"unprocessable", http.StatusUnprocessableEntity, `{"message":"Validation Failed"}`, []string{"ErrValidation"}},
+		{"unauthorized", http.StatusUnauthorized, `{"message":"Bad credentials"}`, []string{"ErrUnauthorized"}},
+		{"forbidden", http.StatusForbidden, `{"message":"Forbidden"}`, []string{"ErrUnauthorized"}},
+		{"server error", http.StatusInternalServerError, `{"message":"Oops"}`, nil},
+		{"unparsable body", http.StatusNotFound, `<html>`, []string{"ErrNotFound"}},
+		{
+			// Field errors decide instead of the status.
+			"field errors",
+			http.StatusUnprocessableEntity,
+			`{"message":"Validation Failed","errors":[{"resource":"Adoption","field":"adoptee","code":"already_exists"}]}`,
+			[]string{"ErrConflict"},
+		},
+		{
+			"missing field error",
+			http.StatusUnprocessableEntity,
+			`{"message":"Validation Failed","errors":[{"resource":"Adoption","field":"adopter","code":"missing"}]}`,
+			[]string{"ErrNotFound"},
+		},
+	}
+
+	for _, tt := range tests {
+		err := animalrescue.CheckResponse(&http.Response{
+			StatusCode: tt.status,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(tt.body)),
+		})
+		var rerr *animalrescue.ErrorResponse
+		if !errors.As(err, &rerr) {
+			t.Errorf("%v: CheckResponse returned %T, want *ErrorResponse", tt.name, err)
+			continue
+		}
+		if got := matched(err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: error matches %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := matched(&animalrescue.ErrorResponse{Message: "no response"}); got != nil {
+		t.Errorf("ErrorResponse without a response matches %v, want none", got)
+	}
+}
+
+func TestError_Is(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{animalrescue.CodeMissing, []string{"ErrNotFound"}},
+		{animalrescue.CodeAlreadyExists, []string{"ErrConflict"}},
+		{animalrescue.CodeMissingField, []string{"ErrValidation"}},
+		{animalrescue.CodeInvalid, []string{"ErrValidation"}},
+		{animalrescue.CodeCustom, []string{"ErrValidation"}},
+		{"unheard_of", nil},
+	}
+
+	for _, tt := range tests {
+		err := &animalrescue.Error{Resource: "Adopter", Field: "email", Code: tt.code}
+		if got := matched(err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Error{Code: %q} matches %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := animalrescue.CheckResponse(&http.Response{
+		StatusCode: http.StatusUnprocessableEntity,
+		Header:     http.Header{},
+		Body: ioutil.NopCloser(strings.NewReader(`{"message":"Validation Failed","errors":[` +
+			`{"resource":"Adopter","field":"email","code":"invalid"},` +
+			`{"resource":"Adopter","field":"phone","code":"missing_field"},` +
+			`{"resource":"Adopter","field":"email","code":"already_exists"},` +
+			`{"resource":"Adopter","code":"custom","message":"too many adopters"}]}`)),
+	})
+
+	var verr *animalrescue.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("CheckResponse returned %v, want a *ValidationError through errors.As", err)
+	}
+	if got, want := verr.Fields(), []string{"email", "phone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fields = %v, want %v", got, want)
+	}
+	var codes []string
+	for _, e := range verr.FieldErrors("email") {
+		codes = append(codes, e.Code)
+	}
+	if want := []string{"invalid", "already_exists"}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("FieldErrors(email) have codes %v, want %v", codes, want)
+	}
+	if !verr.HasField("phone") || verr.HasField("address") {
+		t.Errorf("HasField(phone), HasField(address) = %v, %v, want true, false", verr.HasField("phone"), verr.HasField("address"))
+	}
+	if got, want := matched(verr), []string{"ErrConflict", "ErrValidation"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidationError matches %v, want %v", got, want)
+	}
+}
+
+func TestValidationError_fromServer(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptee, _, err := c.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoption := animalrescue.NewAdoption{Adopter: adopter, Adoptee: adoptee}
+	if _, _, err := c.Adoptions.CreateAdoption(ctx, adoption); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = c.Adoptions.CreateAdoption(ctx, adoption)
+	var verr *animalrescue.ValidationError
+	if !errors.Is(err, animalrescue.ErrConflict) || !errors.As(err, &verr) || !verr.HasField("adoptee") {
+		t.Errorf("adopting an adoptee twice returned %v, want a conflict on the adoptee field", err)
+	}
+
+	_, _, err = c.Adopters.GetAdopterByID(ctx, *adopter.ID+100)
+	if got, want := matched(err), []string{"ErrNotFound"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getting a missing adopter returned %v matching %v, want %v", err, got, want)
+	}
+}
+
+func TestValidationError_editLeavesEntity(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	if _, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+		FirstName: animalrescue.String("Jane"),
+		Email:     animalrescue.String("jane@example.com"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+		FirstName:      animalrescue.String("John"),
+		Email:          animalrescue.String("john@example.com"),
+		PetPreferences: []*animalrescue.PetPreference{{Breed: "Beagle"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err = c.Adopters.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{
+		Email:          animalrescue.String("jane@example.com"),
+		PetPreferences: []*animalrescue.PetPreference{{Breed: "Pug"}},
+	})
+	if !errors.Is(err, animalrescue.ErrConflict) {
+		t.Fatalf("taking another adopter's email returned %v, want a conflict", err)
+	}
+
+	got, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email := *got.Email; email != "john@example.com" {
+		t.Errorf("rejected edit left email %q, want %q", email, "john@example.com")
+	}
+	pp, _, err := c.PetPreferences.GetPetPreferenceByID(ctx, int64(adopter.PetPreferences[0].ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pp.Breed != "Beagle" {
+		t.Errorf("rejected edit left pet preference breed %q, want %q", pp.Breed, "Beagle")
+	}
+}
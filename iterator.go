@@ -0,0 +1,131 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+)
+
+// pageFunc fetches the page of results described by opts.
+type pageFunc func(ctx context.Context, opts *ListOptions) ([]interface{}, *Response, error)
+
+type pageResult struct {
+	items []interface{}
+	resp  *Response
+	err   error
+}
+
+// iterator implements the paging logic shared by the typed iterators
+// returned from the various Iter methods. Pages are fetched lazily through
+// fetch, and optionally one page ahead in the background.
+type iterator struct {
+	ctx      context.Context
+	fetch    pageFunc
+	opts     ListOptions
+	prefetch bool
+
+	page    []interface{}
+	cur     interface{}
+	resp    *Response
+	pending chan pageResult
+	done    bool // no pages remain to be fetched
+	err     error
+}
+
+func newIterator(ctx context.Context, opts *ListOptions, fetch pageFunc) iterator {
+	it := iterator{ctx: ctx, fetch: fetch}
+	if opts != nil {
+		it.opts = *opts
+	}
+	return it
+}
+
+// Next advances the iterator to the next value, fetching the next page of
+// results if needed. It returns false when the results are exhausted, the
+// context is canceled or an error occurs; Err distinguishes between these.
+func (it *iterator) Next() bool {
+	it.cur = nil
+	if it.err != nil {
+		return false
+	}
+	if it.ctx == nil {
+		it.err = errors.New("context must be non-nil")
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	for len(it.page) == 0 {
+		if it.done {
+			return false
+		}
+
+		r := it.nextPage()
+		if r.err != nil {
+			it.err = r.err
+			return false
+		}
+		it.page, it.resp = r.items, r.resp
+		it.advance()
+	}
+
+	it.cur = it.page[0]
+	it.page[0] = nil // allow the value to be collected once consumed
+	it.page = it.page[1:]
+	return true
+}
+
+// Err returns the first error encountered during iteration, if any.
+func (it *iterator) Err() error {
+	return it.err
+}
+
+// Response returns the API response for the most recently fetched page.
+func (it *iterator) Response() *Response {
+	return it.resp
+}
+
+// nextPage returns the page described by it.opts, either by waiting on a
+// prefetch started earlier or by fetching it now.
+func (it *iterator) nextPage() pageResult {
+	if it.pending == nil {
+		opts := it.opts
+		items, resp, err := it.fetch(it.ctx, &opts)
+		return pageResult{items, resp, err}
+	}
+
+	pending := it.pending
+	it.pending = nil
+	select {
+	case r := <-pending:
+		return r
+	case <-it.ctx.Done():
+		return pageResult{err: it.ctx.Err()}
+	}
+}
+
+// advance moves it.opts on to the page following the one just fetched, and
+// starts fetching it in the background if prefetching is enabled.
+func (it *iterator) advance() {
+	switch {
+	case len(it.page) == 0 || it.resp == nil:
+		it.done = true
+	case it.resp.NextCursor != "":
+		it.opts.Cursor = it.resp.NextCursor
+	case it.resp.NextPage != 0:
+		it.opts.Page = it.resp.NextPage
+	default:
+		it.done = true
+	}
+
+	if it.prefetch && !it.done {
+		// The channel is buffered so the goroutine never blocks, even if the
+		// caller stops iterating before the page is consumed.
+		it.pending = make(chan pageResult, 1)
+		go func(ctx context.Context, fetch pageFunc, opts ListOptions, ch chan<- pageResult) {
+			items, resp, err := fetch(ctx, &opts)
+			ch <- pageResult{items, resp, err}
+		}(it.ctx, it.fetch, it.opts, it.pending)
+	}
+}
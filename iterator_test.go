@@ -0,0 +1,309 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// pageRecorder records the pages of listings requested through it, and
+// fails requests for failPage.
+type pageRecorder struct {
+	mu        sync.Mutex
+	pages     []string
+	requested chan struct{} // receives a value for every request, if set
+	failPage  string
+	next      http.RoundTripper
+}
+
+func (p *pageRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	page := req.URL.Query().Get("page")
+	p.mu.Lock()
+	p.pages = append(p.pages, page)
+	p.mu.Unlock()
+	if p.requested != nil {
+		p.requested <- struct{}{}
+	}
+	if p.failPage != "" && page == p.failPage {
+		return nil, errors.New("connection reset by peer")
+	}
+	return p.next.RoundTrip(req)
+}
+
+func (p *pageRecorder) requestedPages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.pages...)
+}
+
+// newPagedClient returns a client of srv, which holds n adopters named
+// "adopter 1" to "adopter n", that reaches it through p.
+func newPagedClient(t *testing.T, srv *animalrescuetest.Server, n int, p *pageRecorder) *animalrescue.Client {
+	t.Helper()
+	ctx := context.Background()
+	for i := 1; i <= n; i++ {
+		if _, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String(fmt.Sprintf("adopter %d", i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			p.next = next
+			return p
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestAdoptersService_Iter(t *testing.T) {
+	tests := []struct {
+		name      string
+		adopters  int
+		opts      *animalrescue.AdopterListOptions
+		prefetch  bool
+		failPage  string
+		wantPages []string
+		first     int // number of the first adopter returned
+		last      int // number of the last adopter returned
+		wantErr   bool
+	}{
+		{
+			name:      "single page",
+			adopters:  3,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 5}},
+			wantPages: []string{""},
+			first:     1,
+			last:      3,
+		},
+		{
+			name:      "several pages",
+			adopters:  5,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}},
+			wantPages: []string{"", "2", "3"},
+			first:     1,
+			last:      5,
+		},
+		{
+			name:      "prefetch",
+			adopters:  5,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}},
+			prefetch:  true,
+			wantPages: []string{"", "2", "3"},
+			first:     1,
+			last:      5,
+		},
+		{
+			name:      "starting page",
+			adopters:  5,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{Page: 2, PerPage: 2}},
+			wantPages: []string{"2", "3"},
+			first:     3,
+			last:      5,
+		},
+		{
+			name:      "failed page",
+			adopters:  5,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}},
+			failPage:  "2",
+			wantPages: []string{"", "2"},
+			first:     1,
+			last:      2,
+			wantErr:   true,
+		},
+		{
+			name:      "failed prefetch",
+			adopters:  5,
+			opts:      &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}},
+			prefetch:  true,
+			failPage:  "2",
+			wantPages: []string{"", "2"},
+			first:     1,
+			last:      2,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			p := &pageRecorder{failPage: tt.failPage}
+			c := newPagedClient(t, srv, tt.adopters, p)
+
+			it := c.Adopters.Iter(context.Background(), tt.opts)
+			if tt.prefetch {
+				it = it.WithPrefetch()
+			}
+			var names []string
+			for it.Next() {
+				names = append(names, *it.Value().FirstName)
+			}
+
+			if err := it.Err(); (err != nil) != tt.wantErr {
+				t.Errorf("Err() = %v, want error: %v", err, tt.wantErr)
+			}
+			if it.Value() != nil {
+				t.Errorf("Value() = %v after the iteration stopped, want nil", it.Value())
+			}
+			if pages := p.requestedPages(); !reflect.DeepEqual(pages, tt.wantPages) {
+				t.Errorf("iterator requested pages %q, want %q", pages, tt.wantPages)
+			}
+			var want []string
+			for i := tt.first; i <= tt.last; i++ {
+				want = append(want, fmt.Sprintf("adopter %d", i))
+			}
+			if !reflect.DeepEqual(names, want) {
+				t.Errorf("iterator returned %q, want %q", names, want)
+			}
+		})
+	}
+}
+
+func TestAdoptersService_Iter_prefetchesNextPage(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	p := &pageRecorder{requested: make(chan struct{}, 10)}
+	c := newPagedClient(t, srv, 4, p)
+
+	it := c.Adopters.Iter(context.Background(), &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}}).WithPrefetch()
+	if !it.Next() {
+		t.Fatalf("Next() = false, err %v", it.Err())
+	}
+	// The second page is requested while the first one is consumed.
+	for i := 0; i < 2; i++ {
+		select {
+		case <-p.requested:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("iterator requested pages %q, want the second page prefetched", p.requestedPages())
+		}
+	}
+	if pages := p.requestedPages(); !reflect.DeepEqual(pages, []string{"", "2"}) {
+		t.Errorf("iterator requested pages %q, want [\"\" \"2\"]", pages)
+	}
+}
+
+func TestAdoptersService_Iter_canceled(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	p := &pageRecorder{}
+	c := newPagedClient(t, srv, 4, p)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := c.Adopters.Iter(ctx, &animalrescue.AdopterListOptions{ListOptions: animalrescue.ListOptions{PerPage: 2}}).WithPrefetch()
+	if !it.Next() {
+		t.Fatalf("Next() = false, err %v", it.Err())
+	}
+	cancel()
+	if it.Next() {
+		t.Error("Next() = true after the context was canceled")
+	}
+	if err := it.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+}
+
+// pager is the part of the typed iterators common to all of them.
+type pager interface {
+	Next() bool
+	Err() error
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestIter_requestOptions(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		adoptee, _, err := srv.Client().Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := srv.Client().Adoptions.CreateAdoption(ctx, animalrescue.NewAdoption{Adopter: adopter, Adoptee: adoptee}); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := srv.Client().PetPreferences.CreatePetPreference(ctx, animalrescue.NewPetPreference{Breed: "Beagle"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		headers []string
+	)
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				headers = append(headers, req.Header.Get("X-Source"))
+				mu.Unlock()
+				return next.RoundTrip(req)
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page := animalrescue.ListOptions{PerPage: 2}
+	src := animalrescue.WithHeader("X-Source", "iter")
+	tests := []struct {
+		name string
+		iter func() pager
+	}{
+		{"adopters", func() pager {
+			return c.Adopters.Iter(ctx, &animalrescue.AdopterListOptions{ListOptions: page}, src)
+		}},
+		{"adoptees", func() pager {
+			return c.Adoptees.Iter(ctx, &animalrescue.AdopteeListOptions{ListOptions: page}, src)
+		}},
+		{"adoptions", func() pager {
+			return c.Adoptions.Iter(ctx, &animalrescue.AdoptionListOptions{ListOptions: page}, src)
+		}},
+		{"pet preferences", func() pager {
+			p := page
+			return c.PetPreferences.Iter(ctx, &p, src)
+		}},
+	}
+
+	for _, tt := range tests {
+		mu.Lock()
+		headers = nil
+		mu.Unlock()
+
+		it := tt.iter()
+		n := 0
+		for it.Next() {
+			n++
+		}
+		if err := it.Err(); err != nil || n != 3 {
+			t.Errorf("%v: iterated over %d items with error %v, want 3", tt.name, n, err)
+		}
+		mu.Lock()
+		if len(headers) != 2 || headers[0] != "iter" || headers[1] != "iter" {
+			t.Errorf("%v: page requests carried X-Source %q, want \"iter\" on both pages", tt.name, headers)
+		}
+		mu.Unlock()
+	}
+}
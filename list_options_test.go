@@ -0,0 +1,136 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestListAll_filtersAndSorts(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	var adopters []*animalrescue.Adopter
+	for _, a := range []struct{ first, last, state string }{
+		{"Jane", "Doe", "OR"},
+		{"John", "Smith", "WA"},
+		{"Ann", "Lee", "or"},
+	} {
+		adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+			FirstName: animalrescue.String(a.first),
+			LastName:  animalrescue.String(a.last),
+			State:     animalrescue.String(a.state),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		adopters = append(adopters, adopter)
+	}
+	var adoptees []*animalrescue.Adoptee
+	for _, a := range []animalrescue.NewAdoptee{
+		{Name: "Rex", Gender: animalrescue.GenderMale, Age: animalrescue.AgeAdult},
+		{Name: "Bella", Gender: animalrescue.GenderFemale, Age: animalrescue.AgePuppy},
+		{Name: "Luna", Gender: animalrescue.GenderFemale, Age: animalrescue.AgeSenior},
+	} {
+		adoptee, _, err := c.Adoptees.CreateAdoptee(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adoptees = append(adoptees, adoptee)
+	}
+	day := func(d int) *animalrescue.Timestamp {
+		return &animalrescue.Timestamp{Time: time.Date(2020, 5, d, 0, 0, 0, 0, time.UTC)}
+	}
+	for i, created := range []*animalrescue.Timestamp{day(1), day(10), day(20)} {
+		_, _, err := c.Adoptions.CreateAdoption(ctx, animalrescue.NewAdoption{
+			Adopter:   adopters[0],
+			Adoptee:   adoptees[i],
+			CreatedAt: created,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("adopters", func(t *testing.T) {
+		got, _, err := c.Adopters.ListAll(ctx, &animalrescue.AdopterListOptions{
+			State:     "OR",
+			Sort:      "last_name",
+			Direction: "desc",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, a := range got {
+			names = append(names, *a.LastName)
+		}
+		if want := []string{"Lee", "Doe"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("ListAll returned %q, want %q", names, want)
+		}
+	})
+
+	t.Run("adoptees", func(t *testing.T) {
+		got, _, err := c.Adoptees.ListAll(ctx, &animalrescue.AdopteeListOptions{
+			Gender: "F",
+			Sort:   "age",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, a := range got {
+			names = append(names, a.Name)
+		}
+		if want := []string{"Bella", "Luna"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("ListAll returned %q, want %q", names, want)
+		}
+	})
+
+	t.Run("adoptions", func(t *testing.T) {
+		got, _, err := c.Adoptions.ListAll(ctx, &animalrescue.AdoptionListOptions{
+			AdopterID:     *adopters[0].ID,
+			CreatedAfter:  day(5).Time,
+			CreatedBefore: day(25).Time,
+			Sort:          "created_at",
+			Direction:     "desc",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, a := range got {
+			names = append(names, a.Adoptee.Name)
+		}
+		if want := []string{"Luna", "Bella"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("ListAll returned %q, want %q", names, want)
+		}
+	})
+
+	t.Run("paged", func(t *testing.T) {
+		got, resp, err := c.Adoptees.ListAll(ctx, &animalrescue.AdopteeListOptions{
+			Sort:        "name",
+			ListOptions: animalrescue.ListOptions{Page: 2, PerPage: 2},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].Name != "Rex" || resp.PrevPage != 1 {
+			t.Errorf("ListAll returned %v with previous page %d, want [Rex] and page 1", got, resp.PrevPage)
+		}
+	})
+
+	t.Run("invalid sort", func(t *testing.T) {
+		_, _, err := c.Adopters.ListAll(ctx, &animalrescue.AdopterListOptions{Sort: "email"})
+		if !errors.Is(err, animalrescue.ErrValidation) {
+			t.Errorf("ListAll sorted by email returned %v, want ErrValidation", err)
+		}
+	})
+}
@@ -0,0 +1,148 @@
+package animalrescue
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Logger is the interface used by the client to report diagnostic messages.
+// It is satisfied by *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// redacted replaces the value of every field redacted from logged output.
+const redacted = "[REDACTED]"
+
+// piiFields lists the JSON fields of an Adopter, and the query parameters,
+// that hold personally identifiable information and are never logged.
+var piiFields = map[string]bool{
+	"email":     true,
+	"phone":     true,
+	"address":   true,
+	"birthdate": true,
+	"zip_code":  true,
+}
+
+// logf reports a diagnostic message to c.Logger, if one is set.
+func (c *Client) logf(format string, v ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+	}
+}
+
+// logRequest reports a single attempt at req to c.Logger: the method, URL,
+// outcome and latency, and the request and response bodies if LogBodies is
+// set. Adopter PII is redacted from the URL and bodies. If the response
+// body is logged, it is replaced so that it can still be read by the caller.
+func (c *Client) logRequest(req *http.Request, resp *http.Response, err error, latency time.Duration) {
+	if c.Logger == nil {
+		return
+	}
+
+	u := RedactURL(req.URL)
+	latency = latency.Round(time.Millisecond)
+	if err != nil {
+		c.logf("animalrescue: %v %v failed after %v: %v", req.Method, u, latency, redactError(err))
+	} else {
+		c.logf("animalrescue: %v %v: %v (%v)", req.Method, u, resp.Status, latency)
+	}
+
+	if !c.LogBodies {
+		return
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(body)
+			body.Close()
+			if len(data) > 0 {
+				c.logf("animalrescue: request body: %s", RedactPII(data))
+			}
+		}
+	}
+	if resp != nil && resp.Body != nil {
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if err == nil && len(data) > 0 {
+			c.logf("animalrescue: response body: %s", RedactPII(data))
+		}
+	}
+}
+
+// RedactPII returns a copy of the JSON document data with the values of
+// Adopter PII fields (email, phone, address, birthdate and zip_code)
+// replaced, wherever they appear in it. Data that is not valid JSON is
+// replaced entirely, since it cannot be inspected.
+func RedactPII(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []byte(redacted)
+	}
+
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range v {
+			if piiFields[strings.ToLower(k)] && vv != nil {
+				v[k] = redacted
+			} else {
+				v[k] = redactValue(vv)
+			}
+		}
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = redactValue(vv)
+		}
+	}
+	return v
+}
+
+// redactError returns err with the URL of a *url.Error, which the transport
+// returns and which embeds the request URL in its message, redacted by
+// RedactURL. Other errors are returned unchanged.
+func redactError(err error) error {
+	uerr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	u, perr := url.Parse(uerr.URL)
+	if perr != nil {
+		return &url.Error{Op: uerr.Op, URL: redacted, Err: uerr.Err}
+	}
+	return &url.Error{Op: uerr.Op, URL: RedactURL(u), Err: uerr.Err}
+}
+
+// RedactURL returns u as a string with the values of query parameters named
+// after Adopter PII fields replaced, along with any user credentials.
+func RedactURL(u *url.URL) string {
+	u2 := *u
+	if u2.User != nil {
+		u2.User = url.User(redacted)
+	}
+
+	q := u2.Query()
+	changed := false
+	for k := range q {
+		if piiFields[strings.ToLower(k)] {
+			q.Set(k, redacted)
+			changed = true
+		}
+	}
+	if changed {
+		u2.RawQuery = q.Encode()
+	}
+	return u2.String()
+}
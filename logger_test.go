@@ -0,0 +1,132 @@
+package animalrescue_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// bufferLogger collects logged lines.
+type bufferLogger struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *bufferLogger) Printf(format string, v ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(&l.buf, format+"\n", v...)
+}
+
+func (l *bufferLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+// piiPattern matches the email addresses and zip codes used below, raw or
+// percent-encoded.
+var piiPattern = regexp.MustCompile(`@|%40|90210|555-0100|Main St`)
+
+// unreachableURL returns the URL of a port nothing listens on.
+func unreachableURL(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr + "/"
+}
+
+func TestLogger_redactsPII(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"message":"unavailable","email":"jane@example.com"}`)
+	}))
+	defer unavailable.Close()
+
+	listOpts := &animalrescue.AdopterListOptions{Email: "jane@example.com", ZipCode: "90210"}
+	tests := []struct {
+		name    string
+		baseURL string
+		call    func(ctx context.Context, c *animalrescue.Client) error
+	}{
+		{
+			name:    "transport error",
+			baseURL: unreachableURL(t),
+			call: func(ctx context.Context, c *animalrescue.Client) error {
+				_, _, err := c.Adopters.ListAll(ctx, listOpts)
+				return err
+			},
+		},
+		{
+			name:    "retried status",
+			baseURL: unavailable.URL + "/",
+			call: func(ctx context.Context, c *animalrescue.Client) error {
+				_, _, err := c.Adopters.ListAll(ctx, listOpts)
+				return err
+			},
+		},
+		{
+			name:    "bodies",
+			baseURL: srv.URL + "/",
+			call: func(ctx context.Context, c *animalrescue.Client) error {
+				_, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+					FirstName: animalrescue.String("Jane"),
+					LastName:  animalrescue.String("Doe"),
+					Email:     animalrescue.String("jane@example.com"),
+					Phone:     animalrescue.String("555-0100"),
+					Address:   animalrescue.String("1 Main St"),
+					ZipCode:   animalrescue.String("90210"),
+				})
+				if err != nil {
+					return err
+				}
+				_, _, err = c.Adopters.ListAll(ctx, listOpts)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := new(bufferLogger)
+			c, err := animalrescue.NewClientWithOptions(
+				animalrescue.WithBaseURL(tt.baseURL),
+				animalrescue.WithLogger(logger),
+				animalrescue.WithBodyLogging(),
+				animalrescue.WithRetryPolicy(&animalrescue.RetryPolicy{
+					MaxAttempts:    2,
+					InitialBackoff: time.Millisecond,
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.call(context.Background(), c)
+
+			out := logger.String()
+			if out == "" {
+				t.Fatal("nothing was logged")
+			}
+			if loc := piiPattern.FindStringIndex(out); loc != nil {
+				t.Errorf("log output contains PII %q:\n%v", out[loc[0]:loc[1]], out)
+			}
+		})
+	}
+}
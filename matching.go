@@ -0,0 +1,282 @@
+package animalrescue
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MatchWeights sets the relative importance of each criterion when scoring
+// an adoptee against a pet preference. Weights need not sum to 1.
+type MatchWeights struct {
+	Breed  float64
+	Age    float64
+	Gender float64
+}
+
+// DefaultMatchWeights favors breed over age, and age over gender.
+var DefaultMatchWeights = MatchWeights{Breed: 0.5, Age: 0.3, Gender: 0.2}
+
+// DefaultBreedGroups maps lower-case breed names to the group they belong to.
+// Adoptees of a different breed in the same group as a preferred breed earn
+// partial credit.
+var DefaultBreedGroups = map[string]string{
+	// dogs
+	"labrador retriever":   "sporting",
+	"golden retriever":     "sporting",
+	"cocker spaniel":       "sporting",
+	"pointer":              "sporting",
+	"beagle":               "hound",
+	"dachshund":            "hound",
+	"basset hound":         "hound",
+	"greyhound":            "hound",
+	"german shepherd":      "herding",
+	"border collie":        "herding",
+	"australian shepherd":  "herding",
+	"corgi":                "herding",
+	"boxer":                "working",
+	"rottweiler":           "working",
+	"husky":                "working",
+	"great dane":           "working",
+	"jack russell terrier": "terrier",
+	"pit bull terrier":     "terrier",
+	"yorkshire terrier":    "terrier",
+	"chihuahua":            "toy",
+	"pug":                  "toy",
+	"shih tzu":             "toy",
+	"pomeranian":           "toy",
+	"poodle":               "non-sporting",
+	"bulldog":              "non-sporting",
+	"dalmatian":            "non-sporting",
+	// cats
+	"domestic shorthair": "shorthair cat",
+	"siamese":            "shorthair cat",
+	"british shorthair":  "shorthair cat",
+	"domestic longhair":  "longhair cat",
+	"maine coon":         "longhair cat",
+	"persian":            "longhair cat",
+	"ragdoll":            "longhair cat",
+}
+
+// DefaultAgeBands lists the age bands known to the matcher, youngest first.
+// Adoptees in a band adjacent to the preferred one earn partial credit.
+var DefaultAgeBands = []AgeGroup{AgePuppy, AgeYoung, AgeAdult, AgeSenior}
+
+// A Matcher scores adoptees against the pet preferences of an adopter. The
+// zero value is ready to use and applies the defaults documented on each
+// field.
+type Matcher struct {
+	// Weights of each criterion. Default: DefaultMatchWeights.
+	Weights *MatchWeights
+
+	// BreedGroups maps lower-case breed names to their group.
+	// Default: DefaultBreedGroups.
+	BreedGroups map[string]string
+
+	// AgeBands lists the known age bands, youngest first.
+	// Default: DefaultAgeBands.
+	AgeBands []AgeGroup
+
+	// MinScore excludes candidates scoring below it from ranked results.
+	// Candidates scoring zero are always excluded.
+	MinScore float64
+}
+
+// MatchReason explains the credit an adoptee earned for one criterion.
+type MatchReason struct {
+	Criterion   string  // "breed", "age" or "gender"
+	Score       float64 // credit earned, from 0 to 1, before weighting
+	Explanation string
+}
+
+// A Match is an adoptee ranked against an adopter's pet preferences.
+type Match struct {
+	Adoptee *Adoptee
+
+	// Preference is the pet preference the adoptee matched best, or nil if
+	// the adopter has no preferences.
+	Preference *PetPreference
+
+	// Score is the weighted score, from 0 to 1.
+	Score float64
+
+	Reasons []MatchReason
+}
+
+func (m Match) String() string {
+	return Stringify(m)
+}
+
+// Score scores adoptee a against pref. A nil pref, meaning no preference,
+// gives full credit on every criterion.
+func (m *Matcher) Score(pref *PetPreference, a *Adoptee) *Match {
+	if pref == nil {
+		pref = &PetPreference{}
+	}
+	w := DefaultMatchWeights
+	if m.Weights != nil {
+		w = *m.Weights
+	}
+
+	breed, breedWhy := m.scoreBreed(pref.Breed, a.Breed)
+	age, ageWhy := m.scoreAge(pref.Age, a.Age)
+	gender, genderWhy := scoreGender(pref.Gender, a.Gender)
+
+	match := &Match{
+		Adoptee: a,
+		Reasons: []MatchReason{
+			{Criterion: "breed", Score: breed, Explanation: breedWhy},
+			{Criterion: "age", Score: age, Explanation: ageWhy},
+			{Criterion: "gender", Score: gender, Explanation: genderWhy},
+		},
+	}
+	if total := w.Breed + w.Age + w.Gender; total > 0 {
+		match.Score = (w.Breed*breed + w.Age*age + w.Gender*gender) / total
+	}
+	return match
+}
+
+// Rank scores every adoptee against each of prefs, keeps the best scoring
+// preference per adoptee, and returns the candidates ordered from best to
+// worst match. Ties are broken by adoptee ID.
+func (m *Matcher) Rank(prefs []*PetPreference, adoptees []*Adoptee) []*Match {
+	if len(prefs) == 0 {
+		prefs = []*PetPreference{nil}
+	}
+
+	var matches []*Match
+	for _, a := range adoptees {
+		if a == nil {
+			continue
+		}
+		var best *Match
+		for _, pref := range prefs {
+			match := m.Score(pref, a)
+			match.Preference = pref
+			if best == nil || match.Score > best.Score {
+				best = match
+			}
+		}
+		if best.Score > 0 && best.Score >= m.MinScore {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Adoptee.ID < matches[j].Adoptee.ID
+	})
+	return matches
+}
+
+func (m *Matcher) scoreBreed(want, got string) (float64, string) {
+	want, got = normalize(want), normalize(got)
+	switch {
+	case want == "":
+		return 1, "no breed preference"
+	case want == got:
+		return 1, fmt.Sprintf("breed %q matches", got)
+	case got != "" && strings.Contains(got, want):
+		return 0.75, fmt.Sprintf("breed %q includes preferred %q", got, want)
+	}
+
+	groups := m.BreedGroups
+	if groups == nil {
+		groups = DefaultBreedGroups
+	}
+	if group, ok := groups[want]; ok && groups[got] == group {
+		return 0.5, fmt.Sprintf("breed %q is in the same %s group as preferred %q", got, group, want)
+	}
+	return 0, fmt.Sprintf("breed %q does not match preferred %q", got, want)
+}
+
+func (m *Matcher) scoreAge(want, got AgeGroup) (float64, string) {
+	want, got = ParseAgeGroup(string(want)), ParseAgeGroup(string(got))
+	if want == "" {
+		return 1, "no age preference"
+	}
+	if want == got {
+		return 1, fmt.Sprintf("age %q matches", got)
+	}
+
+	bands := m.AgeBands
+	if bands == nil {
+		bands = DefaultAgeBands
+	}
+	wi, gi := indexOf(bands, want), indexOf(bands, got)
+	if wi >= 0 && gi >= 0 && (wi-gi == 1 || gi-wi == 1) {
+		return 0.5, fmt.Sprintf("age %q is adjacent to preferred %q", got, want)
+	}
+	return 0, fmt.Sprintf("age %q does not match preferred %q", got, want)
+}
+
+func scoreGender(want, got Gender) (float64, string) {
+	want, got = ParseGender(string(want)), ParseGender(string(got))
+	switch {
+	case want == "" || want == GenderAny:
+		return 1, "no gender preference"
+	case want == got:
+		return 1, fmt.Sprintf("gender %q matches", got)
+	}
+	return 0, fmt.Sprintf("gender %q does not match preferred %q", got, want)
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func indexOf(list []AgeGroup, a AgeGroup) int {
+	for i, v := range list {
+		if v == a {
+			return i
+		}
+	}
+	return -1
+}
+
+// Match ranks the adoptees available for adoption against the pet
+// preferences of the adopter referenced by ID, using a zero Matcher.
+// Adoptees that already have an adoption are excluded.
+func (c *Client) Match(ctx context.Context, adopterID int64) ([]*Match, *Response, error) {
+	return c.MatchWith(ctx, adopterID, nil)
+}
+
+// MatchWith is like Match, but scores candidates with m. A nil m is
+// equivalent to a zero Matcher.
+func (c *Client) MatchWith(ctx context.Context, adopterID int64, m *Matcher) ([]*Match, *Response, error) {
+	if m == nil {
+		m = &Matcher{}
+	}
+
+	adopter, resp, err := c.Adopters.GetAdopterByID(ctx, adopterID)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	adopted := make(map[int]bool)
+	adoptions := c.Adoptions.Iter(ctx, nil)
+	for adoptions.Next() {
+		if a := adoptions.Value(); a != nil && a.Adoptee != nil {
+			adopted[a.Adoptee.ID] = true
+		}
+	}
+	if err := adoptions.Err(); err != nil {
+		return nil, adoptions.Response(), err
+	}
+
+	var candidates []*Adoptee
+	adoptees := c.Adoptees.Iter(ctx, nil)
+	for adoptees.Next() {
+		if a := adoptees.Value(); !adopted[a.ID] {
+			candidates = append(candidates, a)
+		}
+	}
+	if err := adoptees.Err(); err != nil {
+		return nil, adoptees.Response(), err
+	}
+
+	return m.Rank(adopter.PetPreferences, candidates), adoptees.Response(), nil
+}
@@ -0,0 +1,288 @@
+package animalrescue_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"path"
+	"reflect"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestMatcher_Score(t *testing.T) {
+	rex := &animalrescue.Adoptee{ID: 1, Name: "Rex", Breed: "Beagle", Age: animalrescue.AgeAdult, Gender: animalrescue.GenderMale}
+	tests := []struct {
+		name    string
+		matcher animalrescue.Matcher
+		pref    *animalrescue.PetPreference
+		adoptee *animalrescue.Adoptee
+		want    float64
+		reasons [3]float64 // credit for breed, age and gender
+	}{
+		{name: "no preference", want: 1, reasons: [3]float64{1, 1, 1}},
+		{
+			name:    "exact",
+			pref:    &animalrescue.PetPreference{Breed: " beagle ", Age: "mature", Gender: "M"},
+			want:    1,
+			reasons: [3]float64{1, 1, 1},
+		},
+		{
+			name:    "breed included",
+			pref:    &animalrescue.PetPreference{Breed: "terrier"},
+			adoptee: &animalrescue.Adoptee{Breed: "Jack Russell Terrier"},
+			want:    0.875,
+			reasons: [3]float64{0.75, 1, 1},
+		},
+		{
+			name:    "breed group",
+			pref:    &animalrescue.PetPreference{Breed: "Dachshund"},
+			want:    0.75,
+			reasons: [3]float64{0.5, 1, 1},
+		},
+		{
+			name:    "other breed",
+			pref:    &animalrescue.PetPreference{Breed: "Pug"},
+			want:    0.5,
+			reasons: [3]float64{0, 1, 1},
+		},
+		{
+			name:    "custom breed groups",
+			matcher: animalrescue.Matcher{BreedGroups: map[string]string{"beagle": "family", "pug": "family"}},
+			pref:    &animalrescue.PetPreference{Breed: "Pug"},
+			want:    0.75,
+			reasons: [3]float64{0.5, 1, 1},
+		},
+		{
+			name:    "adjacent age",
+			pref:    &animalrescue.PetPreference{Age: animalrescue.AgeSenior},
+			want:    0.85,
+			reasons: [3]float64{1, 0.5, 1},
+		},
+		{
+			name:    "distant age",
+			pref:    &animalrescue.PetPreference{Age: animalrescue.AgePuppy},
+			want:    0.7,
+			reasons: [3]float64{1, 0, 1},
+		},
+		{
+			name:    "custom age bands",
+			matcher: animalrescue.Matcher{AgeBands: []animalrescue.AgeGroup{animalrescue.AgePuppy, animalrescue.AgeAdult}},
+			pref:    &animalrescue.PetPreference{Age: "baby"},
+			want:    0.85,
+			reasons: [3]float64{1, 0.5, 1},
+		},
+		{
+			name:    "any gender",
+			pref:    &animalrescue.PetPreference{Gender: animalrescue.GenderAny},
+			want:    1,
+			reasons: [3]float64{1, 1, 1},
+		},
+		{
+			name:    "other gender",
+			pref:    &animalrescue.PetPreference{Gender: animalrescue.GenderFemale},
+			want:    0.8,
+			reasons: [3]float64{1, 1, 0},
+		},
+		{
+			name:    "weights",
+			matcher: animalrescue.Matcher{Weights: &animalrescue.MatchWeights{Breed: 1, Gender: 3}},
+			pref:    &animalrescue.PetPreference{Breed: "Pug", Age: animalrescue.AgePuppy},
+			want:    0.75,
+			reasons: [3]float64{0, 0, 1},
+		},
+		{
+			name:    "zero weights",
+			matcher: animalrescue.Matcher{Weights: &animalrescue.MatchWeights{}},
+			want:    0,
+			reasons: [3]float64{1, 1, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		a := tt.adoptee
+		if a == nil {
+			a = rex
+		}
+		got := tt.matcher.Score(tt.pref, a)
+		if got.Adoptee != a || math.Abs(got.Score-tt.want) > 1e-9 {
+			t.Errorf("%v: Score = %v, want %v", tt.name, got.Score, tt.want)
+		}
+		if len(got.Reasons) != 3 {
+			t.Errorf("%v: Score gave reasons %v, want 3", tt.name, got.Reasons)
+			continue
+		}
+		for i, criterion := range []string{"breed", "age", "gender"} {
+			r := got.Reasons[i]
+			if r.Criterion != criterion || r.Score != tt.reasons[i] || r.Explanation == "" {
+				t.Errorf("%v: reason %d = %+v, want %v credit %v", tt.name, i, r, criterion, tt.reasons[i])
+			}
+		}
+	}
+}
+
+func TestMatcher_Rank(t *testing.T) {
+	adoptees := []*animalrescue.Adoptee{
+		{ID: 3, Name: "Max", Breed: "Pug", Age: animalrescue.AgeSenior, Gender: animalrescue.GenderMale},
+		{ID: 4, Name: "Luna", Breed: "Dachshund", Age: animalrescue.AgeAdult, Gender: animalrescue.GenderFemale},
+		nil,
+		{ID: 1, Name: "Rex", Breed: "Beagle", Age: animalrescue.AgeAdult, Gender: animalrescue.GenderMale},
+		{ID: 2, Name: "Bella", Breed: "Beagle", Age: animalrescue.AgePuppy, Gender: animalrescue.GenderFemale},
+	}
+	adultBeagle := &animalrescue.PetPreference{ID: 10, Breed: "Beagle", Age: animalrescue.AgeAdult}
+	pug := &animalrescue.PetPreference{ID: 11, Breed: "Pug"}
+
+	tests := []struct {
+		name    string
+		matcher animalrescue.Matcher
+		prefs   []*animalrescue.PetPreference
+		want    []int // adoptee IDs, best first
+		prefIDs []int // IDs of the preference each matched, 0 for none
+	}{
+		{
+			name:    "no preferences",
+			want:    []int{1, 2, 3, 4}, // all tied
+			prefIDs: []int{0, 0, 0, 0},
+		},
+		{
+			name:    "one preference",
+			prefs:   []*animalrescue.PetPreference{adultBeagle},
+			want:    []int{1, 4, 2, 3}, // 1, 0.75, 0.7, 0.35
+			prefIDs: []int{10, 10, 10, 10},
+		},
+		{
+			name:    "best preference",
+			prefs:   []*animalrescue.PetPreference{adultBeagle, pug},
+			want:    []int{1, 3, 4, 2}, // 1, 1, 0.75, 0.7
+			prefIDs: []int{10, 11, 10, 10},
+		},
+		{
+			name:    "min score",
+			matcher: animalrescue.Matcher{MinScore: 0.7},
+			prefs:   []*animalrescue.PetPreference{adultBeagle},
+			want:    []int{1, 4, 2},
+			prefIDs: []int{10, 10, 10},
+		},
+		{
+			name:    "zero scores",
+			matcher: animalrescue.Matcher{Weights: &animalrescue.MatchWeights{Breed: 1}},
+			prefs:   []*animalrescue.PetPreference{{Breed: "Persian"}},
+		},
+	}
+
+	for _, tt := range tests {
+		var ids, prefIDs []int
+		for _, m := range tt.matcher.Rank(tt.prefs, adoptees) {
+			ids = append(ids, m.Adoptee.ID)
+			if m.Preference == nil {
+				prefIDs = append(prefIDs, 0)
+			} else {
+				prefIDs = append(prefIDs, m.Preference.ID)
+			}
+		}
+		if !reflect.DeepEqual(ids, tt.want) || !reflect.DeepEqual(prefIDs, tt.prefIDs) {
+			t.Errorf("%v: Rank = %v matching preferences %v, want %v matching %v", tt.name, ids, prefIDs, tt.want, tt.prefIDs)
+		}
+	}
+}
+
+func TestClient_MatchWith(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	c := srv.Client()
+	ctx := context.Background()
+
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+		FirstName:      animalrescue.String("Jane"),
+		PetPreferences: []*animalrescue.PetPreference{{Breed: "Beagle", Age: animalrescue.AgeAdult}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var adopted *animalrescue.Adoptee
+	for _, a := range []animalrescue.NewAdoptee{
+		{Name: "Rex", Breed: "Beagle", Age: animalrescue.AgeAdult},
+		{Name: "Bella", Breed: "Beagle", Age: animalrescue.AgePuppy},
+		{Name: "Max", Breed: "Pug", Age: animalrescue.AgeSenior},
+		{Name: "Luna", Breed: "Dachshund", Age: animalrescue.AgeAdult},
+	} {
+		adoptee, _, err := c.Adoptees.CreateAdoptee(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Name == "Rex" {
+			adopted = adoptee
+		}
+	}
+	other, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("John")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adoptions.CreateAdoption(ctx, animalrescue.NewAdoption{Adopter: other, Adoptee: adopted}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		matcher *animalrescue.Matcher
+		want    []string
+	}{
+		{"default", nil, []string{"Luna", "Bella", "Max"}},
+		{"min score", &animalrescue.Matcher{MinScore: 0.7}, []string{"Luna", "Bella"}},
+	}
+
+	for _, tt := range tests {
+		matches, _, err := c.MatchWith(ctx, *adopter.ID, tt.matcher)
+		if err != nil {
+			t.Fatalf("%v: MatchWith returned error: %v", tt.name, err)
+		}
+		var names []string
+		for _, m := range matches {
+			names = append(names, m.Adoptee.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%v: MatchWith ranked %v, want %v", tt.name, names, tt.want)
+		}
+	}
+
+	// A null element of the adoptions list is skipped.
+	withNull, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if err != nil || path.Base(req.URL.Path) != "adoptions" {
+					return resp, err
+				}
+				body, err := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				if err != nil {
+					return nil, err
+				}
+				body = bytes.Replace(body, []byte("["), []byte("[null,"), 1)
+				resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+				resp.ContentLength = int64(len(body))
+				resp.Header.Del("Content-Length")
+				return resp, nil
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	matches, _, err := withNull.MatchWith(ctx, *adopter.ID, nil)
+	if err != nil {
+		t.Fatalf("MatchWith with a null adoption returned error: %v", err)
+	}
+	if len(matches) != 3 {
+		t.Errorf("MatchWith with a null adoption returned %d matches, want 3", len(matches))
+	}
+
+	if _, _, err := c.MatchWith(ctx, *adopter.ID+100, nil); err == nil {
+		t.Error("MatchWith of a missing adopter returned no error")
+	}
+}
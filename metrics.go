@@ -0,0 +1,110 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Metrics collects measurements of the requests sent by a Client. Do
+// reports every request to it; the metrics package provides an in-memory
+// implementation and one exposing the measurements to Prometheus.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// RequestStarted is called before a request is sent, with only the
+	// Service, Method and HTTPMethod fields of s set.
+	RequestStarted(s *RequestStats)
+
+	// RequestFinished is called once a request, and any retry of it, is
+	// done.
+	RequestFinished(s *RequestStats)
+}
+
+// Error codes reported in RequestStats.ErrorCodes for failures without
+// field errors.
+const (
+	// ErrorCodeCanceled is reported for requests whose context was
+	// canceled or timed out.
+	ErrorCodeCanceled = "canceled"
+
+	// ErrorCodeNetwork is reported for requests that got no response.
+	ErrorCodeNetwork = "network"
+
+	// ErrorCodeInvalidResponse is reported for responses whose body could
+	// not be decoded.
+	ErrorCodeInvalidResponse = "invalid_response"
+)
+
+// RequestStats describes a request sent by Do.
+type RequestStats struct {
+	// Service and Method name the service method that sent the request,
+	// such as "Adopters" and "GetAdopterByID". They are empty for requests
+	// sent with Do directly.
+	Service string
+	Method  string
+
+	HTTPMethod string
+
+	// Status is the HTTP status of the last response, or 0 if none was
+	// received.
+	Status int
+
+	// Duration is the time taken by the request, including retries and
+	// any wait for the rate limit to reset.
+	Duration time.Duration
+
+	// Retries is the number of times the request was retried.
+	Retries int
+
+	// BytesSent and BytesReceived count the bytes of the request and
+	// response bodies, over every attempt. The bodies of the responses
+	// that were retried are read in full; that of the last response counts
+	// for as much of it as was read by the time RequestFinished is called,
+	// which is all of it when Do decodes it.
+	BytesSent     int64
+	BytesReceived int64
+
+	// Err is the error returned by Do, if any. ErrorCodes lists the codes
+	// of the field errors reported by the API for it (see Error.Code); if
+	// there are none, it holds "http_" followed by the status code for API
+	// errors, or one of the ErrorCode* constants.
+	Err        error
+	ErrorCodes []string
+}
+
+// errorCodes returns the codes reported in RequestStats.ErrorCodes for err,
+// returned for a request whose last response had the given status.
+func errorCodes(ctx context.Context, err error, status int) []string {
+	var rerr *ErrorResponse
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &rerr) && len(rerr.Errors) > 0:
+		codes := make([]string, len(rerr.Errors))
+		for i, e := range rerr.Errors {
+			codes[i] = e.Code
+		}
+		return codes
+	case ctx.Err() != nil:
+		return []string{ErrorCodeCanceled}
+	case status == 0:
+		return []string{ErrorCodeNetwork}
+	case status >= 300:
+		return []string{fmt.Sprintf("http_%d", status)}
+	}
+	return []string{ErrorCodeInvalidResponse}
+}
+
+// countingBody counts the bytes read from a response body.
+type countingBody struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	*b.n += int64(n)
+	return n, err
+}
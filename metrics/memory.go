@@ -0,0 +1,91 @@
+// Package metrics provides implementations of animalrescue.Metrics: Memory,
+// which keeps every measurement for tests to assert against, and
+// Prometheus, which aggregates them and serves them in the Prometheus text
+// exposition format:
+//
+//	m := metrics.NewPrometheus(nil)
+//	client.Metrics = m
+//	http.Handle("/metrics", m)
+package metrics
+
+import (
+	"sync"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Memory records the requests reported to it. The zero value is ready to
+// use.
+type Memory struct {
+	mu       sync.Mutex
+	inFlight int
+	requests []animalrescue.RequestStats
+}
+
+// RequestStarted implements animalrescue.Metrics.
+func (m *Memory) RequestStarted(s *animalrescue.RequestStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+}
+
+// RequestFinished implements animalrescue.Metrics.
+func (m *Memory) RequestFinished(s *animalrescue.RequestStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	r := *s
+	r.ErrorCodes = append([]string(nil), s.ErrorCodes...)
+	m.requests = append(m.requests, r)
+}
+
+// InFlight returns the number of requests started but not yet finished.
+func (m *Memory) InFlight() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.inFlight
+}
+
+// Requests returns the finished requests, in the order they finished.
+func (m *Memory) Requests() []animalrescue.RequestStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]animalrescue.RequestStats(nil), m.requests...)
+}
+
+// Count returns the number of finished requests sent by the given service
+// method, such as "Adopters" and "GetAdopterByID".
+func (m *Memory) Count(service, method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, r := range m.requests {
+		if r.Service == service && r.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// ErrorCount returns the number of times code was reported in the
+// ErrorCodes of the finished requests.
+func (m *Memory) ErrorCount(code string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, r := range m.requests {
+		for _, c := range r.ErrorCodes {
+			if c == code {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// Reset discards the finished requests.
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = nil
+}
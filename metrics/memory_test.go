@@ -0,0 +1,174 @@
+package metrics_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+	"github.com/anGie44/go-animal-rescue/metrics"
+)
+
+// unavailableBody is the body of the 503 responses served by flaky.
+const unavailableBody = `{"message":"Service Unavailable"}`
+
+// flaky answers the first n requests with 503 Service Unavailable.
+func flaky(n int) animalrescue.Middleware {
+	var mu sync.Mutex
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			fail := n > 0
+			n--
+			mu.Unlock()
+			if !fail {
+				return next.RoundTrip(req)
+			}
+			return &http.Response{
+				Status:     "503 Service Unavailable",
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(unavailableBody)),
+				Request:    req,
+			}, nil
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestMemory(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{
+		FirstName: animalrescue.String("Jane"),
+		LastName:  animalrescue.String("Doe"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// size is the length of the body of the response to a GET of adopter.
+	resp, err := http.Get(srv.URL + "/adopter/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	size := int64(len(body))
+
+	tests := []struct {
+		name       string
+		failures   int // 503 responses served before the request goes through
+		call       func(c *animalrescue.Client) error
+		method     string
+		status     int
+		retries    int
+		sent       bool
+		received   int64
+		errorCodes []string
+	}{
+		{
+			name: "get",
+			call: func(c *animalrescue.Client) error {
+				_, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID)
+				return err
+			},
+			method:   "GetAdopterByID",
+			status:   http.StatusOK,
+			received: size,
+		},
+		{
+			name:     "retried get",
+			failures: 2,
+			call: func(c *animalrescue.Client) error {
+				_, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID)
+				return err
+			},
+			method:   "GetAdopterByID",
+			status:   http.StatusOK,
+			retries:  2,
+			received: 2*int64(len(unavailableBody)) + size,
+		},
+		{
+			name: "not found",
+			call: func(c *animalrescue.Client) error {
+				_, _, err := c.Adopters.GetAdopterByID(ctx, 404)
+				return err
+			},
+			method:     "GetAdopterByID",
+			status:     http.StatusNotFound,
+			errorCodes: []string{animalrescue.CodeMissing},
+		},
+		{
+			name: "edit",
+			call: func(c *animalrescue.Client) error {
+				_, _, err := c.Adopters.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{City: animalrescue.String("Austin")})
+				return err
+			},
+			method: "EditAdopterByID",
+			status: http.StatusOK,
+			sent:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(metrics.Memory)
+			c, err := animalrescue.NewClientWithOptions(
+				animalrescue.WithBaseURL(srv.URL+"/"),
+				animalrescue.WithMetrics(m),
+				animalrescue.WithMiddleware(flaky(tt.failures)),
+				animalrescue.WithRetryPolicy(&animalrescue.RetryPolicy{
+					MaxAttempts:    3,
+					InitialBackoff: time.Millisecond,
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.call(c)
+
+			if n := m.InFlight(); n != 0 {
+				t.Errorf("InFlight() = %d, want 0", n)
+			}
+			if n := m.Count("Adopters", tt.method); n != 1 {
+				t.Fatalf("Count(Adopters, %v) = %d, want 1", tt.method, n)
+			}
+			r := m.Requests()[0]
+			if r.Status != tt.status || r.Retries != tt.retries {
+				t.Errorf("request has status %d after %d retries, want %d after %d", r.Status, r.Retries, tt.status, tt.retries)
+			}
+			if (r.BytesSent > 0) != tt.sent {
+				t.Errorf("BytesSent = %d, want a body sent: %v", r.BytesSent, tt.sent)
+			}
+			if tt.received > 0 && r.BytesReceived != tt.received {
+				t.Errorf("BytesReceived = %d, want %d", r.BytesReceived, tt.received)
+			}
+			for _, code := range tt.errorCodes {
+				if n := m.ErrorCount(code); n != 1 {
+					t.Errorf("ErrorCount(%v) = %d, want 1", code, n)
+				}
+			}
+			if len(tt.errorCodes) == 0 && r.Err != nil {
+				t.Errorf("request failed with %v", r.Err)
+			}
+
+			m.Reset()
+			if n := len(m.Requests()); n != 0 {
+				t.Errorf("Requests() holds %d requests after Reset, want 0", n)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// DefaultBuckets are the default upper bounds, in seconds, of the request
+// duration histogram buckets.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// contentType is the content type of the text exposition format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Prometheus aggregates the requests reported to it into the following
+// metrics, labeled with the service and method that sent them:
+//
+//	animalrescue_client_request_duration_seconds  histogram, also by status ("0" if no response)
+//	animalrescue_client_errors_total              counter, also by error code
+//	animalrescue_client_retries_total             counter
+//	animalrescue_client_sent_bytes_total          counter of request body bytes
+//	animalrescue_client_received_bytes_total      counter of response body bytes
+//	animalrescue_client_requests_in_flight        gauge
+//
+// It serves them over HTTP in the Prometheus text exposition format.
+type Prometheus struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	durations map[string]*histogram
+	errors    map[string]float64
+	retries   map[string]float64
+	sent      map[string]float64
+	received  map[string]float64
+	inFlight  map[string]float64
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheus returns a Prometheus collector whose duration histogram has
+// the given bucket upper bounds, in seconds. If buckets is nil,
+// DefaultBuckets are used.
+func NewPrometheus(buckets []float64) *Prometheus {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Prometheus{
+		buckets:   buckets,
+		durations: make(map[string]*histogram),
+		errors:    make(map[string]float64),
+		retries:   make(map[string]float64),
+		sent:      make(map[string]float64),
+		received:  make(map[string]float64),
+		inFlight:  make(map[string]float64),
+	}
+}
+
+// RequestStarted implements animalrescue.Metrics.
+func (p *Prometheus) RequestStarted(s *animalrescue.RequestStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight[labels("service", s.Service, "method", s.Method)]++
+}
+
+// RequestFinished implements animalrescue.Metrics.
+func (p *Prometheus) RequestFinished(s *animalrescue.RequestStats) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	method := labels("service", s.Service, "method", s.Method)
+	p.inFlight[method]--
+	p.retries[method] += float64(s.Retries)
+	p.sent[method] += float64(s.BytesSent)
+	p.received[method] += float64(s.BytesReceived)
+	for _, code := range s.ErrorCodes {
+		p.errors[labels("service", s.Service, "method", s.Method, "code", code)]++
+	}
+
+	key := labels("service", s.Service, "method", s.Method, "status", strconv.Itoa(s.Status))
+	h := p.durations[key]
+	if h == nil {
+		h = &histogram{counts: make([]uint64, len(p.buckets))}
+		p.durations[key] = h
+	}
+	secs := s.Duration.Seconds()
+	if i := sort.SearchFloat64s(p.buckets, secs); i < len(p.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += secs
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format.
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	p.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format.
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	name := "animalrescue_client_request_duration_seconds"
+	fmt.Fprintf(cw, "# HELP %v Duration of requests to the Animal Rescue API, including retries.\n", name)
+	fmt.Fprintf(cw, "# TYPE %v histogram\n", name)
+	for _, key := range sortedKeys(p.durations) {
+		h := p.durations[key]
+		var cum uint64
+		for i, le := range p.buckets {
+			cum += h.counts[i]
+			fmt.Fprintf(cw, "%v_bucket{%v,le=\"%v\"} %d\n", name, key, formatFloat(le), cum)
+		}
+		fmt.Fprintf(cw, "%v_bucket{%v,le=\"+Inf\"} %d\n", name, key, h.count)
+		fmt.Fprintf(cw, "%v_sum{%v} %v\n", name, key, formatFloat(h.sum))
+		fmt.Fprintf(cw, "%v_count{%v} %d\n", name, key, h.count)
+	}
+
+	writeSeries(cw, "animalrescue_client_errors_total", "counter",
+		"Failed requests to the Animal Rescue API, by error code.", p.errors)
+	writeSeries(cw, "animalrescue_client_retries_total", "counter",
+		"Retries of requests to the Animal Rescue API.", p.retries)
+	writeSeries(cw, "animalrescue_client_sent_bytes_total", "counter",
+		"Bytes of request bodies sent to the Animal Rescue API.", p.sent)
+	writeSeries(cw, "animalrescue_client_received_bytes_total", "counter",
+		"Bytes of response bodies received from the Animal Rescue API.", p.received)
+	writeSeries(cw, "animalrescue_client_requests_in_flight", "gauge",
+		"Requests to the Animal Rescue API in progress.", p.inFlight)
+
+	if err := cw.w.Flush(); err != nil && cw.err == nil {
+		cw.err = err
+	}
+	return cw.n, cw.err
+}
+
+// writeSeries writes a metric with one sample per label set in series.
+func writeSeries(w io.Writer, name, typ, help string, series map[string]float64) {
+	fmt.Fprintf(w, "# HELP %v %v\n", name, help)
+	fmt.Fprintf(w, "# TYPE %v %v\n", name, typ)
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%v{%v} %v\n", name, key, formatFloat(series[key]))
+	}
+}
+
+func sortedKeys(m map[string]*histogram) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// labelEscaper escapes label values as the exposition format requires.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels renders the given label names and values, which alternate, as
+// they appear between the braces of a sample.
+func labels(kv ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%v=\"%v\"", kv[i], labelEscaper.Replace(kv[i+1]))
+	}
+	return b.String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written to w and keeps the first error.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
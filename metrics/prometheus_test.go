@@ -0,0 +1,167 @@
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+	"github.com/anGie44/go-animal-rescue/metrics"
+)
+
+// bodySize returns the length of the body of the response to a GET of url.
+func bodySize(t *testing.T, url string) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(body)
+}
+
+// sumLine matches the samples of the duration sums, which vary from run to
+// run.
+var sumLine = regexp.MustCompile(`(?m)^(animalrescue_client_request_duration_seconds_sum\{.*\}) (.*)$`)
+
+func TestPrometheus(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found, missing := bodySize(t, srv.URL+"/adopter/1"), bodySize(t, srv.URL+"/adopter/404")
+
+	p := metrics.NewPrometheus([]float64{60, 0.05})
+	// Requests with the X-Slow header take 100ms, and see themselves in
+	// flight.
+	var during string
+	slow := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Slow") != "" {
+				var buf bytes.Buffer
+				p.WriteTo(&buf)
+				during = buf.String()
+				time.Sleep(100 * time.Millisecond)
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMetrics(p),
+		animalrescue.WithMiddleware(slow),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID, animalrescue.WithHeader("X-Slow", "1")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adopters.GetAdopterByID(ctx, 404); err == nil {
+		t.Fatal("GetAdopterByID(404) succeeded")
+	}
+	// Label values are escaped.
+	odd := &animalrescue.RequestStats{Service: `Odd"Service`, Method: "Back\\slash\nNewline", Duration: time.Second}
+	p.RequestStarted(odd)
+	p.RequestFinished(odd)
+
+	wantInFlight := `animalrescue_client_requests_in_flight{service="Adopters",method="GetAdopterByID"} 1` + "\n"
+	if !strings.Contains(during, wantInFlight) {
+		t.Errorf("metrics during a request lack %q:\n%s", wantInFlight, during)
+	}
+
+	var buf bytes.Buffer
+	n, err := p.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d bytes written, wrote %d", n, buf.Len())
+	}
+
+	sums := make(map[string]float64)
+	for _, m := range sumLine.FindAllStringSubmatch(buf.String(), -1) {
+		sums[m[1]], err = strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			t.Errorf("sample %v has value %q", m[1], m[2])
+		}
+	}
+	okSum := `animalrescue_client_request_duration_seconds_sum{service="Adopters",method="GetAdopterByID",status="200"}`
+	if s := sums[okSum]; s < 0.1 || s >= 60 {
+		t.Errorf("%v = %v, want the 100ms of the slow request and some", okSum, s)
+	}
+
+	got := sumLine.ReplaceAllString(buf.String(), "$1 SUM")
+	want := fmt.Sprintf(`# HELP animalrescue_client_request_duration_seconds Duration of requests to the Animal Rescue API, including retries.
+# TYPE animalrescue_client_request_duration_seconds histogram
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="200",le="0.05"} 1
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="200",le="60"} 2
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="200",le="+Inf"} 2
+animalrescue_client_request_duration_seconds_sum{service="Adopters",method="GetAdopterByID",status="200"} SUM
+animalrescue_client_request_duration_seconds_count{service="Adopters",method="GetAdopterByID",status="200"} 2
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="404",le="0.05"} 1
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="404",le="60"} 1
+animalrescue_client_request_duration_seconds_bucket{service="Adopters",method="GetAdopterByID",status="404",le="+Inf"} 1
+animalrescue_client_request_duration_seconds_sum{service="Adopters",method="GetAdopterByID",status="404"} SUM
+animalrescue_client_request_duration_seconds_count{service="Adopters",method="GetAdopterByID",status="404"} 1
+animalrescue_client_request_duration_seconds_bucket{service="Odd\"Service",method="Back\\slash\nNewline",status="0",le="0.05"} 0
+animalrescue_client_request_duration_seconds_bucket{service="Odd\"Service",method="Back\\slash\nNewline",status="0",le="60"} 1
+animalrescue_client_request_duration_seconds_bucket{service="Odd\"Service",method="Back\\slash\nNewline",status="0",le="+Inf"} 1
+animalrescue_client_request_duration_seconds_sum{service="Odd\"Service",method="Back\\slash\nNewline",status="0"} SUM
+animalrescue_client_request_duration_seconds_count{service="Odd\"Service",method="Back\\slash\nNewline",status="0"} 1
+# HELP animalrescue_client_errors_total Failed requests to the Animal Rescue API, by error code.
+# TYPE animalrescue_client_errors_total counter
+animalrescue_client_errors_total{service="Adopters",method="GetAdopterByID",code="missing"} 1
+# HELP animalrescue_client_retries_total Retries of requests to the Animal Rescue API.
+# TYPE animalrescue_client_retries_total counter
+animalrescue_client_retries_total{service="Adopters",method="GetAdopterByID"} 0
+animalrescue_client_retries_total{service="Odd\"Service",method="Back\\slash\nNewline"} 0
+# HELP animalrescue_client_sent_bytes_total Bytes of request bodies sent to the Animal Rescue API.
+# TYPE animalrescue_client_sent_bytes_total counter
+animalrescue_client_sent_bytes_total{service="Adopters",method="GetAdopterByID"} 0
+animalrescue_client_sent_bytes_total{service="Odd\"Service",method="Back\\slash\nNewline"} 0
+# HELP animalrescue_client_received_bytes_total Bytes of response bodies received from the Animal Rescue API.
+# TYPE animalrescue_client_received_bytes_total counter
+animalrescue_client_received_bytes_total{service="Adopters",method="GetAdopterByID"} %d
+animalrescue_client_received_bytes_total{service="Odd\"Service",method="Back\\slash\nNewline"} 0
+# HELP animalrescue_client_requests_in_flight Requests to the Animal Rescue API in progress.
+# TYPE animalrescue_client_requests_in_flight gauge
+animalrescue_client_requests_in_flight{service="Adopters",method="GetAdopterByID"} 0
+animalrescue_client_requests_in_flight{service="Odd\"Service",method="Back\\slash\nNewline"} 0
+`, 2*found+missing)
+	if got != want {
+		t.Errorf("WriteTo wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrometheus_ServeHTTP(t *testing.T) {
+	p := metrics.NewPrometheus(nil)
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want the text exposition format", ct)
+	}
+	if !strings.Contains(w.Body.String(), "# TYPE animalrescue_client_requests_in_flight gauge\n") {
+		t.Errorf("ServeHTTP wrote\n%s", w.Body.String())
+	}
+}
@@ -0,0 +1,317 @@
+package animalrescue
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An Option configures a Client built by NewClientWithOptions.
+type Option func(*clientConfig) error
+
+// Middleware wraps the transport used to send requests, for example to add
+// headers or record metrics.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+type clientConfig struct {
+	httpClient       *http.Client
+	baseURL          *url.URL
+	userAgent        *string
+	timeout          time.Duration
+	retryPolicy      *RetryPolicy
+	waitForRateLimit bool
+	logger           Logger
+	logBodies        bool
+	header           http.Header
+	middleware       []Middleware
+	cache            Cache
+	validate         bool
+	tracer           Tracer
+	metrics          Metrics
+	applicationStore ApplicationStore
+}
+
+// NewClientWithOptions returns a new Animal Rescue API client configured by
+// opts. Unlike NewClient, it validates the configuration up front and
+// returns an error if any option is invalid.
+func NewClientWithOptions(opts ...Option) (*Client, error) {
+	cfg := &clientConfig{}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Never modify an *http.Client supplied by the caller.
+	httpClient := &http.Client{}
+	if cfg.httpClient != nil {
+		clone := *cfg.httpClient
+		httpClient = &clone
+	}
+	if cfg.timeout > 0 {
+		httpClient.Timeout = cfg.timeout
+	}
+	if cfg.cache != nil {
+		httpClient.Transport = withCache(httpClient.Transport, cfg.cache)
+	}
+	if len(cfg.middleware) > 0 {
+		rt := transport(httpClient.Transport)
+		for i := len(cfg.middleware) - 1; i >= 0; i-- {
+			rt = cfg.middleware[i](rt)
+		}
+		httpClient.Transport = rt
+	}
+
+	c := NewClient(httpClient)
+	if cfg.baseURL != nil {
+		c.BaseURL = cfg.baseURL
+	}
+	if cfg.userAgent != nil {
+		c.UserAgent = *cfg.userAgent
+	}
+	c.RetryPolicy = cfg.retryPolicy
+	c.WaitForRateLimit = cfg.waitForRateLimit
+	c.ValidateRequests = cfg.validate
+	c.Tracer = cfg.tracer
+	c.Metrics = cfg.metrics
+	if cfg.applicationStore != nil {
+		c.ApplicationStore = cfg.applicationStore
+	}
+	c.Logger = cfg.logger
+	c.LogBodies = cfg.logBodies
+	c.Header = cfg.header
+	return c, nil
+}
+
+// WithHTTPClient sets the HTTP client used to send requests. The client is
+// copied, so options such as WithClientTimeout do not modify it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(cfg *clientConfig) error {
+		if httpClient == nil {
+			return errors.New("http client must be non-nil")
+		}
+		cfg.httpClient = httpClient
+		return nil
+	}
+}
+
+// WithBaseURL sets the base URL for API requests. It must be an absolute
+// http or https URL; a trailing slash is added to its path if missing.
+func WithBaseURL(baseURL string) Option {
+	return func(cfg *clientConfig) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base URL %q: %v", baseURL, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+		}
+		if u.Host == "" {
+			return fmt.Errorf("invalid base URL %q: missing host", baseURL)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid base URL %q: must not have a query or fragment", baseURL)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+			if u.RawPath != "" {
+				u.RawPath += "/"
+			}
+		}
+		cfg.baseURL = u
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request. An
+// empty string disables the header.
+func WithUserAgent(userAgent string) Option {
+	return func(cfg *clientConfig) error {
+		cfg.userAgent = &userAgent
+		return nil
+	}
+}
+
+// WithClientTimeout sets the time limit for each attempt at a request,
+// including reading the response body.
+func WithClientTimeout(d time.Duration) Option {
+	return func(cfg *clientConfig) error {
+		if d < 0 {
+			return fmt.Errorf("timeout must not be negative, got %v", d)
+		}
+		cfg.timeout = d
+		return nil
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(p *RetryPolicy) Option {
+	return func(cfg *clientConfig) error {
+		if p != nil && (p.Jitter < 0 || p.Jitter > 1) {
+			return fmt.Errorf("retry jitter must be between 0 and 1, got %v", p.Jitter)
+		}
+		if p != nil && p.InitialBackoff > 0 && p.MaxBackoff > 0 && p.InitialBackoff > p.MaxBackoff {
+			return fmt.Errorf("retry initial backoff %v exceeds max backoff %v", p.InitialBackoff, p.MaxBackoff)
+		}
+		cfg.retryPolicy = p
+		return nil
+	}
+}
+
+// WithWaitForRateLimit makes the client wait for the rate limit to reset
+// once it has been used up, rather than sending requests that would be
+// rejected.
+func WithWaitForRateLimit() Option {
+	return func(cfg *clientConfig) error {
+		cfg.waitForRateLimit = true
+		return nil
+	}
+}
+
+// WithValidation makes the client validate entities before creating or
+// editing them. See Client.ValidateRequests.
+func WithValidation() Option {
+	return func(cfg *clientConfig) error {
+		cfg.validate = true
+		return nil
+	}
+}
+
+// WithTracer sets the tracer that records a span for every service call.
+func WithTracer(t Tracer) Option {
+	return func(cfg *clientConfig) error {
+		if t == nil {
+			return errors.New("tracer must be non-nil")
+		}
+		cfg.tracer = t
+		return nil
+	}
+}
+
+// WithMetrics sets the collector that receives measurements of every
+// request sent.
+func WithMetrics(m Metrics) Option {
+	return func(cfg *clientConfig) error {
+		if m == nil {
+			return errors.New("metrics must be non-nil")
+		}
+		cfg.metrics = m
+		return nil
+	}
+}
+
+// WithApplicationStore sets the store of the applications managed by the
+// client's ApplicationsService, instead of a new MemoryApplicationStore.
+func WithApplicationStore(s ApplicationStore) Option {
+	return func(cfg *clientConfig) error {
+		if s == nil {
+			return errors.New("application store must be non-nil")
+		}
+		cfg.applicationStore = s
+		return nil
+	}
+}
+
+// WithLogger sets the logger the client reports diagnostic messages to.
+func WithLogger(l Logger) Option {
+	return func(cfg *clientConfig) error {
+		if l == nil {
+			return errors.New("logger must be non-nil")
+		}
+		cfg.logger = l
+		return nil
+	}
+}
+
+// WithBodyLogging makes the client log request and response bodies, with
+// Adopter PII redacted. It has no effect unless a logger is set.
+func WithBodyLogging() Option {
+	return func(cfg *clientConfig) error {
+		cfg.logBodies = true
+		return nil
+	}
+}
+
+// WithDefaultHeaders sets headers added to every request. Headers set by the
+// client itself, such as Accept and User-Agent, take precedence.
+func WithDefaultHeaders(h http.Header) Option {
+	return func(cfg *clientConfig) error {
+		if cfg.header == nil {
+			cfg.header = make(http.Header)
+		}
+		for k, v := range h {
+			for _, vv := range v {
+				cfg.header.Add(k, vv)
+			}
+		}
+		return nil
+	}
+}
+
+// WithMiddleware wraps the client's transport with mw. Middleware listed
+// first is outermost, so it sees each request first.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(cfg *clientConfig) error {
+		for _, m := range mw {
+			if m == nil {
+				return errors.New("middleware must be non-nil")
+			}
+		}
+		cfg.middleware = append(cfg.middleware, mw...)
+		return nil
+	}
+}
+
+// WithCache caches responses in cache and revalidates them with conditional
+// requests, so that unchanged resources are not downloaded again. See
+// CachingTransport.
+//
+// If the HTTP client authenticates with an APIKeyTransport,
+// BearerTokenTransport or ClientCredentialsTransport, the client uses a
+// copy of it that sends requests through the cache, so that responses are
+// cached per credentials. Credentials added by other transports or by
+// middleware are not seen by the cache: use a CachingTransport as the
+// Transport of the one adding them instead of this option.
+func WithCache(cache Cache) Option {
+	return func(cfg *clientConfig) error {
+		if cache == nil {
+			return errors.New("cache must be non-nil")
+		}
+		cfg.cache = cache
+		return nil
+	}
+}
+
+// withCache returns rt sending requests through a CachingTransport storing
+// responses in cache. The transports of this package that authenticate
+// requests are copied with the cache installed beneath them, since it must
+// see their credentials.
+func withCache(rt http.RoundTripper, cache Cache) http.RoundTripper {
+	switch t := rt.(type) {
+	case *APIKeyTransport:
+		c := *t
+		ct := &CachingTransport{Cache: cache, Transport: t.Transport}
+		if t.QueryParam == "" && t.Header != "" {
+			ct.KeyHeaders = append([]string{t.Header}, defaultKeyHeaders...)
+		}
+		c.Transport = ct
+		return &c
+	case *BearerTokenTransport:
+		c := *t
+		c.Transport = &CachingTransport{Cache: cache, Transport: t.Transport}
+		return &c
+	case *ClientCredentialsTransport:
+		return &ClientCredentialsTransport{
+			TokenURL:     t.TokenURL,
+			ClientID:     t.ClientID,
+			ClientSecret: t.ClientSecret,
+			Scopes:       t.Scopes,
+			ExpiryDelta:  t.ExpiryDelta,
+			Transport:    &CachingTransport{Cache: cache, Transport: t.Transport},
+		}
+	}
+	return &CachingTransport{Cache: cache, Transport: rt}
+}
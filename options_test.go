@@ -0,0 +1,186 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+func TestNewClientWithOptions_nil(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  animalrescue.Option
+	}{
+		{"http client", animalrescue.WithHTTPClient(nil)},
+		{"tracer", animalrescue.WithTracer(nil)},
+		{"metrics", animalrescue.WithMetrics(nil)},
+		{"application store", animalrescue.WithApplicationStore(nil)},
+		{"logger", animalrescue.WithLogger(nil)},
+		{"middleware", animalrescue.WithMiddleware(nil)},
+		{"cache", animalrescue.WithCache(nil)},
+	}
+
+	for _, tt := range tests {
+		if _, err := animalrescue.NewClientWithOptions(tt.opt); err == nil {
+			t.Errorf("NewClientWithOptions accepted a nil %v", tt.name)
+		}
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string // empty if rejected
+	}{
+		{"https://api.example.com/", "https://api.example.com/"},
+		{"https://api.example.com", "https://api.example.com/"},
+		{"http://localhost:8080/v1", "http://localhost:8080/v1/"},
+		{"https://api.example.com/a%2Fb", "https://api.example.com/a%2Fb/"},
+		{"ftp://api.example.com/", ""},
+		{"api.example.com/v1/", ""},
+		{"/v1/", ""},
+		{"https:///v1/", ""},
+		{"https://api.example.com/v1/?key=secret", ""},
+		{"https://api.example.com/v1/#top", ""},
+		{"https://api.example.com/%zz", ""},
+	}
+
+	for _, tt := range tests {
+		c, err := animalrescue.NewClientWithOptions(animalrescue.WithBaseURL(tt.baseURL))
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("WithBaseURL accepted %q as %v", tt.baseURL, c.BaseURL)
+		case tt.want != "" && err != nil:
+			t.Errorf("WithBaseURL(%q) returned error: %v", tt.baseURL, err)
+		case tt.want != "" && c.BaseURL.String() != tt.want:
+			t.Errorf("WithBaseURL(%q) set %v, want %v", tt.baseURL, c.BaseURL, tt.want)
+		}
+	}
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *animalrescue.RetryPolicy
+		valid  bool
+	}{
+		{"nil", nil, true},
+		{"default", animalrescue.DefaultRetryPolicy(), true},
+		{"zero", &animalrescue.RetryPolicy{}, true},
+		{"no jitter", &animalrescue.RetryPolicy{Jitter: 0}, true},
+		{"full jitter", &animalrescue.RetryPolicy{Jitter: 1}, true},
+		{"negative jitter", &animalrescue.RetryPolicy{Jitter: -0.1}, false},
+		{"jitter above 1", &animalrescue.RetryPolicy{Jitter: 1.5}, false},
+		{"equal backoffs", &animalrescue.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Second}, true},
+		{"default max backoff", &animalrescue.RetryPolicy{InitialBackoff: time.Minute}, true},
+		{"initial above max", &animalrescue.RetryPolicy{InitialBackoff: 2 * time.Second, MaxBackoff: time.Second}, false},
+	}
+
+	for _, tt := range tests {
+		c, err := animalrescue.NewClientWithOptions(animalrescue.WithRetryPolicy(tt.policy))
+		if (err == nil) != tt.valid {
+			t.Errorf("WithRetryPolicy with %v policy returned error %v, want valid: %v", tt.name, err, tt.valid)
+			continue
+		}
+		if err == nil && c.RetryPolicy != tt.policy {
+			t.Errorf("WithRetryPolicy with %v policy set %+v", tt.name, c.RetryPolicy)
+		}
+	}
+}
+
+func TestWithClientTimeout(t *testing.T) {
+	if _, err := animalrescue.NewClientWithOptions(animalrescue.WithClientTimeout(-time.Second)); err == nil {
+		t.Error("WithClientTimeout accepted a negative timeout")
+	}
+	for _, d := range []time.Duration{0, 30 * time.Second} {
+		if _, err := animalrescue.NewClientWithOptions(animalrescue.WithClientTimeout(d)); err != nil {
+			t.Errorf("WithClientTimeout(%v) returned error: %v", d, err)
+		}
+	}
+}
+
+func TestWithHTTPClient_notModified(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("not sent")
+	})
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := &http.Client{Transport: transport, Timeout: time.Minute, Jar: jar}
+
+	_, err = animalrescue.NewClientWithOptions(
+		animalrescue.WithHTTPClient(hc),
+		animalrescue.WithClientTimeout(time.Second),
+		animalrescue.WithCache(animalrescue.NewMemoryCache(10)),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper { return next }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc.Timeout != time.Minute {
+		t.Errorf("caller's client timeout changed to %v", hc.Timeout)
+	}
+	if _, ok := hc.Transport.(roundTripFunc); !ok {
+		t.Errorf("caller's client transport changed to %T", hc.Transport)
+	}
+	if hc.Jar != jar {
+		t.Error("caller's client cookie jar changed")
+	}
+}
+
+func TestWithMiddleware_order(t *testing.T) {
+	var calls []string
+	record := func(name string) animalrescue.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				resp, err := next.RoundTrip(req)
+				calls = append(calls, name+" response")
+				return resp, err
+			})
+		}
+	}
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "transport")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`[]`)),
+			Request:    req,
+		}, nil
+	})
+
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithHTTPClient(&http.Client{Transport: transport}),
+		animalrescue.WithMiddleware(record("outer"), record("middle")),
+		animalrescue.WithMiddleware(record("inner")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adopters.ListAll(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"outer request",
+		"middle request",
+		"inner request",
+		"transport",
+		"inner response",
+		"middle response",
+		"outer response",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/anGie44/go-animal-rescue/otelanimalrescue
+
+go 1.15
+
+require (
+	github.com/anGie44/go-animal-rescue v0.0.0
+	go.opentelemetry.io/otel v1.0.1
+	go.opentelemetry.io/otel/trace v1.0.1
+)
+
+replace github.com/anGie44/go-animal-rescue => ../
@@ -0,0 +1,118 @@
+// Package otelanimalrescue adapts OpenTelemetry tracing to the Tracer
+// interface of the animalrescue client:
+//
+//	client.Tracer = otelanimalrescue.NewTracer()
+//
+// It lives in its own module so that the animalrescue package does not
+// depend on OpenTelemetry.
+package otelanimalrescue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans started by the Tracer.
+const instrumentationName = "github.com/anGie44/go-animal-rescue/otelanimalrescue"
+
+// An Option configures a Tracer built by NewTracer.
+type Option func(*Tracer)
+
+// WithTracerProvider sets the provider of the OpenTelemetry tracer. By
+// default, the global provider is used.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(t *Tracer) {
+		t.provider = tp
+	}
+}
+
+// WithPropagators sets the propagators used to add the trace context to
+// request headers. By default, the global propagators are used.
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(t *Tracer) {
+		t.propagators = p
+	}
+}
+
+// Tracer implements animalrescue.Tracer with OpenTelemetry. Its spans are
+// client spans.
+type Tracer struct {
+	provider    trace.TracerProvider
+	propagators propagation.TextMapPropagator
+	tracer      trace.Tracer
+}
+
+// NewTracer returns a Tracer configured by opts.
+func NewTracer(opts ...Option) *Tracer {
+	t := &Tracer{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.provider == nil {
+		t.provider = otel.GetTracerProvider()
+	}
+	if t.propagators == nil {
+		t.propagators = otel.GetTextMapPropagator()
+	}
+	t.tracer = t.provider.Tracer(instrumentationName)
+	return t
+}
+
+// Start implements animalrescue.Tracer.
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...animalrescue.Attribute) (context.Context, animalrescue.Span) {
+	ctx, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(convert(attrs)...),
+	)
+	return ctx, &otelSpan{span}
+}
+
+// Inject implements animalrescue.Tracer.
+func (t *Tracer) Inject(ctx context.Context, h http.Header) {
+	t.propagators.Inject(ctx, propagation.HeaderCarrier(h))
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s *otelSpan) SetAttributes(attrs ...animalrescue.Attribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s *otelSpan) RecordError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+}
+
+// convert returns the OpenTelemetry equivalents of attrs.
+func convert(attrs []animalrescue.Attribute) []attribute.KeyValue {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		switch v := a.Value.(type) {
+		case string:
+			kvs[i] = attribute.String(a.Key, v)
+		case int:
+			kvs[i] = attribute.Int(a.Key, v)
+		case int64:
+			kvs[i] = attribute.Int64(a.Key, v)
+		case bool:
+			kvs[i] = attribute.Bool(a.Key, v)
+		default:
+			kvs[i] = attribute.String(a.Key, fmt.Sprint(v))
+		}
+	}
+	return kvs
+}
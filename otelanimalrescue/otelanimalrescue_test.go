@@ -0,0 +1,222 @@
+package otelanimalrescue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+	"github.com/anGie44/go-animal-rescue/otelanimalrescue"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordedSpan is a span started by fakeProvider. The embedded no-op span
+// provides the methods it does not record.
+type recordedSpan struct {
+	trace.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]attribute.Value
+	sc     trace.SpanContext
+	errs   []error
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.sc }
+func (s *recordedSpan) IsRecording() bool              { return !s.ended }
+func (s *recordedSpan) End(...trace.SpanEndOption)     { s.ended = true }
+
+func (s *recordedSpan) SetAttributes(kvs ...attribute.KeyValue) {
+	for _, kv := range kvs {
+		s.attrs[kv.Key] = kv.Value
+	}
+}
+
+func (s *recordedSpan) RecordError(err error, opts ...trace.EventOption) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, description string) {
+	s.status = code
+}
+
+// fakeProvider is an OpenTelemetry TracerProvider recording the spans
+// started by its tracers.
+type fakeProvider struct {
+	mu    sync.Mutex
+	names []string // instrumentation names of the tracers
+	spans []*recordedSpan
+}
+
+func (p *fakeProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.names = append(p.names, name)
+	return fakeTracer{p}
+}
+
+type fakeTracer struct {
+	p *fakeProvider
+}
+
+func (t fakeTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	t.p.mu.Lock()
+	defer t.p.mu.Unlock()
+	s := &recordedSpan{
+		Span:  trace.SpanFromContext(context.Background()),
+		name:  name,
+		kind:  cfg.SpanKind(),
+		attrs: make(map[attribute.Key]attribute.Value),
+		sc: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{byte(len(t.p.spans) + 1)},
+			TraceFlags: trace.FlagsSampled,
+		}),
+	}
+	s.SetAttributes(cfg.Attributes()...)
+	t.p.spans = append(t.p.spans, s)
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// newClient returns a client of srv tracing through a Tracer built on a
+// fakeProvider, and the traceparent headers of the requests it sent.
+func newClient(t *testing.T, srv *animalrescuetest.Server) (*animalrescue.Client, *fakeProvider, func() []string) {
+	t.Helper()
+	tp := &fakeProvider{}
+	var (
+		mu      sync.Mutex
+		headers []string
+	)
+	client, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithTracer(otelanimalrescue.NewTracer(
+			otelanimalrescue.WithTracerProvider(tp),
+			otelanimalrescue.WithPropagators(propagation.TraceContext{}),
+		)),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+				mu.Lock()
+				headers = append(headers, req.Header.Get("Traceparent"))
+				mu.Unlock()
+				return next.RoundTrip(req)
+			})
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, tp, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), headers...)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTracer(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tp, headers := newClient(t, srv)
+	ctx := context.Background()
+
+	adoptee, _, err := client.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Adoptees.GetAdopteeByID(ctx, int64(adoptee.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tp.names) != 1 || tp.names[0] != "github.com/anGie44/go-animal-rescue/otelanimalrescue" {
+		t.Errorf("tracers were created for %q, want the otelanimalrescue package", tp.names)
+	}
+	if len(tp.spans) != 2 {
+		t.Fatalf("provider recorded %d spans, want 2", len(tp.spans))
+	}
+	s := tp.spans[1]
+	if s.name != "Adoptees.GetAdopteeByID" || s.kind != trace.SpanKindClient || !s.ended {
+		t.Errorf("span is %q of kind %v, ended %v, want an ended client span named Adoptees.GetAdopteeByID", s.name, s.kind, s.ended)
+	}
+	want := map[attribute.Key]attribute.Value{
+		animalrescue.AttributeEntity:     attribute.StringValue("adoptee"),
+		animalrescue.AttributeEntityID:   attribute.Int64Value(int64(adoptee.ID)),
+		animalrescue.AttributeHTTPMethod: attribute.StringValue("GET"),
+		animalrescue.AttributeHTTPStatus: attribute.IntValue(http.StatusOK),
+		animalrescue.AttributeRetryCount: attribute.IntValue(0),
+	}
+	for k, v := range want {
+		if got, ok := s.attrs[k]; !ok || got != v {
+			t.Errorf("span attribute %v = %v, want %v", k, got.Emit(), v.Emit())
+		}
+	}
+
+	got := headers()
+	if len(got) != 2 {
+		t.Fatalf("%d requests were sent, want 2", len(got))
+	}
+	for i, h := range got {
+		sc := tp.spans[i].sc
+		if want := "00-" + sc.TraceID().String() + "-" + sc.SpanID().String() + "-01"; h != want {
+			t.Errorf("request %d has traceparent %q, want %q", i, h, want)
+		}
+	}
+}
+
+func TestTracer_recordsErrors(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tp, _ := newClient(t, srv)
+
+	_, _, err := client.Adopters.GetAdopterByID(context.Background(), 42)
+	if !errors.Is(err, animalrescue.ErrNotFound) {
+		t.Fatalf("GetAdopterByID returned %v, want ErrNotFound", err)
+	}
+	if len(tp.spans) != 1 {
+		t.Fatalf("provider recorded %d spans, want 1", len(tp.spans))
+	}
+	s := tp.spans[0]
+	if len(s.errs) != 1 || s.errs[0] != err || s.status != codes.Error {
+		t.Errorf("span recorded errors %v with status %v, want [%v] with status %v", s.errs, s.status, err, codes.Error)
+	}
+}
+
+func TestTracer_attributeTypes(t *testing.T) {
+	tp := &fakeProvider{}
+	tracer := otelanimalrescue.NewTracer(otelanimalrescue.WithTracerProvider(tp))
+
+	_, span := tracer.Start(context.Background(), "test",
+		animalrescue.Attribute{Key: "string", Value: "a"},
+		animalrescue.Attribute{Key: "int", Value: 1},
+		animalrescue.Attribute{Key: "int64", Value: int64(2)},
+		animalrescue.Attribute{Key: "bool", Value: true},
+		animalrescue.Attribute{Key: "other", Value: 1.5},
+	)
+	span.End()
+
+	want := map[attribute.Key]attribute.Value{
+		"string": attribute.StringValue("a"),
+		"int":    attribute.IntValue(1),
+		"int64":  attribute.Int64Value(2),
+		"bool":   attribute.BoolValue(true),
+		"other":  attribute.StringValue("1.5"),
+	}
+	attrs := tp.spans[0].attrs
+	for k, v := range want {
+		if got, ok := attrs[k]; !ok || got != v {
+			t.Errorf("attribute %v = %v, want %v", k, got.Emit(), v.Emit())
+		}
+	}
+}
@@ -12,10 +12,10 @@ type PetPreferencesService service
 // PetPreference represents a preference made by a prospective adopter in
 // an animal rescue.
 type PetPreference struct {
-	ID     int    `json:"id,omitempty"`
-	Breed  string `json:"breed,omitempty"`
-	Age    string `json:"age,omitempty"`
-	Gender string `json:"gender,omitempty"`
+	ID     int      `json:"id,omitempty"`
+	Breed  string   `json:"breed,omitempty"`
+	Age    AgeGroup `json:"age,omitempty"`
+	Gender Gender   `json:"gender,omitempty"`
 }
 
 func (pp PetPreference) String() string {
@@ -23,9 +23,16 @@ func (pp PetPreference) String() string {
 }
 
 // ListAll lists all of the pet-preferences within an animal rescue.
-func (s *PetPreferencesService) ListAll(ctx context.Context) ([]*PetPreference, *Response, error) {
-	u := "petprefs"
-	req, err := s.client.NewRequest("GET", u, nil)
+func (s *PetPreferencesService) ListAll(ctx context.Context, opts *ListOptions, reqOpts ...RequestOption) ([]*PetPreference, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "PetPreferences.ListAll", "pet_preference", 0)
+	defer span.End()
+
+	u, err := addOptions("petprefs", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,10 +44,47 @@ func (s *PetPreferencesService) ListAll(ctx context.Context) ([]*PetPreference,
 	return pp, resp, nil
 }
 
+// PetPreferenceIterator iterates over the pet-preferences of an animal rescue, fetching
+// pages of results from the API as they are needed.
+type PetPreferenceIterator struct {
+	iterator
+}
+
+// Value returns the pet-preference at the current position of the iterator.
+func (it *PetPreferenceIterator) Value() *PetPreference {
+	v, _ := it.cur.(*PetPreference)
+	return v
+}
+
+// WithPrefetch makes the iterator fetch the next page of results in the
+// background while the current one is consumed. It must be called before
+// the first call to Next.
+func (it *PetPreferenceIterator) WithPrefetch() *PetPreferenceIterator {
+	it.prefetch = true
+	return it
+}
+
+// Iter returns an iterator over all of the pet-preferences for an animal rescue,
+// starting from the page described by opts. Iteration stops when ctx is
+// canceled.
+func (s *PetPreferencesService) Iter(ctx context.Context, opts *ListOptions, reqOpts ...RequestOption) *PetPreferenceIterator {
+	return &PetPreferenceIterator{newIterator(ctx, opts, func(ctx context.Context, opts *ListOptions) ([]interface{}, *Response, error) {
+		pp, resp, err := s.ListAll(ctx, opts, reqOpts...)
+		items := make([]interface{}, len(pp))
+		for i, v := range pp {
+			items[i] = v
+		}
+		return items, resp, err
+	})}
+}
+
 // GetPetPreferenceByID fetches a pet-preference by ID.
-func (s *PetPreferencesService) GetPetPreferenceByID(ctx context.Context, ppID int64) (*PetPreference, *Response, error) {
+func (s *PetPreferencesService) GetPetPreferenceByID(ctx context.Context, ppID int64, reqOpts ...RequestOption) (*PetPreference, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "PetPreferences.GetPetPreferenceByID", "pet_preference", ppID)
+	defer span.End()
+
 	u := fmt.Sprintf("petpref/%v", ppID)
-	req, err := s.client.NewRequest("GET", u, nil)
+	req, err := s.client.NewRequest("GET", u, nil, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -55,15 +99,21 @@ func (s *PetPreferencesService) GetPetPreferenceByID(ctx context.Context, ppID i
 
 // NewPetPreference represents a pet-preference to be created or modified.
 type NewPetPreference struct {
-	Breed  string `json:"breed,omitempty"`
-	Age    string `json:"age,omitempty"`
-	Gender string `json:"gender,omitempty"`
+	Breed  string   `json:"breed,omitempty"`
+	Age    AgeGroup `json:"age,omitempty"`
+	Gender Gender   `json:"gender,omitempty"`
 }
 
 // CreatePetPreference creates a new pet-preference within an animal rescue.
-func (s *PetPreferencesService) CreatePetPreference(ctx context.Context, pp NewPetPreference) (*PetPreference, *Response, error) {
+func (s *PetPreferencesService) CreatePetPreference(ctx context.Context, pp NewPetPreference, reqOpts ...RequestOption) (*PetPreference, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "PetPreferences.CreatePetPreference", "pet_preference", 0)
+	defer span.End()
+
 	u := "petprefs"
-	req, err := s.client.NewRequest("POST", u, pp)
+	if err := s.client.validate(ctx, pp); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("POST", u, pp, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -78,9 +128,15 @@ func (s *PetPreferencesService) CreatePetPreference(ctx context.Context, pp NewP
 }
 
 // EditPetPreferenceByID edits a pet-preference selected by ID.
-func (s *PetPreferencesService) EditPetPreferenceByID(ctx context.Context, ppID int64, pp NewPetPreference) (*PetPreference, *Response, error) {
+func (s *PetPreferencesService) EditPetPreferenceByID(ctx context.Context, ppID int64, pp NewPetPreference, reqOpts ...RequestOption) (*PetPreference, *Response, error) {
+	ctx, span := s.client.startSpan(ctx, "PetPreferences.EditPetPreferenceByID", "pet_preference", ppID)
+	defer span.End()
+
 	u := fmt.Sprintf("petpref/%v", ppID)
-	req, err := s.client.NewRequest("PATCH", u, pp)
+	if err := s.client.validate(ctx, pp); err != nil {
+		return nil, nil, err
+	}
+	req, err := s.client.NewRequest("PATCH", u, pp, reqOpts...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -94,11 +150,70 @@ func (s *PetPreferencesService) EditPetPreferenceByID(ctx context.Context, ppID
 }
 
 // DeletePetPreferenceByID deletes a pet-preference referenced by ID.
-func (s *PetPreferencesService) DeletePetPreferenceByID(ctx context.Context, ppID int64) (*Response, error) {
+func (s *PetPreferencesService) DeletePetPreferenceByID(ctx context.Context, ppID int64, reqOpts ...RequestOption) (*Response, error) {
+	ctx, span := s.client.startSpan(ctx, "PetPreferences.DeletePetPreferenceByID", "pet_preference", ppID)
+	defer span.End()
+
 	u := fmt.Sprintf("petpref/%v", ppID)
-	req, err := s.client.NewRequest("DELETE", u, nil)
+	req, err := s.client.NewRequest("DELETE", u, nil, reqOpts...)
 	if err != nil {
 		return nil, err
 	}
 	return s.client.Do(ctx, req, nil)
 }
+
+// PetPreferenceResult is the outcome of the item at Index of a CreateMany or
+// EditMany call on the PetPreferencesService.
+type PetPreferenceResult struct {
+	Index         int
+	PetPreference *PetPreference
+	Response      *Response
+	Err           error
+}
+
+// CreateMany creates each of pp, sending up to opts.Concurrency requests at
+// once. The result at index i reports the outcome of pp[i].
+func (s *PetPreferencesService) CreateMany(ctx context.Context, pp []NewPetPreference, opts BatchOptions, reqOpts ...RequestOption) []PetPreferenceResult {
+	results := make([]PetPreferenceResult, len(pp))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(pp), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.PetPreference, r.Response, r.Err = s.CreatePetPreference(ctx, pp[i], reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// PetPreferenceEdit pairs a pet-preference ID with the edits to apply to it.
+type PetPreferenceEdit struct {
+	ID            int64
+	PetPreference NewPetPreference
+}
+
+// EditMany applies each of edits, sending up to opts.Concurrency requests at
+// once. The result at index i reports the outcome of edits[i].
+func (s *PetPreferencesService) EditMany(ctx context.Context, edits []PetPreferenceEdit, opts BatchOptions, reqOpts ...RequestOption) []PetPreferenceResult {
+	results := make([]PetPreferenceResult, len(edits))
+	for i := range results {
+		results[i].Index = i
+	}
+	runBatch(ctx, len(edits), opts, func(ctx context.Context, i int) error {
+		r := &results[i]
+		r.PetPreference, r.Response, r.Err = s.EditPetPreferenceByID(ctx, edits[i].ID, edits[i].PetPreference, reqOpts...)
+		return r.Err
+	}, func(i int, err error) {
+		results[i].Err = err
+	})
+	return results
+}
+
+// DeleteMany deletes the pet-preferences referenced by ids, sending up to
+// opts.Concurrency requests at once. The result at index i reports the
+// outcome of deleting ids[i].
+func (s *PetPreferencesService) DeleteMany(ctx context.Context, ids []int64, opts BatchOptions, reqOpts ...RequestOption) []DeleteResult {
+	return deleteMany(ctx, ids, opts, s.DeletePetPreferenceByID, reqOpts)
+}
@@ -0,0 +1,149 @@
+package animalrescue
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRateLimit     = "X-RateLimit-Limit"
+	headerRateRemaining = "X-RateLimit-Remaining"
+	headerRateReset     = "X-RateLimit-Reset"
+	headerRetryAfter    = "Retry-After"
+)
+
+// Rate represents the rate limit for the current client.
+type Rate struct {
+	// The number of requests per hour the client is currently limited to.
+	Limit int `json:"limit"`
+
+	// The number of remaining requests the client can make this hour.
+	Remaining int `json:"remaining"`
+
+	// The time at which the current rate limit will reset.
+	Reset Timestamp `json:"reset"`
+
+	// RetryAfter is the delay requested by a Retry-After header, if any.
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+func (r Rate) String() string {
+	return Stringify(r)
+}
+
+// parseRate parses the rate related headers.
+func parseRate(r *http.Response) Rate {
+	var rate Rate
+	if limit := r.Header.Get(headerRateLimit); limit != "" {
+		rate.Limit, _ = strconv.Atoi(limit)
+	}
+	if remaining := r.Header.Get(headerRateRemaining); remaining != "" {
+		rate.Remaining, _ = strconv.Atoi(remaining)
+	}
+	if reset := r.Header.Get(headerRateReset); reset != "" {
+		if v, _ := strconv.ParseInt(reset, 10, 64); v != 0 {
+			rate.Reset = Timestamp{time.Unix(v, 0)}
+		}
+	}
+	if after, ok := parseRetryAfter(r.Header.Get(headerRetryAfter)); ok {
+		rate.RetryAfter = after
+	}
+	return rate
+}
+
+// RateLimitError occurs when the API returns 429 Too Many Requests because
+// the client has used up its rate limit.
+type RateLimitError struct {
+	Rate     Rate           // Rate specifies last known rate limit for the client
+	Response *http.Response // HTTP response that caused this error
+	Message  string         `json:"message"` // error message
+}
+
+func (r *RateLimitError) Error() string {
+	return fmt.Sprintf("%v%v; rate reset in %v",
+		describeResponse(r.Response), r.Message, time.Until(r.Rate.Reset.Time))
+}
+
+// AbuseRateLimitError occurs when the API returns 429 Too Many Requests
+// without the client having exhausted its rate limit, meaning it tripped
+// the API's abuse detection by sending too many requests in a short burst.
+type AbuseRateLimitError struct {
+	Response *http.Response // HTTP response that caused this error
+	Message  string         `json:"message"` // error message
+
+	// RetryAfter is provided with some abuse rate limit errors. If present,
+	// it is the amount of time that the client should wait before retrying.
+	// Otherwise, the client should try again later (after an unspecified
+	// amount of time).
+	RetryAfter *time.Duration
+}
+
+func (r *AbuseRateLimitError) Error() string {
+	return describeResponse(r.Response) + r.Message
+}
+
+// describeResponse returns the method and redacted URL of the request resp
+// answered, followed by its status, to prefix error messages. Whatever is
+// missing from resp is left out.
+func describeResponse(resp *http.Response) string {
+	switch {
+	case resp == nil:
+		return ""
+	case resp.Request == nil || resp.Request.URL == nil:
+		return fmt.Sprintf("%d ", resp.StatusCode)
+	}
+	return fmt.Sprintf("%v %v: %d ", resp.Request.Method, RedactURL(resp.Request.URL), resp.StatusCode)
+}
+
+// Rate returns the rate limit reported by the most recent API response.
+func (c *Client) Rate() Rate {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return c.rate
+}
+
+// updateRate records the rate limit reported by resp, along with the time
+// until which requests should be held back when WaitForRateLimit is set.
+func (c *Client) updateRate(resp *Response) {
+	h := resp.Header
+	if h.Get(headerRateRemaining) == "" && h.Get(headerRetryAfter) == "" {
+		return
+	}
+
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	if h.Get(headerRateRemaining) != "" {
+		c.rate = resp.Rate
+		if resp.Rate.Remaining == 0 {
+			c.rateBlockedUntil = resp.Rate.Reset.Time
+		}
+	}
+	if resp.Rate.RetryAfter > 0 {
+		if until := time.Now().Add(resp.Rate.RetryAfter); until.After(c.rateBlockedUntil) {
+			c.rateBlockedUntil = until
+		}
+	}
+}
+
+// waitForRateLimit blocks until the last known rate limit has reset, or ctx
+// is done.
+func (c *Client) waitForRateLimit(ctx context.Context) error {
+	c.rateMu.Lock()
+	wait := time.Until(c.rateBlockedUntil)
+	c.rateMu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
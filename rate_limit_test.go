@@ -0,0 +1,133 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestCheckResponse_rateLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		remaining  string
+		retryAfter string
+		abuse      bool
+		wantAfter  time.Duration // RetryAfter of abuse errors, if any
+	}{
+		{name: "exhausted", remaining: "0"},
+		{name: "exhausted with Retry-After", remaining: "0", retryAfter: "30"},
+		{name: "abuse", remaining: "12", abuse: true},
+		{name: "abuse with Retry-After", remaining: "12", retryAfter: "30", abuse: true, wantAfter: 30 * time.Second},
+		{name: "abuse without budget headers", retryAfter: "5", abuse: true, wantAfter: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message":"slow down"}`)),
+		}
+		if tt.remaining != "" {
+			resp.Header.Set("X-RateLimit-Remaining", tt.remaining)
+		}
+		if tt.retryAfter != "" {
+			resp.Header.Set("Retry-After", tt.retryAfter)
+		}
+		err := animalrescue.CheckResponse(resp)
+
+		var (
+			rerr *animalrescue.RateLimitError
+			aerr *animalrescue.AbuseRateLimitError
+		)
+		switch {
+		case tt.abuse && errors.As(err, &aerr):
+			if aerr.Message != "slow down" {
+				t.Errorf("%v: Message = %q", tt.name, aerr.Message)
+			}
+			if tt.wantAfter == 0 && aerr.RetryAfter != nil {
+				t.Errorf("%v: RetryAfter = %v, want nil", tt.name, *aerr.RetryAfter)
+			}
+			if tt.wantAfter != 0 && (aerr.RetryAfter == nil || *aerr.RetryAfter != tt.wantAfter) {
+				t.Errorf("%v: RetryAfter = %v, want %v", tt.name, aerr.RetryAfter, tt.wantAfter)
+			}
+		case !tt.abuse && errors.As(err, &rerr):
+			if rerr.Message != "slow down" || rerr.Rate.Remaining != 0 {
+				t.Errorf("%v: returned %+v", tt.name, rerr)
+			}
+		default:
+			t.Errorf("%v: CheckResponse returned %T %v, want abuse %v", tt.name, err, err, tt.abuse)
+		}
+	}
+}
+
+func TestClient_rateLimit(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	srv.SetRateLimit(2, time.Second)
+	c := srv.Client()
+	ctx := context.Background()
+
+	var rate animalrescue.Rate
+	for i := 0; i < 2; i++ {
+		_, resp, err := c.Adopters.ListAll(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rate = resp.Rate
+	}
+	if rate.Limit != 2 || rate.Remaining != 0 || c.Rate() != rate {
+		t.Fatalf("rate after 2 requests is %v (client: %v), want the budget used up", rate, c.Rate())
+	}
+
+	_, _, err := c.Adopters.ListAll(ctx, nil)
+	var rerr *animalrescue.RateLimitError
+	if !errors.As(err, &rerr) || !rerr.Rate.Reset.Equal(rate.Reset) {
+		t.Fatalf("request over the limit returned %v, want a *RateLimitError until %v", err, rate.Reset)
+	}
+}
+
+func TestClient_WaitForRateLimit(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	srv.SetRateLimit(1, time.Second)
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithWaitForRateLimit(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	_, resp, err := c.Adopters.ListAll(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reset := resp.Rate.Reset
+
+	// A request sent before the reset would fail.
+	if _, _, err := c.Adopters.ListAll(ctx, nil); err != nil {
+		t.Fatalf("request after the budget was used up returned error: %v", err)
+	}
+	if now := time.Now(); now.Before(reset.Time) {
+		t.Errorf("request was sent at %v, before the rate limit reset at %v", now, reset)
+	}
+
+	// Waits end with the context.
+	srv.SetRateLimit(1, time.Hour)
+	if _, _, err := c.Adopters.ListAll(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := c.Adopters.ListAll(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf("request waiting past its deadline returned %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,66 @@
+package animalrescue
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   Rate
+	}{
+		{"none", nil, Rate{}},
+		{
+			"budget",
+			map[string]string{headerRateLimit: "60", headerRateRemaining: "59", headerRateReset: "1588336200"},
+			Rate{Limit: 60, Remaining: 59, Reset: Timestamp{time.Unix(1588336200, 0)}},
+		},
+		{"retry after", map[string]string{headerRetryAfter: "30"}, Rate{RetryAfter: 30 * time.Second}},
+		{
+			"malformed",
+			map[string]string{headerRateLimit: "lots", headerRateRemaining: "-", headerRateReset: "soon", headerRetryAfter: "later"},
+			Rate{},
+		},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		for k, v := range tt.header {
+			resp.Header.Set(k, v)
+		}
+		got := parseRate(resp)
+		if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) || got.RetryAfter != tt.want.RetryAfter {
+			t.Errorf("%v: parseRate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimitErrors_Error(t *testing.T) {
+	req := &http.Request{Method: "GET", URL: &url.URL{Scheme: "https", Host: "api.example.com", Path: "/adopters", RawQuery: "email=jane%40example.com"}}
+	tests := []struct {
+		name string
+		resp *http.Response
+		want string // prefix of the messages
+	}{
+		{"with request", &http.Response{StatusCode: 429, Request: req}, "GET https://api.example.com/adopters?email=%5BREDACTED%5D: 429 slow down"},
+		{"without request", &http.Response{StatusCode: 429}, "429 slow down"},
+		{"without response", nil, "slow down"},
+	}
+
+	for _, tt := range tests {
+		errs := []error{
+			&RateLimitError{Response: tt.resp, Message: "slow down"},
+			&AbuseRateLimitError{Response: tt.resp, Message: "slow down"},
+		}
+		for _, err := range errs {
+			if got := err.Error(); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("%v: %T.Error() = %q, want prefix %q", tt.name, err, got, tt.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package replica
+
+import (
+	"context"
+	"encoding/json"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/watch"
+)
+
+// Collection names, as used in Mutation.Collection.
+const (
+	CollectionAdopters       = "adopters"
+	CollectionAdoptees       = "adoptees"
+	CollectionAdoptions      = "adoptions"
+	CollectionPetPreferences = "petprefs"
+)
+
+// collectionOrder lists the collections in the order Sync pulls them:
+// entities before the adoptions referring to them.
+var collectionOrder = []string{
+	CollectionAdopters,
+	CollectionAdoptees,
+	CollectionPetPreferences,
+	CollectionAdoptions,
+}
+
+// A collection binds one of the client's services to the replica. Entities
+// and the bodies of mutations are handled as JSON, so that the replica can
+// treat every collection alike; entity returns a pointer to a new entity of
+// the collection to decode them into.
+type collection struct {
+	name   string
+	entity func() interface{}
+	source func(c *animalrescue.Client) watch.Source
+	get    func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error)
+	create func(ctx context.Context, c *animalrescue.Client, data []byte) (interface{}, error)
+	edit   func(ctx context.Context, c *animalrescue.Client, id int64, data []byte) (interface{}, error)
+	delete func(ctx context.Context, c *animalrescue.Client, id int64) error
+}
+
+var collections = map[string]*collection{
+	CollectionAdopters: {
+		name: CollectionAdopters,
+		entity: func() interface{} {
+			return new(animalrescue.Adopter)
+		},
+		source: func(c *animalrescue.Client) watch.Source {
+			return watch.Adopters(c, nil)
+		},
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adopters.GetAdopterByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, data []byte) (interface{}, error) {
+			var v animalrescue.NewAdopter
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			a, _, err := c.Adopters.CreateAdopter(ctx, v)
+			return a, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, data []byte) (interface{}, error) {
+			var v animalrescue.NewAdopter
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			a, _, err := c.Adopters.EditAdopterByID(ctx, id, v)
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adopters.DeleteAdopterByID(ctx, id)
+			return err
+		},
+	},
+	CollectionAdoptees: {
+		name: CollectionAdoptees,
+		entity: func() interface{} {
+			return new(animalrescue.Adoptee)
+		},
+		source: func(c *animalrescue.Client) watch.Source {
+			return watch.Adoptees(c, nil)
+		},
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adoptees.GetAdopteeByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, data []byte) (interface{}, error) {
+			var v animalrescue.NewAdoptee
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			a, _, err := c.Adoptees.CreateAdoptee(ctx, v)
+			return a, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, data []byte) (interface{}, error) {
+			var v animalrescue.NewAdoptee
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			a, _, err := c.Adoptees.EditAdopteeByID(ctx, id, v)
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adoptees.DeleteAdopteeByID(ctx, id)
+			return err
+		},
+	},
+	CollectionAdoptions: {
+		name: CollectionAdoptions,
+		entity: func() interface{} {
+			return new(animalrescue.Adoption)
+		},
+		source: func(c *animalrescue.Client) watch.Source {
+			return watch.Adoptions(c, nil)
+		},
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			a, _, err := c.Adoptions.GetAdoptionByID(ctx, id)
+			return a, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, data []byte) (interface{}, error) {
+			var v animalrescue.NewAdoption
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			a, _, err := c.Adoptions.CreateAdoption(ctx, v)
+			return a, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.Adoptions.DeleteAdoptionByID(ctx, id)
+			return err
+		},
+	},
+	CollectionPetPreferences: {
+		name: CollectionPetPreferences,
+		entity: func() interface{} {
+			return new(animalrescue.PetPreference)
+		},
+		source: func(c *animalrescue.Client) watch.Source {
+			return watch.PetPreferences(c, nil)
+		},
+		get: func(ctx context.Context, c *animalrescue.Client, id int64) (interface{}, error) {
+			pp, _, err := c.PetPreferences.GetPetPreferenceByID(ctx, id)
+			return pp, err
+		},
+		create: func(ctx context.Context, c *animalrescue.Client, data []byte) (interface{}, error) {
+			var v animalrescue.NewPetPreference
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			pp, _, err := c.PetPreferences.CreatePetPreference(ctx, v)
+			return pp, err
+		},
+		edit: func(ctx context.Context, c *animalrescue.Client, id int64, data []byte) (interface{}, error) {
+			var v animalrescue.NewPetPreference
+			if err := json.Unmarshal(data, &v); err != nil {
+				return nil, err
+			}
+			pp, _, err := c.PetPreferences.EditPetPreferenceByID(ctx, id, v)
+			return pp, err
+		},
+		delete: func(ctx context.Context, c *animalrescue.Client, id int64) error {
+			_, err := c.PetPreferences.DeletePetPreferenceByID(ctx, id)
+			return err
+		},
+	},
+}
@@ -0,0 +1,234 @@
+package replica
+
+import (
+	"context"
+	"encoding/json"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Adopters returns the adopters in the replica, ordered by ID with those created
+// offline last.
+func (r *Replica) Adopters() []*animalrescue.Adopter {
+	var all []*animalrescue.Adopter
+	r.all(CollectionAdopters, func(data []byte) error {
+		v := new(animalrescue.Adopter)
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		all = append(all, v)
+		return nil
+	})
+	return all
+}
+
+// Adopter returns the adopter with the given ID, reporting whether it is in the
+// replica.
+func (r *Replica) Adopter(id int64) (*animalrescue.Adopter, bool) {
+	v := new(animalrescue.Adopter)
+	if !r.lookup(CollectionAdopters, id, v) {
+		return nil, false
+	}
+	return v, true
+}
+
+// CreateAdopter creates an adopter, or queues its creation and gives it a local ID
+// if the API cannot be reached.
+func (r *Replica) CreateAdopter(ctx context.Context, v animalrescue.NewAdopter) (*animalrescue.Adopter, error) {
+	data, err := r.mutate(ctx, CollectionAdopters, OpCreate, 0, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.Adopter)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// EditAdopterByID edits the adopter with the given ID, or queues the edit if the
+// API cannot be reached.
+func (r *Replica) EditAdopterByID(ctx context.Context, id int64, v animalrescue.NewAdopter) (*animalrescue.Adopter, error) {
+	data, err := r.mutate(ctx, CollectionAdopters, OpEdit, id, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.Adopter)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// DeleteAdopterByID deletes the adopter with the given ID, or queues the deletion
+// if the API cannot be reached.
+func (r *Replica) DeleteAdopterByID(ctx context.Context, id int64) error {
+	_, err := r.mutate(ctx, CollectionAdopters, OpDelete, id, nil)
+	return err
+}
+
+// Adoptees returns the adoptees in the replica, ordered by ID with those created
+// offline last.
+func (r *Replica) Adoptees() []*animalrescue.Adoptee {
+	var all []*animalrescue.Adoptee
+	r.all(CollectionAdoptees, func(data []byte) error {
+		v := new(animalrescue.Adoptee)
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		all = append(all, v)
+		return nil
+	})
+	return all
+}
+
+// Adoptee returns the adoptee with the given ID, reporting whether it is in the
+// replica.
+func (r *Replica) Adoptee(id int64) (*animalrescue.Adoptee, bool) {
+	v := new(animalrescue.Adoptee)
+	if !r.lookup(CollectionAdoptees, id, v) {
+		return nil, false
+	}
+	return v, true
+}
+
+// CreateAdoptee creates an adoptee, or queues its creation and gives it a local ID
+// if the API cannot be reached.
+func (r *Replica) CreateAdoptee(ctx context.Context, v animalrescue.NewAdoptee) (*animalrescue.Adoptee, error) {
+	data, err := r.mutate(ctx, CollectionAdoptees, OpCreate, 0, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.Adoptee)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// EditAdopteeByID edits the adoptee with the given ID, or queues the edit if the
+// API cannot be reached.
+func (r *Replica) EditAdopteeByID(ctx context.Context, id int64, v animalrescue.NewAdoptee) (*animalrescue.Adoptee, error) {
+	data, err := r.mutate(ctx, CollectionAdoptees, OpEdit, id, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.Adoptee)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// DeleteAdopteeByID deletes the adoptee with the given ID, or queues the deletion
+// if the API cannot be reached.
+func (r *Replica) DeleteAdopteeByID(ctx context.Context, id int64) error {
+	_, err := r.mutate(ctx, CollectionAdoptees, OpDelete, id, nil)
+	return err
+}
+
+// Adoptions returns the adoptions in the replica, ordered by ID with those created
+// offline last.
+func (r *Replica) Adoptions() []*animalrescue.Adoption {
+	var all []*animalrescue.Adoption
+	r.all(CollectionAdoptions, func(data []byte) error {
+		v := new(animalrescue.Adoption)
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		all = append(all, v)
+		return nil
+	})
+	return all
+}
+
+// Adoption returns the adoption with the given ID, reporting whether it is in the
+// replica.
+func (r *Replica) Adoption(id int64) (*animalrescue.Adoption, bool) {
+	v := new(animalrescue.Adoption)
+	if !r.lookup(CollectionAdoptions, id, v) {
+		return nil, false
+	}
+	return v, true
+}
+
+// CreateAdoption creates an adoption, or queues its creation and gives it a local ID
+// if the API cannot be reached.
+func (r *Replica) CreateAdoption(ctx context.Context, v animalrescue.NewAdoption) (*animalrescue.Adoption, error) {
+	data, err := r.mutate(ctx, CollectionAdoptions, OpCreate, 0, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.Adoption)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// DeleteAdoptionByID deletes the adoption with the given ID, or queues the deletion
+// if the API cannot be reached.
+func (r *Replica) DeleteAdoptionByID(ctx context.Context, id int64) error {
+	_, err := r.mutate(ctx, CollectionAdoptions, OpDelete, id, nil)
+	return err
+}
+
+// PetPreferences returns the pet preferences in the replica, ordered by ID with those created
+// offline last.
+func (r *Replica) PetPreferences() []*animalrescue.PetPreference {
+	var all []*animalrescue.PetPreference
+	r.all(CollectionPetPreferences, func(data []byte) error {
+		v := new(animalrescue.PetPreference)
+		if err := json.Unmarshal(data, v); err != nil {
+			return err
+		}
+		all = append(all, v)
+		return nil
+	})
+	return all
+}
+
+// PetPreference returns the pet preference with the given ID, reporting whether it is in the
+// replica.
+func (r *Replica) PetPreference(id int64) (*animalrescue.PetPreference, bool) {
+	v := new(animalrescue.PetPreference)
+	if !r.lookup(CollectionPetPreferences, id, v) {
+		return nil, false
+	}
+	return v, true
+}
+
+// CreatePetPreference creates a pet preference, or queues its creation and gives it a local ID
+// if the API cannot be reached.
+func (r *Replica) CreatePetPreference(ctx context.Context, v animalrescue.NewPetPreference) (*animalrescue.PetPreference, error) {
+	data, err := r.mutate(ctx, CollectionPetPreferences, OpCreate, 0, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.PetPreference)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// EditPetPreferenceByID edits the pet preference with the given ID, or queues the edit if the
+// API cannot be reached.
+func (r *Replica) EditPetPreferenceByID(ctx context.Context, id int64, v animalrescue.NewPetPreference) (*animalrescue.PetPreference, error) {
+	data, err := r.mutate(ctx, CollectionPetPreferences, OpEdit, id, v)
+	if data == nil {
+		return nil, err
+	}
+	res := new(animalrescue.PetPreference)
+	if uerr := json.Unmarshal(data, res); uerr != nil {
+		return nil, uerr
+	}
+	return res, err
+}
+
+// DeletePetPreferenceByID deletes the pet preference with the given ID, or queues the deletion
+// if the API cannot be reached.
+func (r *Replica) DeletePetPreferenceByID(ctx context.Context, id int64) error {
+	_, err := r.mutate(ctx, CollectionPetPreferences, OpDelete, id, nil)
+	return err
+}
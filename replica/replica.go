@@ -0,0 +1,426 @@
+// Package replica keeps a local, file-backed copy of the entities of an
+// Animal Rescue API organization, for use without connectivity.
+//
+// A Replica answers reads from its local store. Mutations are sent to the
+// API right away when it can be reached, and otherwise applied to the local
+// store and queued. Sync replays the queued mutations in order, resolving
+// conflicts with a Policy, then brings the local store up to date with the
+// API:
+//
+//	r, err := replica.Open("rescue.json", client)
+//	if err != nil {
+//		return err
+//	}
+//	a, err := r.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+//	// a has a negative, local ID until the creation is replayed
+//	...
+//	res, err := r.Sync(ctx)
+//
+// Entities created while offline are given negative IDs. Once their
+// creation is replayed, later queued mutations referring to them are
+// rewritten to use the ID assigned by the API.
+//
+// Sync is not incremental: the API offers no way to list only the entities
+// changed since a given time, so every Sync lists every collection in full,
+// adopters first, then adoptees, pet preferences and adoptions. Give the
+// client a cache with animalrescue.WithCache to have the pages of results
+// that did not change since the previous Sync revalidated with their ETag
+// instead of downloaded again.
+package replica
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// ErrNotPersisted is matched by the errors of mutations and Syncs that
+// changed the API but could not save the change to the replica file. The
+// change is kept in memory and saved by the next change that succeeds: it
+// must not be made again, as the API already holds it.
+var ErrNotPersisted = errors.New("replica: change made on the API but not saved")
+
+// notPersisted reports the failure, with err, to save a change already made
+// on the API.
+type notPersisted struct {
+	err error
+}
+
+func (e *notPersisted) Error() string {
+	return ErrNotPersisted.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is ErrNotPersisted.
+func (e *notPersisted) Is(target error) bool {
+	return target == ErrNotPersisted
+}
+
+func (e *notPersisted) Unwrap() error {
+	return e.err
+}
+
+// Op is the kind of a queued mutation.
+type Op string
+
+// Mutation kinds.
+const (
+	OpCreate Op = "create"
+	OpEdit   Op = "edit"
+	OpDelete Op = "delete"
+)
+
+// A Mutation is a change made while the API could not be reached, queued
+// to be replayed by Sync.
+type Mutation struct {
+	Seq        int64  `json:"seq"`
+	Collection string `json:"collection"`
+	Op         Op     `json:"op"`
+
+	// ID is the ID of the entity changed, or the local ID given to the
+	// entity created.
+	ID int64 `json:"id"`
+
+	// Data is the JSON body of the create or edit request.
+	Data json.RawMessage `json:"data,omitempty"`
+
+	// Base is the entity as the replica knew it when the mutation was
+	// queued, for edits and deletes. Sync reports a conflict if the
+	// entity has changed on the server since.
+	Base json.RawMessage `json:"base,omitempty"`
+
+	QueuedAt animalrescue.Timestamp `json:"queued_at"`
+}
+
+// state is the content of the replica file.
+type state struct {
+	Collections map[string]map[string]json.RawMessage `json:"collections"`
+	Queue       []*Mutation                           `json:"queue"`
+	NextSeq     int64                                 `json:"next_seq"`
+	NextLocalID int64                                 `json:"next_local_id"`
+	SyncedAt    *animalrescue.Timestamp               `json:"synced_at,omitempty"`
+}
+
+// Replica is a local copy of the entities of the API. Its methods may be
+// called concurrently. Reads are never held up by requests to the API;
+// mutations wait for those of the mutations before them, and for Sync, so
+// that they reach the API in order.
+type Replica struct {
+	client *animalrescue.Client
+	path   string
+
+	// Policy resolves the conflicts met by Sync.
+	// Default: LastWriterWins.
+	Policy Policy
+
+	// sendMu is held while mutations are sent to the API, to keep them in
+	// order, and by Sync. It is acquired before mu, which guards st and is
+	// never held during requests.
+	sendMu sync.Mutex
+
+	mu sync.Mutex
+	st state
+}
+
+// Open returns a Replica stored in the file at path, which is created by
+// the first change if it does not exist. The replica is empty until it is
+// first synced.
+func Open(path string, client *animalrescue.Client) (*Replica, error) {
+	r := &Replica{client: client, path: path}
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &r.st); err != nil {
+			return nil, fmt.Errorf("replica: reading %v: %v", path, err)
+		}
+	}
+	if r.st.Collections == nil {
+		r.st.Collections = make(map[string]map[string]json.RawMessage)
+	}
+	for name := range collections {
+		if r.st.Collections[name] == nil {
+			r.st.Collections[name] = make(map[string]json.RawMessage)
+		}
+	}
+	return r, nil
+}
+
+// save writes the state to the replica file, replacing it atomically. r.mu
+// must be held.
+func (r *Replica) save() error {
+	data, err := json.Marshal(&r.st)
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(r.path), filepath.Base(r.path)+".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), r.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Pending returns copies of the mutations waiting to be replayed, oldest
+// first.
+func (r *Replica) Pending() []*Mutation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pending := make([]*Mutation, len(r.st.Queue))
+	for i, m := range r.st.Queue {
+		m := *m
+		pending[i] = &m
+	}
+	return pending
+}
+
+// SyncedAt returns the time of the last complete Sync, or the zero time if
+// the replica was never synced.
+func (r *Replica) SyncedAt() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.st.SyncedAt == nil {
+		return time.Time{}
+	}
+	return r.st.SyncedAt.Time
+}
+
+// lookup decodes the entity with the given ID into v, reporting whether it
+// exists.
+func (r *Replica) lookup(coll string, id int64, v interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.st.Collections[coll][strconv.FormatInt(id, 10)]
+	return ok && json.Unmarshal(data, v) == nil
+}
+
+// all calls add with every entity of the collection, ordered by ID with
+// entities created offline last.
+func (r *Replica) all(coll string, add func(data []byte) error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]int64, 0, len(r.st.Collections[coll]))
+	for key := range r.st.Collections[coll] {
+		id, _ := strconv.ParseInt(key, 10, 64)
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if (ids[i] < 0) != (ids[j] < 0) {
+			return ids[j] < 0
+		}
+		if ids[i] < 0 {
+			return ids[i] > ids[j]
+		}
+		return ids[i] < ids[j]
+	})
+	for _, id := range ids {
+		add(r.st.Collections[coll][strconv.FormatInt(id, 10)])
+	}
+}
+
+// mutate performs a mutation: online if nothing is queued and the API can
+// be reached, and otherwise by queueing it and applying it locally. It
+// returns the JSON of the resulting entity, or nil for deletes, along with
+// an error matching ErrNotPersisted if the mutation was made online but
+// could not be saved.
+func (r *Replica) mutate(ctx context.Context, coll string, op Op, id int64, body interface{}) (json.RawMessage, error) {
+	var data json.RawMessage
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	m := &Mutation{Collection: coll, Op: op, ID: id, Data: data}
+
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	// Mutations must reach the API in order, so nothing is sent directly
+	// while others are queued, nor for entities that only exist locally.
+	// The queue only grows while sendMu is held, so it cannot change
+	// while m is sent.
+	r.mu.Lock()
+	queued := len(r.st.Queue) > 0
+	r.mu.Unlock()
+	if !queued && id >= 0 {
+		v, err := r.send(ctx, m)
+		switch {
+		case err == nil:
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			entity, err := r.store(coll, op, id, v)
+			if err != nil {
+				return nil, &notPersisted{err}
+			}
+			if err := r.save(); err != nil {
+				return entity, &notPersisted{err}
+			}
+			return entity, nil
+		case !offline(ctx, err):
+			return nil, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.enqueue(m)
+}
+
+// enqueue applies m to the local store and queues it. r.mu must be held.
+func (r *Replica) enqueue(m *Mutation) (json.RawMessage, error) {
+	entities := r.st.Collections[m.Collection]
+	key := strconv.FormatInt(m.ID, 10)
+	var entity json.RawMessage
+	switch m.Op {
+	case OpCreate:
+		r.st.NextLocalID--
+		m.ID = r.st.NextLocalID
+		key = strconv.FormatInt(m.ID, 10)
+		var err error
+		if entity, err = merge(nil, m.Data, m.ID); err != nil {
+			return nil, err
+		}
+		entities[key] = entity
+	case OpEdit:
+		base, ok := entities[key]
+		if !ok {
+			return nil, errNotFound(m.Collection, m.ID)
+		}
+		var err error
+		if entity, err = merge(base, m.Data, m.ID); err != nil {
+			return nil, err
+		}
+		m.Base = base
+		entities[key] = entity
+	case OpDelete:
+		base, ok := entities[key]
+		if !ok {
+			return nil, errNotFound(m.Collection, m.ID)
+		}
+		m.Base = base
+		delete(entities, key)
+	}
+
+	r.st.NextSeq++
+	m.Seq = r.st.NextSeq
+	m.QueuedAt = animalrescue.Timestamp{Time: time.Now()}
+	r.st.Queue = append(r.st.Queue, m)
+	return entity, r.save()
+}
+
+// send performs m against the API, returning the resulting entity.
+func (r *Replica) send(ctx context.Context, m *Mutation) (interface{}, error) {
+	c := collections[m.Collection]
+	switch m.Op {
+	case OpCreate:
+		return c.create(ctx, r.client, m.Data)
+	case OpEdit:
+		return c.edit(ctx, r.client, m.ID, m.Data)
+	case OpDelete:
+		return nil, c.delete(ctx, r.client, m.ID)
+	}
+	return nil, fmt.Errorf("replica: unknown operation %q", m.Op)
+}
+
+// store records in the local store the entity v returned by the API for a
+// mutation of the entity with the given ID, returning its JSON. r.mu must
+// be held.
+func (r *Replica) store(coll string, op Op, id int64, v interface{}) (json.RawMessage, error) {
+	entities := r.st.Collections[coll]
+	if op == OpDelete {
+		delete(entities, strconv.FormatInt(id, 10))
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	newID, err := entityID(data)
+	if err != nil {
+		return nil, err
+	}
+	entities[strconv.FormatInt(newID, 10)] = data
+	return data, nil
+}
+
+// merge returns the JSON object base overlaid with the fields of the JSON
+// object patch, with its "id" field set to id.
+func merge(base, patch json.RawMessage, id int64) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if base != nil {
+		if err := json.Unmarshal(base, &fields); err != nil {
+			return nil, err
+		}
+	}
+	var overlay map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &overlay); err != nil {
+		return nil, err
+	}
+	for k, v := range overlay {
+		fields[k] = v
+	}
+	fields["id"] = json.RawMessage(strconv.FormatInt(id, 10))
+	return json.Marshal(fields)
+}
+
+// entityID returns the "id" field of the JSON object data.
+func entityID(data []byte) (int64, error) {
+	var v struct {
+		ID *int64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return 0, err
+	}
+	if v.ID == nil {
+		return 0, errors.New("replica: entity without ID")
+	}
+	return *v.ID, nil
+}
+
+// offline reports whether err means that the API could not be reached or
+// was unavailable for a while, rather than that it rejected the request:
+// a transport error, a 5xx response, or a rate limit.
+func offline(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var (
+		uerr    *url.Error
+		rerr    *animalrescue.RateLimitError
+		aerr    *animalrescue.AbuseRateLimitError
+		errResp *animalrescue.ErrorResponse
+	)
+	switch {
+	case errors.As(err, &uerr), errors.As(err, &rerr), errors.As(err, &aerr):
+		return true
+	case errors.As(err, &errResp):
+		return errResp.Response != nil && errResp.Response.StatusCode >= 500
+	}
+	return false
+}
+
+func errNotFound(coll string, id int64) error {
+	return fmt.Errorf("replica: %v %d: %w", coll, id, animalrescue.ErrNotFound)
+}
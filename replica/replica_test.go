@@ -0,0 +1,471 @@
+package replica_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+	"github.com/anGie44/go-animal-rescue/replica"
+)
+
+// network is a transport that can be cut off, and can hold requests back.
+type network struct {
+	mu      sync.Mutex
+	down    bool
+	fail    *http.Response // if set, answers every request
+	failGet string         // if set, GETs of this collection fail with 503
+	gets    []string       // last path element of every GET
+	hold    chan struct{}  // if set, requests wait for it to be closed
+	arrived chan struct{}  // if set, receives a value when a request is held
+	next    http.RoundTripper
+}
+
+func (n *network) RoundTrip(req *http.Request) (*http.Response, error) {
+	n.mu.Lock()
+	down, fail, hold, arrived := n.down, n.fail, n.hold, n.arrived
+	if req.Method == "GET" {
+		name := path.Base(req.URL.Path)
+		n.gets = append(n.gets, name)
+		if name == n.failGet {
+			fail = &http.Response{StatusCode: http.StatusServiceUnavailable}
+		}
+	}
+	n.mu.Unlock()
+	if down {
+		return nil, errors.New("network is unreachable")
+	}
+	if fail != nil {
+		resp := *fail
+		resp.Header = fail.Header.Clone()
+		if resp.Header == nil {
+			resp.Header = make(http.Header)
+		}
+		resp.Body = ioutil.NopCloser(strings.NewReader(`{"message":"unavailable"}`))
+		resp.Request = req
+		return &resp, nil
+	}
+	if hold != nil {
+		arrived <- struct{}{}
+		<-hold
+	}
+	return n.next.RoundTrip(req)
+}
+
+func (n *network) setDown(down bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.down = down
+}
+
+func (n *network) setFail(resp *http.Response) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fail = resp
+}
+
+// setup returns a fake server, a replica of it stored in a temporary file,
+// the network the replica reaches the server through, and the path of the
+// file.
+func setup(t *testing.T) (*animalrescuetest.Server, *replica.Replica, *network, string) {
+	t.Helper()
+	srv := animalrescuetest.NewServer()
+	t.Cleanup(srv.Close)
+	dir, err := ioutil.TempDir("", "replica")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	net := &network{}
+	client, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			net.next = next
+			return net
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "rescue.json")
+	r, err := replica.Open(path, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, r, net, path
+}
+
+func TestReplica_replayRemapsLocalIDs(t *testing.T) {
+	srv, r, net, path := setup(t)
+	ctx := context.Background()
+
+	net.setDown(true)
+	adopter, err := r.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptee, err := r.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{LastName: animalrescue.String("Doe")}); err != nil {
+		t.Fatal(err)
+	}
+	adoption, err := r.CreateAdoption(ctx, animalrescue.NewAdoption{Adopter: adopter, Adoptee: adoptee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{*adopter.ID, int64(adoptee.ID), int64(adoption.ID)} {
+		if id >= 0 {
+			t.Errorf("entity created offline has ID %d, want a local, negative ID", id)
+		}
+	}
+	if got, ok := r.Adopter(*adopter.ID); !ok || str(got.LastName) != "Doe" {
+		t.Errorf("Adopter(%d) = %v, %v, want the edited adopter", *adopter.ID, got, ok)
+	}
+	if n := len(r.Pending()); n != 4 {
+		t.Fatalf("Pending() holds %d mutations, want 4", n)
+	}
+
+	net.setDown(false)
+	res, err := r.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if res.Replayed != 4 || len(res.Failed) != 0 || len(res.Conflicts) != 0 {
+		t.Errorf("Sync returned %+v, want 4 mutations replayed", res)
+	}
+	if n := len(r.Pending()); n != 0 {
+		t.Errorf("Pending() holds %d mutations after Sync, want 0", n)
+	}
+
+	adoptions, _, err := srv.Client().Adoptions.ListAll(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(adoptions) != 1 {
+		t.Fatalf("server has %d adoptions, want 1", len(adoptions))
+	}
+	got := adoptions[0]
+	if str(got.Adopter.FirstName) != "Jane" || str(got.Adopter.LastName) != "Doe" || got.Adoptee.Name != "Rex" {
+		t.Errorf("server adoption is %v, want Jane Doe adopting Rex", got)
+	}
+
+	// The replica, as reopened from its file, holds the server's entities.
+	reopened, err := replica.Open(path, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	replicated := reopened.Adoptions()
+	if len(replicated) != 1 || replicated[0].ID != got.ID {
+		t.Errorf("reopened replica holds adoptions %v, want [%v]", replicated, got)
+	}
+	for _, a := range reopened.Adopters() {
+		if *a.ID < 0 {
+			t.Errorf("reopened replica holds local adopter %v", a)
+		}
+	}
+}
+
+func TestReplica_syncKeepsMutationsWhileUnavailable(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		header http.Header
+	}{
+		{"service unavailable", http.StatusServiceUnavailable, nil},
+		{"rate limited", http.StatusTooManyRequests, http.Header{
+			"X-Ratelimit-Limit":     {"60"},
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {"4102444800"},
+		}},
+		{"abuse rate limited", http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, r, net, _ := setup(t)
+			ctx := context.Background()
+
+			adopter, err := r.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			net.setDown(true)
+			if _, err := r.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{LastName: animalrescue.String("Doe")}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := r.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"}); err != nil {
+				t.Fatal(err)
+			}
+
+			net.setDown(false)
+			net.setFail(&http.Response{StatusCode: tt.status, Header: tt.header})
+			res, err := r.Sync(ctx)
+			if err == nil {
+				t.Fatal("Sync returned no error while the API was unavailable")
+			}
+			if len(res.Failed) != 0 {
+				t.Errorf("Sync dropped mutations %v while the API was unavailable", res.Failed)
+			}
+			if n := len(r.Pending()); n != 2 {
+				t.Fatalf("Pending() holds %d mutations, want 2", n)
+			}
+
+			net.setFail(nil)
+			res, err = r.Sync(ctx)
+			if err != nil {
+				t.Fatalf("Sync returned error: %v", err)
+			}
+			if res.Replayed != 2 || len(res.Failed) != 0 {
+				t.Errorf("Sync returned %+v, want 2 mutations replayed", res)
+			}
+			server, _, err := srv.Client().Adopters.GetAdopterByID(ctx, *adopter.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := str(server.LastName); got != "Doe" {
+				t.Errorf("server adopter has last name %q, want %q", got, "Doe")
+			}
+		})
+	}
+}
+
+func TestReplica_syncOrder(t *testing.T) {
+	srv, r, net, _ := setup(t)
+	ctx := context.Background()
+	c := srv.Client()
+	if _, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.PetPreferences.CreatePetPreference(ctx, animalrescue.NewPetPreference{Breed: "Beagle"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Collections are pulled in the same order every time, so a failure
+	// always leaves the same ones behind.
+	net.failGet = "adoptees"
+	for i := 0; i < 5; i++ {
+		if _, err := r.Sync(ctx); err == nil {
+			t.Fatal("Sync returned no error while adoptees could not be listed")
+		}
+		if len(r.Adopters()) != 1 || len(r.Adoptees()) != 0 || len(r.PetPreferences()) != 0 {
+			t.Fatalf("failed Sync left %d adopters, %d adoptees and %d pet preferences, want only the adopter",
+				len(r.Adopters()), len(r.Adoptees()), len(r.PetPreferences()))
+		}
+	}
+
+	net.failGet = ""
+	net.gets = nil
+	if _, err := r.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"adopters", "adoptees", "petprefs", "adoptions"}
+	if !reflect.DeepEqual(net.gets, want) {
+		t.Errorf("Sync listed %q, want %q", net.gets, want)
+	}
+}
+
+func TestReplica_notPersisted(t *testing.T) {
+	srv, r, net, path := setup(t)
+	ctx := context.Background()
+
+	// A non-empty directory in place of the file makes every save fail.
+	block := func() {
+		t.Helper()
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(path, "blocked"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	unblock := func() {
+		t.Helper()
+		if err := os.RemoveAll(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	adopter, err := r.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block()
+	edited, err := r.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{LastName: animalrescue.String("Doe")})
+	if !errors.Is(err, replica.ErrNotPersisted) {
+		t.Fatalf("EditAdopterByID returned error %v, want ErrNotPersisted", err)
+	}
+	if edited == nil || str(edited.LastName) != "Doe" {
+		t.Errorf("EditAdopterByID returned %+v, want the edited adopter", edited)
+	}
+	server, _, err := srv.Client().Adopters.GetAdopterByID(ctx, *adopter.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := str(server.LastName); got != "Doe" {
+		t.Errorf("server adopter has last name %q, want %q", got, "Doe")
+	}
+	if n := len(r.Pending()); n != 0 {
+		t.Errorf("Pending() holds %d mutations, want none", n)
+	}
+
+	unblock()
+	net.setDown(true)
+	if _, err := r.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"}); err != nil {
+		t.Fatal(err)
+	}
+	net.setDown(false)
+	block()
+	res, err := r.Sync(ctx)
+	if !errors.Is(err, replica.ErrNotPersisted) {
+		t.Fatalf("Sync returned error %v, want ErrNotPersisted", err)
+	}
+	if res.Replayed != 1 {
+		t.Errorf("Sync replayed %d mutations, want 1", res.Replayed)
+	}
+	if n := len(r.Pending()); n != 0 {
+		t.Errorf("Pending() holds %d mutations, want none", n)
+	}
+
+	unblock()
+	if _, err := r.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	adoptees, _, err := srv.Client().Adoptees.ListAll(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(adoptees) != 1 {
+		t.Errorf("server holds %d adoptees, want 1", len(adoptees))
+	}
+	reopened, err := replica.Open(path, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reopened.Adopters(); len(got) != 1 || str(got[0].LastName) != "Doe" {
+		t.Errorf("reopened replica holds adopters %+v, want the edited adopter", got)
+	}
+}
+
+func TestReplica_conflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy replica.Policy
+		want   string // first name on the server after Sync
+		res    replica.Resolution
+	}{
+		{"default", nil, "Local", replica.ApplyLocal},
+		{"last writer wins", replica.LastWriterWins, "Local", replica.ApplyLocal},
+		{"server wins", replica.ServerWins, "Server", replica.KeepServer},
+		{"callback", replica.PolicyFunc(func(ctx context.Context, c *replica.Conflict) (replica.Resolution, error) {
+			server := c.Server.(*animalrescue.Adopter)
+			if str(server.FirstName) == "Server" {
+				return replica.KeepServer, nil
+			}
+			return replica.ApplyLocal, nil
+		}), "Server", replica.KeepServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, r, net, _ := setup(t)
+			ctx := context.Background()
+			r.Policy = tt.policy
+
+			adopter, err := r.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+			if err != nil {
+				t.Fatal(err)
+			}
+			net.setDown(true)
+			if _, err := r.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{FirstName: animalrescue.String("Local")}); err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := srv.Client().Adopters.EditAdopterByID(ctx, *adopter.ID, animalrescue.NewAdopter{FirstName: animalrescue.String("Server")}); err != nil {
+				t.Fatal(err)
+			}
+
+			net.setDown(false)
+			res, err := r.Sync(ctx)
+			if err != nil {
+				t.Fatalf("Sync returned error: %v", err)
+			}
+			if len(res.Conflicts) != 1 || res.Conflicts[0].Resolution != tt.res {
+				t.Errorf("Sync reported conflicts %v, want one resolved with %v", res.Conflicts, tt.res)
+			}
+
+			server, _, err := srv.Client().Adopters.GetAdopterByID(ctx, *adopter.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := str(server.FirstName); got != tt.want {
+				t.Errorf("server adopter is named %q, want %q", got, tt.want)
+			}
+			if local, _ := r.Adopter(*adopter.ID); str(local.FirstName) != tt.want {
+				t.Errorf("replica adopter is named %q, want %q", str(local.FirstName), tt.want)
+			}
+		})
+	}
+}
+
+func TestReplica_readsDuringSend(t *testing.T) {
+	_, r, net, _ := setup(t)
+	ctx := context.Background()
+
+	if _, err := r.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"}); err != nil {
+		t.Fatal(err)
+	}
+
+	hold := make(chan struct{})
+	net.mu.Lock()
+	net.hold, net.arrived = hold, make(chan struct{}, 1)
+	arrived := net.arrived
+	net.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Bella"})
+		done <- err
+	}()
+	<-arrived
+
+	reads := make(chan int, 1)
+	go func() { reads <- len(r.Adoptees()) }()
+	select {
+	case n := <-reads:
+		if n != 1 {
+			t.Errorf("Adoptees() returned %d adoptees while the creation was in flight, want 1", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Adoptees() blocked while a mutation was being sent")
+	}
+
+	close(hold)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := len(r.Adoptees()); n != 2 {
+		t.Errorf("Adoptees() returned %d adoptees, want 2", n)
+	}
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
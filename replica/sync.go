@@ -0,0 +1,362 @@
+package replica
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Resolution is the outcome of a conflict chosen by a Policy.
+type Resolution int
+
+// Conflict resolutions.
+const (
+	// ApplyLocal replays the queued mutation, overwriting the changes
+	// made on the server.
+	ApplyLocal Resolution = iota + 1
+
+	// KeepServer drops the queued mutation, keeping the entity as it is
+	// on the server.
+	KeepServer
+)
+
+func (r Resolution) String() string {
+	switch r {
+	case ApplyLocal:
+		return "apply local"
+	case KeepServer:
+		return "keep server"
+	}
+	return "unknown"
+}
+
+// A Conflict is met by Sync when an entity changed on the server after an
+// edit or delete of it was queued.
+type Conflict struct {
+	Mutation *Mutation
+
+	// Base is the entity as the replica knew it when the mutation was
+	// queued, and Server the entity as it is now on the server, or nil if
+	// it was deleted there. Both are pointers to an animalrescue entity
+	// type, such as *animalrescue.Adopter.
+	Base   interface{}
+	Server interface{}
+
+	// Resolution is the resolution chosen by the policy.
+	Resolution Resolution
+}
+
+// A Policy resolves conflicts met by Sync.
+type Policy interface {
+	// Resolve chooses how to resolve c. If it returns an error, Sync
+	// stops and returns it, leaving the mutation queued.
+	Resolve(ctx context.Context, c *Conflict) (Resolution, error)
+}
+
+// PolicyFunc adapts an ordinary function, such as a callback asking the
+// user, to the Policy interface.
+type PolicyFunc func(ctx context.Context, c *Conflict) (Resolution, error)
+
+// Resolve calls f(ctx, c).
+func (f PolicyFunc) Resolve(ctx context.Context, c *Conflict) (Resolution, error) {
+	return f(ctx, c)
+}
+
+var (
+	// LastWriterWins resolves every conflict with ApplyLocal. The API does
+	// not report when entities were last modified, so the queued mutation,
+	// which is replayed after the server's change, is the last write.
+	LastWriterWins Policy = PolicyFunc(func(context.Context, *Conflict) (Resolution, error) {
+		return ApplyLocal, nil
+	})
+
+	// ServerWins resolves every conflict with KeepServer.
+	ServerWins Policy = PolicyFunc(func(context.Context, *Conflict) (Resolution, error) {
+		return KeepServer, nil
+	})
+)
+
+// A MutationError reports a queued mutation that the API rejected when it
+// was replayed. The mutation is dropped.
+type MutationError struct {
+	Mutation *Mutation
+	Err      error
+}
+
+func (e *MutationError) Error() string {
+	return fmt.Sprintf("replica: %v of %v %d: %v", e.Mutation.Op, e.Mutation.Collection, e.Mutation.ID, e.Err)
+}
+
+func (e *MutationError) Unwrap() error {
+	return e.Err
+}
+
+// SyncResult reports the outcome of a Sync.
+type SyncResult struct {
+	// Replayed is the number of queued mutations sent to the API.
+	Replayed int
+
+	// Conflicts lists the conflicts met, with their resolution.
+	Conflicts []*Conflict
+
+	// Failed lists the queued mutations rejected by the API.
+	Failed []*MutationError
+
+	// Pulled is the number of local entities added, updated or removed to
+	// match the API.
+	Pulled int
+}
+
+// Sync replays the queued mutations in order, then brings the local store
+// up to date with the API. If the API cannot be reached, answers with a
+// 5xx status or limits the rate of requests, Sync stops and returns the
+// error, keeping the mutations that were not yet replayed queued for the
+// next Sync. If a replayed mutation cannot be saved to the replica file,
+// Sync stops with an error matching ErrNotPersisted.
+//
+// Sync then lists every collection in full, as described in the package
+// documentation, and only rewrites the entities that differ from the local
+// ones, counting them in SyncResult.Pulled. If listing a collection fails,
+// the collections before it are left up to date and those after it as they
+// were.
+func (r *Replica) Sync(ctx context.Context) (*SyncResult, error) {
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	res := &SyncResult{}
+	for {
+		r.mu.Lock()
+		if len(r.st.Queue) == 0 {
+			r.mu.Unlock()
+			break
+		}
+		m := r.st.Queue[0]
+		r.mu.Unlock()
+
+		err := r.replay(ctx, m, res)
+
+		r.mu.Lock()
+		if err == nil {
+			r.st.Queue = r.st.Queue[1:]
+		}
+		serr := r.save()
+		r.mu.Unlock()
+		if err != nil {
+			return res, err
+		}
+		if serr != nil {
+			// m was dropped from the queue in memory, so it is not
+			// replayed again by a later Sync.
+			return res, &notPersisted{serr}
+		}
+	}
+
+	for _, name := range collectionOrder {
+		list, err := collections[name].source(r.client).List(ctx)
+		if err != nil {
+			return res, err
+		}
+		fresh := make(map[string]json.RawMessage, len(list))
+		for id, v := range list {
+			data, err := json.Marshal(v)
+			if err != nil {
+				return res, err
+			}
+			fresh[strconv.FormatInt(id, 10)] = data
+		}
+		r.mu.Lock()
+		res.Pulled += r.pull(name, fresh)
+		r.mu.Unlock()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.st.SyncedAt = &animalrescue.Timestamp{Time: time.Now()}
+	return res, r.save()
+}
+
+// pull makes the local entities of the collection those of fresh, listed by
+// the API, returning the number of entities added, updated or removed.
+// Entities created offline whose creation was not replayed are removed.
+// r.mu must be held.
+func (r *Replica) pull(coll string, fresh map[string]json.RawMessage) int {
+	entities := r.st.Collections[coll]
+	n := 0
+	for key := range entities {
+		if _, ok := fresh[key]; !ok {
+			delete(entities, key)
+			n++
+		}
+	}
+	for key, data := range fresh {
+		if !sameJSON(entities[key], data) {
+			entities[key] = data
+			n++
+		}
+	}
+	return n
+}
+
+// replay sends the queued mutation m to the API, resolving any conflict.
+// Mutations rejected by the API are recorded in res; the errors returned
+// stop the Sync. r.sendMu must be held, and r.mu is acquired only to update
+// the local store, so that reads go on while m is sent and while the
+// policy resolves a conflict.
+func (r *Replica) replay(ctx context.Context, m *Mutation, res *SyncResult) error {
+	c := collections[m.Collection]
+	if c == nil {
+		res.Failed = append(res.Failed, &MutationError{m, fmt.Errorf("unknown collection %q", m.Collection)})
+		return nil
+	}
+	// A rejected creation leaves mutations of the entity it created
+	// without a server ID.
+	if m.Op != OpCreate && m.ID < 0 {
+		res.Failed = append(res.Failed, &MutationError{m, errNotFound(m.Collection, m.ID)})
+		return nil
+	}
+
+	if m.Op != OpCreate && m.Base != nil {
+		server, err := c.get(ctx, r.client, m.ID)
+		switch {
+		case errors.Is(err, animalrescue.ErrNotFound):
+			server = nil
+		case err != nil:
+			return r.rejected(ctx, m, err, res)
+		}
+
+		current, err := marshalEntity(server)
+		if err != nil {
+			return err
+		}
+		if !sameJSON(current, m.Base) {
+			conflict := &Conflict{Mutation: m, Base: c.entity(), Server: server}
+			if err := json.Unmarshal(m.Base, conflict.Base); err != nil {
+				return err
+			}
+			policy := r.Policy
+			if policy == nil {
+				policy = LastWriterWins
+			}
+			if conflict.Resolution, err = policy.Resolve(ctx, conflict); err != nil {
+				return err
+			}
+			res.Conflicts = append(res.Conflicts, conflict)
+			if conflict.Resolution == KeepServer {
+				return nil
+			}
+		}
+		if current == nil {
+			// Deleted on the server: there is nothing left to change.
+			return nil
+		}
+	}
+
+	v, err := r.send(ctx, m)
+	if err != nil {
+		return r.rejected(ctx, m, err, res)
+	}
+	res.Replayed++
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if m.Op == OpCreate {
+		delete(r.st.Collections[m.Collection], strconv.FormatInt(m.ID, 10))
+	}
+	data, err := r.store(m.Collection, m.Op, m.ID, v)
+	if err != nil {
+		return err
+	}
+	if m.Op == OpCreate {
+		id, err := entityID(data)
+		if err != nil {
+			return err
+		}
+		return r.remap(m.ID, id)
+	}
+	return nil
+}
+
+// rejected records err, returned by the API for m, in res, unless it means
+// that the API could not be reached or was unavailable, in which case it is
+// returned.
+func (r *Replica) rejected(ctx context.Context, m *Mutation, err error, res *SyncResult) error {
+	if ctx.Err() != nil || offline(ctx, err) {
+		return err
+	}
+	res.Failed = append(res.Failed, &MutationError{m, err})
+	return nil
+}
+
+// remap rewrites the queued mutations referring to the entity created
+// offline with the local ID to use the ID assigned by the API. r.mu must be
+// held.
+func (r *Replica) remap(local, id int64) error {
+	for _, m := range r.st.Queue {
+		if m.ID == local {
+			m.ID = id
+			// The entity was just created from the replica's own
+			// data, so there is nothing to conflict with.
+			m.Base = nil
+		}
+		if m.Collection != CollectionAdoptions || m.Data == nil {
+			continue
+		}
+
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(m.Data, &fields); err != nil {
+			return err
+		}
+		changed := false
+		for _, key := range []string{"adopter", "adoptee"} {
+			if fields[key] == nil {
+				continue
+			}
+			ref, err := entityID(fields[key])
+			if err != nil || ref != local {
+				continue
+			}
+			if fields[key], err = merge(fields[key], []byte("{}"), id); err != nil {
+				return err
+			}
+			changed = true
+		}
+		if changed {
+			data, err := json.Marshal(fields)
+			if err != nil {
+				return err
+			}
+			m.Data = data
+		}
+	}
+	return nil
+}
+
+// marshalEntity returns the JSON of the entity v, or nil if v is a nil
+// pointer.
+func marshalEntity(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// sameJSON reports whether a and b encode the same JSON value.
+func sameJSON(a, b json.RawMessage) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,153 @@
+package animalrescue
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	headerRequestID      = "X-Request-ID"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// A RequestOption customizes a single request. Every service method accepts
+// a list of them, as does NewRequest:
+//
+//	adopter, _, err := client.Adopters.GetAdopterByID(ctx, id,
+//		animalrescue.WithRequestID(reqID),
+//		animalrescue.WithTimeout(2*time.Second))
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	header         http.Header
+	query          url.Values
+	timeout        time.Duration
+	idempotencyKey string
+	generateKey    bool
+}
+
+// WithHeader adds a header to the request. Headers set by the client itself,
+// such as Accept and User-Agent, take precedence; headers set with
+// WithHeader replace those of the same name set with WithDefaultHeaders.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.header == nil {
+			o.header = make(http.Header)
+		}
+		o.header.Add(key, value)
+	}
+}
+
+// WithQuery adds a query parameter to the URL of the request.
+func WithQuery(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		o.query.Add(key, value)
+	}
+}
+
+// WithTimeout limits the time taken by the call, including any retries, to
+// d. It is applied by Do, on top of the deadline of its context.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRequestID sends id in the X-Request-ID header, so that the request can
+// be correlated with the caller's own logs.
+func WithRequestID(id string) RequestOption {
+	return WithHeader(headerRequestID, id)
+}
+
+// WithIdempotencyKey sends key in the Idempotency-Key header, so that the API
+// performs a creation at most once however many times it is sent. Requests
+// with an idempotency key are retried according to the client's RetryPolicy
+// even if their method is not listed in RetryMethods. Passed to a CreateMany
+// method, it would send the same key for every item: use
+// WithGeneratedIdempotencyKey there instead.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithGeneratedIdempotencyKey makes Create* methods send a random
+// idempotency key, generated for each request. See WithIdempotencyKey.
+func WithGeneratedIdempotencyKey() RequestOption {
+	return func(o *requestOptions) {
+		o.generateKey = true
+	}
+}
+
+// readOptions returns opts without the idempotency keys they set, for the
+// reads made by a method given opts for a creation.
+func readOptions(opts []RequestOption) []RequestOption {
+	read := make([]RequestOption, len(opts))
+	for i, opt := range opts {
+		opt := opt
+		read[i] = func(o *requestOptions) {
+			key, generate := o.idempotencyKey, o.generateKey
+			opt(o)
+			o.idempotencyKey, o.generateKey = key, generate
+		}
+	}
+	return read
+}
+
+// timeoutKey is the request context key of the timeout set by WithTimeout,
+// which Do applies.
+type timeoutKey struct{}
+
+// applyRequestOptions applies opts to req, built by NewRequest.
+func applyRequestOptions(req *http.Request, opts []RequestOption) (*http.Request, error) {
+	if len(opts) == 0 {
+		return req, nil
+	}
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.query) > 0 {
+		q := req.URL.Query()
+		for k, v := range o.query {
+			q[k] = append(q[k], v...)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+	for k, v := range o.header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	if o.idempotencyKey == "" && o.generateKey && req.Method == "POST" {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return nil, err
+		}
+		o.idempotencyKey = key
+	}
+	if o.idempotencyKey != "" {
+		req.Header.Set(headerIdempotencyKey, o.idempotencyKey)
+	}
+	if o.timeout > 0 {
+		req = req.WithContext(context.WithValue(req.Context(), timeoutKey{}, o.timeout))
+	}
+	return req, nil
+}
+
+// newIdempotencyKey returns a random version 4 UUID.
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,201 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestRequestOptions_headerAndQuery(t *testing.T) {
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithUserAgent("animalrescue-test"),
+		animalrescue.WithDefaultHeaders(http.Header{
+			"X-Source": {"default"},
+			"X-Team":   {"intake"},
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, err := c.NewRequest("GET", "adopters?page=2", nil,
+		animalrescue.WithHeader("X-Source", "cli"),
+		animalrescue.WithHeader("X-Source", "batch"),
+		animalrescue.WithHeader("User-Agent", "other"),
+		animalrescue.WithHeader("Accept", "text/plain"),
+		animalrescue.WithRequestID("req-1"),
+		animalrescue.WithQuery("page", "3"),
+		animalrescue.WithQuery("sort", "id"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantHeader := map[string][]string{
+		"X-Source":     {"cli", "batch"}, // replaces the default header
+		"X-Team":       {"intake"},
+		"X-Request-Id": {"req-1"},
+		"User-Agent":   {"animalrescue-test"}, // set by the client
+		"Accept":       {"application/json"},
+	}
+	for k, want := range wantHeader {
+		if got := req.Header.Values(k); !reflect.DeepEqual(got, want) {
+			t.Errorf("header %v = %q, want %q", k, got, want)
+		}
+	}
+	wantQuery := map[string][]string{
+		"page": {"2", "3"},
+		"sort": {"id"},
+	}
+	if got := map[string][]string(req.URL.Query()); !reflect.DeepEqual(got, wantQuery) {
+		t.Errorf("query = %v, want %v", got, wantQuery)
+	}
+
+	// Options do not leak into the client's default headers.
+	req, err = c.NewRequest("GET", "adopters", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Values("X-Source"); !reflect.DeepEqual(got, []string{"default"}) {
+		t.Errorf("header X-Source of a later request = %q, want the default", got)
+	}
+}
+
+func TestRequestOptions_idempotencyKey(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	c := animalrescue.NewClient(nil)
+
+	tests := []struct {
+		method string
+		opts   []animalrescue.RequestOption
+		want   string // "uuid" for a generated key
+	}{
+		{"POST", nil, ""},
+		{"POST", []animalrescue.RequestOption{animalrescue.WithIdempotencyKey("create-jane")}, "create-jane"},
+		{"PATCH", []animalrescue.RequestOption{animalrescue.WithIdempotencyKey("edit-jane")}, "edit-jane"},
+		{"POST", []animalrescue.RequestOption{animalrescue.WithGeneratedIdempotencyKey()}, "uuid"},
+		{"GET", []animalrescue.RequestOption{animalrescue.WithGeneratedIdempotencyKey()}, ""},
+		{"PATCH", []animalrescue.RequestOption{animalrescue.WithGeneratedIdempotencyKey()}, ""},
+		{"DELETE", []animalrescue.RequestOption{animalrescue.WithGeneratedIdempotencyKey()}, ""},
+		{"POST", []animalrescue.RequestOption{
+			animalrescue.WithGeneratedIdempotencyKey(),
+			animalrescue.WithIdempotencyKey("create-jane"),
+		}, "create-jane"},
+		{"POST", []animalrescue.RequestOption{
+			animalrescue.WithIdempotencyKey("create-jane"),
+			animalrescue.WithGeneratedIdempotencyKey(),
+		}, "create-jane"},
+	}
+
+	for _, tt := range tests {
+		req, err := c.NewRequest(tt.method, "adopters", nil, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := req.Header.Get("Idempotency-Key")
+		switch {
+		case tt.want == "uuid" && !uuid.MatchString(got):
+			t.Errorf("%v request with %d options has Idempotency-Key %q, want a generated UUID", tt.method, len(tt.opts), got)
+		case tt.want != "uuid" && got != tt.want:
+			t.Errorf("%v request with %d options has Idempotency-Key %q, want %q", tt.method, len(tt.opts), got, tt.want)
+		}
+	}
+
+	// Every request gets a key of its own.
+	keys := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		req, err := c.NewRequest("POST", "adopters", nil, animalrescue.WithGeneratedIdempotencyKey())
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[req.Header.Get("Idempotency-Key")] = true
+	}
+	if len(keys) != 3 {
+		t.Errorf("3 requests were sent with %d distinct generated keys, want 3", len(keys))
+	}
+}
+
+func TestRequestOptions_idempotencyKeySent(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+
+	var keys []string
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			keys = append(keys, req.Header.Get("Idempotency-Key"))
+			return next.RoundTrip(req)
+		})
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(record),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	adopter, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")},
+		animalrescue.WithIdempotencyKey("create-jane"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Adopters.GetAdopterByID(ctx, *adopter.ID, animalrescue.WithGeneratedIdempotencyKey()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"create-jane", ""}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("requests were sent with idempotency keys %q, want %q", keys, want)
+	}
+}
+
+func TestRequestOptions_timeout(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+
+	// Requests with the X-Slow header hang until they are canceled.
+	slow := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Slow") == "" {
+				return next.RoundTrip(req)
+			}
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(5 * time.Second):
+				return next.RoundTrip(req)
+			}
+		})
+	}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithMiddleware(slow),
+		animalrescue.WithRetryPolicy(animalrescue.DefaultRetryPolicy()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	_, _, err = c.Adopters.ListAll(ctx, nil,
+		animalrescue.WithHeader("X-Slow", "1"),
+		animalrescue.WithTimeout(50*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListAll with a timeout returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ListAll with a 50ms timeout took %v", elapsed)
+	}
+
+	// The timeout only applies to the request it was given to.
+	if _, _, err := c.Adopters.ListAll(ctx, nil); err != nil {
+		t.Errorf("ListAll without a timeout returned error: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy controls how Client.Do retries requests that fail with a
+// transport error or a retryable status code. The zero value of each field
+// selects the default listed next to it.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a request,
+	// including the first one. Values below 2 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Default: 200ms.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Default: 10s.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor by which the delay grows after each attempt.
+	// Default: 2.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to this fraction of its value, in
+	// either direction, so that concurrent clients do not retry in lockstep.
+	// It must be between 0 and 1.
+	Jitter float64
+
+	// RetryStatusCodes lists the HTTP status codes that are retried.
+	// Default: 500, 502, 503 and 504.
+	RetryStatusCodes []int
+
+	// RetryMethods lists the HTTP methods that are retried. Only idempotent
+	// methods should be listed; add "PATCH" to opt in to retrying edits.
+	// Requests sent with an idempotency key are retried whatever their
+	// method. Default: GET, HEAD, OPTIONS and DELETE.
+	RetryMethods []string
+
+	// RetryOn, if set, replaces the status code based classification and
+	// reports whether an attempt that produced resp or err should be retried.
+	// It is only consulted for requests whose method is retryable.
+	RetryOn func(resp *http.Response, err error) bool
+}
+
+var (
+	defaultRetryStatusCodes = []int{
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+	defaultRetryMethods = []string{"GET", "HEAD", "OPTIONS", "DELETE"}
+)
+
+// DefaultRetryPolicy returns a RetryPolicy suited to riding out the API's
+// cold starts: up to 4 attempts with exponential backoff and 20% jitter.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 4,
+		Jitter:      0.2,
+	}
+}
+
+// retryable reports whether req may be retried after an attempt that
+// produced resp or err.
+func (p *RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if !p.methodAllowed(req.Method) && req.Header.Get(headerIdempotencyKey) == "" {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false // the body cannot be replayed
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.RetryOn != nil {
+		return p.RetryOn(resp, err)
+	}
+	if err != nil {
+		return true
+	}
+
+	codes := p.RetryStatusCodes
+	if codes == nil {
+		codes = defaultRetryStatusCodes
+	}
+	for _, code := range codes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) methodAllowed(method string) bool {
+	methods := p.RetryMethods
+	if methods == nil {
+		methods = defaultRetryMethods
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay to wait after the given attempt (starting at 1).
+// A Retry-After header on resp takes precedence when it asks for longer.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	initial, max, mult := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = 200 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 10 * time.Second
+	}
+	if mult < 1 {
+		mult = 2
+	}
+
+	d := float64(initial) * math.Pow(mult, float64(attempt-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1)
+	}
+	wait := time.Duration(d)
+
+	if resp != nil {
+		if after, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter)); ok && after > wait {
+			wait = after
+		}
+	}
+	return wait
+}
+
+// parseRetryAfter parses the value of a Retry-After header, given either in
+// seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+// send performs req, retrying it according to c.RetryPolicy. It returns the
+// last response or error along with the number of attempts made and the
+// number of bytes read from the bodies of the responses that were retried.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, int, int64, error) {
+	policy := c.RetryPolicy
+	var drained int64
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		resp, err := c.client.Do(req)
+		c.logRequest(req, resp, err, time.Since(start))
+		if policy == nil || attempt >= policy.MaxAttempts || !policy.retryable(req, resp, err) {
+			return resp, attempt, drained, err
+		}
+
+		wait := policy.backoff(attempt, resp)
+		if err != nil {
+			c.logf("animalrescue: %v %v failed: %v; retrying in %v (attempt %d of %d)",
+				req.Method, RedactURL(req.URL), redactError(err), wait, attempt+1, policy.MaxAttempts)
+		} else {
+			c.logf("animalrescue: %v %v returned %v; retrying in %v (attempt %d of %d)",
+				req.Method, RedactURL(req.URL), resp.Status, wait, attempt+1, policy.MaxAttempts)
+		}
+		if resp != nil {
+			// Drain the body so the connection can be reused.
+			n, _ := io.Copy(ioutil.Discard, resp.Body)
+			drained += n
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, drained, ctx.Err()
+		case <-timer.C:
+		}
+
+		next := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, attempt, drained, err
+			}
+			next.Body = body
+		}
+		req = next
+	}
+}
@@ -0,0 +1,275 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// outcome is the result a flakyNetwork gives for one request.
+type outcome struct {
+	status  int   // status code answered, unless err is set
+	err     error // transport error returned
+	forward bool  // whether the request reaches the server anyway
+}
+
+// flakyNetwork gives the scripted outcomes to the requests sent through it,
+// in order, then lets requests through to the server.
+type flakyNetwork struct {
+	mu       sync.Mutex
+	script   []outcome
+	requests int
+	next     http.RoundTripper
+}
+
+func (n *flakyNetwork) RoundTrip(req *http.Request) (*http.Response, error) {
+	n.mu.Lock()
+	n.requests++
+	var o *outcome
+	if len(n.script) > 0 {
+		o = &n.script[0]
+		n.script = n.script[1:]
+	}
+	n.mu.Unlock()
+
+	if o == nil {
+		return n.next.RoundTrip(req)
+	}
+	if o.forward {
+		resp, err := n.next.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+	}
+	if o.err != nil {
+		return nil, o.err
+	}
+	return &http.Response{
+		Status:     http.StatusText(o.status),
+		StatusCode: o.status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"message":"` + http.StatusText(o.status) + `"}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_retries(t *testing.T) {
+	var (
+		unavailable = outcome{status: http.StatusServiceUnavailable}
+		reset       = outcome{err: errors.New("connection reset by peer")}
+		lost        = outcome{status: http.StatusGatewayTimeout, forward: true}
+	)
+	get := func(ctx context.Context, c *animalrescue.Client) error {
+		_, _, err := c.Adopters.GetAdopterByID(ctx, 1)
+		return err
+	}
+	create := func(ctx context.Context, c *animalrescue.Client, reqOpts ...animalrescue.RequestOption) error {
+		_, _, err := c.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")}, reqOpts...)
+		return err
+	}
+	edit := func(ctx context.Context, c *animalrescue.Client) error {
+		_, _, err := c.Adopters.EditAdopterByID(ctx, 1, animalrescue.NewAdopter{City: animalrescue.String("Austin")})
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		policy   animalrescue.RetryPolicy
+		script   []outcome
+		call     func(ctx context.Context, c *animalrescue.Client) error
+		requests int  // requests sent
+		wantErr  bool // whether call fails
+		adopters int  // adopters on the server afterwards
+	}{
+		{
+			name:     "retryable status",
+			script:   []outcome{unavailable, unavailable},
+			call:     get,
+			requests: 3,
+			adopters: 1,
+		},
+		{
+			name:     "transport error",
+			script:   []outcome{reset},
+			call:     get,
+			requests: 2,
+			adopters: 1,
+		},
+		{
+			name:     "attempts exhausted",
+			script:   []outcome{unavailable, unavailable, unavailable, unavailable},
+			call:     get,
+			requests: 3,
+			wantErr:  true,
+			adopters: 1,
+		},
+		{
+			name:     "status not retried",
+			script:   []outcome{{status: http.StatusBadRequest}},
+			call:     get,
+			requests: 1,
+			wantErr:  true,
+			adopters: 1,
+		},
+		{
+			name:     "custom status codes",
+			policy:   animalrescue.RetryPolicy{RetryStatusCodes: []int{http.StatusTooManyRequests}},
+			script:   []outcome{{status: http.StatusTooManyRequests}, unavailable},
+			call:     get,
+			requests: 2,
+			wantErr:  true,
+			adopters: 1,
+		},
+		{
+			name: "RetryOn",
+			policy: animalrescue.RetryPolicy{RetryOn: func(resp *http.Response, err error) bool {
+				return err != nil
+			}},
+			script:   []outcome{reset, unavailable},
+			call:     get,
+			requests: 2,
+			wantErr:  true,
+			adopters: 1,
+		},
+		{
+			name:     "creation not retried",
+			script:   []outcome{lost},
+			call:     func(ctx context.Context, c *animalrescue.Client) error { return create(ctx, c) },
+			requests: 1,
+			wantErr:  true,
+			adopters: 2,
+		},
+		{
+			name:   "creation with idempotency key",
+			script: []outcome{lost, lost},
+			call: func(ctx context.Context, c *animalrescue.Client) error {
+				return create(ctx, c, animalrescue.WithIdempotencyKey("create-jane"))
+			},
+			requests: 3,
+			adopters: 2,
+		},
+		{
+			name:     "edit not retried",
+			script:   []outcome{unavailable},
+			call:     edit,
+			requests: 1,
+			wantErr:  true,
+			adopters: 1,
+		},
+		{
+			name:     "edit retried on opt-in",
+			policy:   animalrescue.RetryPolicy{RetryMethods: []string{"GET", "PATCH"}},
+			script:   []outcome{unavailable},
+			call:     edit,
+			requests: 2,
+			adopters: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := animalrescuetest.NewServer()
+			defer srv.Close()
+			ctx := context.Background()
+			if err := create(ctx, srv.Client()); err != nil {
+				t.Fatal(err)
+			}
+
+			net := &flakyNetwork{script: tt.script}
+			policy := tt.policy
+			policy.MaxAttempts = 3
+			policy.InitialBackoff = time.Millisecond
+			c, err := animalrescue.NewClientWithOptions(
+				animalrescue.WithBaseURL(srv.URL+"/"),
+				animalrescue.WithRetryPolicy(&policy),
+				animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+					net.next = next
+					return net
+				}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.call(ctx, c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("call returned error %v, want error: %v", err, tt.wantErr)
+			}
+			if net.requests != tt.requests {
+				t.Errorf("client sent %d requests, want %d", net.requests, tt.requests)
+			}
+			adopters, _, err := srv.Client().Adopters.ListAll(ctx, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(adopters) != tt.adopters {
+				t.Errorf("server holds %d adopters, want %d", len(adopters), tt.adopters)
+			}
+		})
+	}
+}
+
+func TestClient_retries_attempts(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	net := &flakyNetwork{script: []outcome{{status: http.StatusBadGateway}}}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithRetryPolicy(&animalrescue.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			net.next = next
+			return net
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, resp, err := c.Adopters.ListAll(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("Response.Attempts = %d, want 2", resp.Attempts)
+	}
+}
+
+func TestClient_retries_canceledDuringBackoff(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	net := &flakyNetwork{script: []outcome{{status: http.StatusServiceUnavailable}}}
+	c, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithBaseURL(srv.URL+"/"),
+		animalrescue.WithRetryPolicy(&animalrescue.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Hour}),
+		animalrescue.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+			net.next = next
+			return net
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err = c.Adopters.ListAll(ctx, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListAll returned %v, want context.DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("ListAll returned after %v, want it to stop waiting once ctx is done", d)
+	}
+	if net.requests != 1 {
+		t.Errorf("client sent %d requests, want 1", net.requests)
+	}
+}
@@ -1,34 +1,123 @@
 package animalrescue
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Timestamp represents a time that can be unmarshalled from a JSON string
-// formatted as either an RFC3339 or Unix timestamp.
+// formatted as RFC3339 (with or without fractional seconds) or as a date
+// alone, or from a JSON number holding a Unix time in seconds,
+// milliseconds, microseconds or nanoseconds. Strings of digits are not
+// Unix times, and fail to parse. JSON null and the empty string unmarshal
+// to the zero Timestamp. Timestamps marshal to RFC3339 strings with
+// fractional seconds, and the zero Timestamp to null. Timestamps holding
+// dates, such as Adopter.Birthdate, are marshalled as the date alone by the
+// types holding them.
 type Timestamp struct {
 	time.Time
 }
 
+// timestampLayouts are the string formats accepted by Timestamp, tried in
+// order. RFC3339Nano also parses RFC3339 values without fractional seconds.
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
 func (t Timestamp) String() string {
 	return t.Time.String()
 }
 
+// MarshalJSON implements the json.Marshaler interface.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(time.RFC3339Nano))
+}
+
 // UnmarshalJSON implements the json.Unmarshaler interface.
-// Time is expected in RFC3339 or Unix format.
-func (t *Timestamp) UnmarshalJSON(data []byte) (err error) {
+// Time is expected in RFC3339, date-only or Unix format.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
 	str := string(data)
-	i, err := strconv.ParseInt(str, 10, 64)
-	if err == nil {
-		t.Time = time.Unix(i, 0)
-	} else {
-		t.Time, err = time.Parse(`"`+time.RFC3339+`"`, str)
+	if str == "null" {
+		t.Time = time.Time{}
+		return nil
+	}
+	if i, err := strconv.ParseInt(str, 10, 64); err == nil {
+		t.Time = unixTime(i)
+		return nil
+	}
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("timestamp must be a string or a number: %s", data)
+	}
+	return t.UnmarshalText([]byte(str))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The zero
+// Timestamp is written as empty text.
+func (t Timestamp) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return []byte(t.Format(time.RFC3339Nano)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. It
+// accepts the string formats of UnmarshalJSON; Unix times are only
+// accepted as JSON numbers.
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	str := strings.TrimSpace(string(text))
+	if str == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range timestampLayouts {
+		if tm, err := time.Parse(layout, str); err == nil {
+			t.Time = tm
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a timestamp", str)
+}
+
+// unixTime returns the time of the Unix timestamp i, whose unit is inferred
+// from its magnitude: seconds up to 1e11 (the year 5138), then milliseconds,
+// microseconds and nanoseconds.
+func unixTime(i int64) time.Time {
+	abs := i
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(i, 0)
+	case abs < 1e14:
+		return time.Unix(i/1e3, i%1e3*int64(time.Millisecond))
+	case abs < 1e17:
+		return time.Unix(i/1e6, i%1e6*int64(time.Microsecond))
 	}
-	return
+	return time.Unix(0, i)
 }
 
 // Equal reports whether t and u are equal based on time.Equal
 func (t Timestamp) Equal(u Timestamp) bool {
 	return t.Time.Equal(u.Time)
 }
+
+// dateLayout is the format of timestamps holding a date alone.
+const dateLayout = "2006-01-02"
+
+// dateOnly is a Timestamp that marshals to a "2006-01-02" string, the
+// format of dates such as Adopter.Birthdate, and the zero Timestamp to
+// null. The types holding dates marshal them through it.
+type dateOnly Timestamp
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d dateOnly) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.Format(dateLayout))
+}
@@ -0,0 +1,86 @@
+package animalrescue
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: `"2020-05-01T12:30:00Z"`, want: time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)},
+		{in: `"2020-05-01T12:30:00.123456789Z"`, want: time.Date(2020, 5, 1, 12, 30, 0, 123456789, time.UTC)},
+		{in: `"2020-05-01"`, want: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{in: `1588336200`, want: time.Unix(1588336200, 0)},
+		{in: `1588336200123`, want: time.Unix(1588336200, 123*int64(time.Millisecond))},
+		{in: `1588336200123456789`, want: time.Unix(1588336200, 123456789)},
+		{in: `null`},
+		{in: `""`},
+		{in: `"1588336200"`, wantErr: true},
+		{in: `"20200501"`, wantErr: true},
+		{in: `"yesterday"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var got Timestamp
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%v) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%v) returned error: %v", tt.in, err)
+			continue
+		}
+		if !got.Time.Equal(tt.want) {
+			t.Errorf("Unmarshal(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTimestamp_MarshalJSON(t *testing.T) {
+	at := time.Date(2020, 5, 1, 12, 30, 0, 123000000, time.UTC)
+	tests := []struct {
+		name string
+		in   interface{}
+		want string
+	}{
+		{"timestamp", Timestamp{at}, `"2020-05-01T12:30:00.123Z"`},
+		{"zero timestamp", Timestamp{}, `null`},
+		{"zero field", struct {
+			At Timestamp `json:"at"`
+		}{}, `{"at":null}`},
+		{"zero pointer field", Adoption{CreatedAt: &Timestamp{}}, `{"created_at":null}`},
+		{"birthdate", NewAdopter{Birthdate: &Timestamp{time.Date(1990, 4, 12, 0, 0, 0, 0, time.UTC)}}, `{"birthdate":"1990-04-12"}`},
+		{"birthdate with time", Adopter{Birthdate: &Timestamp{time.Date(1990, 4, 12, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))}}, `{"birthdate":"1990-04-12"}`},
+		{"zero birthdate", NewAdopter{Birthdate: &Timestamp{}}, `{"birthdate":null}`},
+		{"no birthdate", Adopter{FirstName: String("Jane")}, `{"first_name":"Jane"}`},
+	}
+
+	for _, tt := range tests {
+		got, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Errorf("%v: Marshal returned error: %v", tt.name, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("%v: Marshal = %s, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Birthdates read back as the same date.
+	var a Adopter
+	if err := json.Unmarshal([]byte(`{"birthdate":"1990-04-12"}`), &a); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(1990, 4, 12, 0, 0, 0, 0, time.UTC); a.Birthdate == nil || !a.Birthdate.Time.Equal(want) {
+		t.Errorf("Unmarshal decoded birthdate %v, want %v", a.Birthdate, want)
+	}
+}
@@ -0,0 +1,111 @@
+package animalrescue
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Attribute keys set on the spans started by the client. The HTTP keys
+// follow the OpenTelemetry semantic conventions.
+const (
+	AttributeEntity     = "animalrescue.entity"       // entity type, such as "adopter"
+	AttributeEntityID   = "animalrescue.entity_id"    // ID of the entity, for *ByID methods
+	AttributeHTTPMethod = "http.request.method"       // HTTP method of the request
+	AttributeHTTPStatus = "http.response.status_code" // HTTP status of the last response
+	AttributeRetryCount = "http.request.resend_count" // number of retries made
+)
+
+// An Attribute is a key-value pair describing a span. Values are strings,
+// ints or int64s.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// A Tracer starts spans around the client's service calls. Every service
+// method that sends a request, such as AdoptersService.GetAdopterByID, runs
+// in a span named after it, "Adopters.GetAdopterByID", annotated with the
+// Attribute* keys. The otelanimalrescue module adapts OpenTelemetry to this
+// interface.
+type Tracer interface {
+	// Start starts a span with the given name and attributes, as a child
+	// of the span in ctx if there is one, and returns a context holding it.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+
+	// Inject adds the trace context of the span in ctx to the headers of
+	// an outgoing request, so that the API can continue the trace.
+	Inject(ctx context.Context, h http.Header)
+}
+
+// A Span is an operation traced by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+
+	End()
+}
+
+// NoopTracer is a Tracer that records nothing. It is used by clients
+// without a Tracer.
+var NoopTracer Tracer = noopTracer{}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+func (noopTracer) Inject(ctx context.Context, h http.Header) {}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// callKey is the context key of the service call in progress, which Do
+// reports on.
+type callKey struct{}
+
+// A call is a service method call in progress.
+type call struct {
+	service string // such as "Adopters"
+	method  string // such as "GetAdopterByID"
+	span    Span
+}
+
+// startSpan starts the span of the service method name, such as
+// "Adopters.GetAdopterByID", acting on entities of the given type. id is
+// the ID of the entity for *ByID methods, and 0 otherwise. The call is
+// recorded in the returned context for Do to report on.
+func (c *Client) startSpan(ctx context.Context, name, entity string, id int64) (context.Context, Span) {
+	if (c.Tracer == nil && c.Metrics == nil) || ctx == nil {
+		return ctx, noopSpan{}
+	}
+	cl := &call{service: name, span: noopSpan{}}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		cl.service, cl.method = name[:i], name[i+1:]
+	}
+	if c.Tracer != nil {
+		attrs := []Attribute{{AttributeEntity, entity}}
+		if id != 0 {
+			attrs = append(attrs, Attribute{AttributeEntityID, id})
+		}
+		ctx, cl.span = c.Tracer.Start(ctx, name, attrs...)
+	}
+	return context.WithValue(ctx, callKey{}, cl), cl.span
+}
+
+// callFrom returns the service call in progress in ctx, or an empty call
+// with a no-op span for requests sent with Do directly.
+func callFrom(ctx context.Context) *call {
+	if ctx != nil {
+		if cl, ok := ctx.Value(callKey{}).(*call); ok {
+			return cl
+		}
+	}
+	return &call{span: noopSpan{}}
+}
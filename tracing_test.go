@@ -0,0 +1,216 @@
+package animalrescue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+// traceHeader is the header fakeTracer injects the name of the current
+// span into.
+const traceHeader = "X-Test-Span"
+
+// fakeSpan is a span recorded by fakeTracer.
+type fakeSpan struct {
+	name   string
+	attrs  map[string]interface{}
+	errs   []error
+	ended  bool
+	parent *fakeSpan
+}
+
+func (s *fakeSpan) SetAttributes(attrs ...animalrescue.Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *fakeSpan) End() {
+	s.ended = true
+}
+
+type spanKey struct{}
+
+// fakeTracer is a Tracer recording the spans it starts.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string, attrs ...animalrescue.Attribute) (context.Context, animalrescue.Span) {
+	s := &fakeSpan{name: name, attrs: make(map[string]interface{})}
+	s.parent, _ = ctx.Value(spanKey{}).(*fakeSpan)
+	s.SetAttributes(attrs...)
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, h http.Header) {
+	if s, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		h.Set(traceHeader, s.name)
+	}
+}
+
+// tracedClient returns a client of srv tracing with a fakeTracer, and the
+// trace headers of the requests it sent.
+func tracedClient(t *testing.T, srv *animalrescuetest.Server, opts ...animalrescue.Option) (*animalrescue.Client, *fakeTracer, func() []string) {
+	t.Helper()
+	tracer := &fakeTracer{}
+	var (
+		mu      sync.Mutex
+		headers []string
+	)
+	record := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			headers = append(headers, req.Header.Get(traceHeader))
+			mu.Unlock()
+			return next.RoundTrip(req)
+		})
+	}
+	opts = append([]animalrescue.Option{
+		animalrescue.WithBaseURL(srv.URL + "/"),
+		animalrescue.WithTracer(tracer),
+		animalrescue.WithMiddleware(record),
+	}, opts...)
+	client, err := animalrescue.NewClientWithOptions(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, tracer, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), headers...)
+	}
+}
+
+func TestTracer_spans(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tracer, headers := tracedClient(t, srv)
+	ctx := context.Background()
+
+	adopter, _, err := client.Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.Adopters.GetAdopterByID(ctx, *adopter.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name  string
+		attrs map[string]interface{}
+	}{
+		{"Adopters.CreateAdopter", map[string]interface{}{
+			animalrescue.AttributeEntity:     "adopter",
+			animalrescue.AttributeHTTPMethod: "POST",
+			animalrescue.AttributeHTTPStatus: http.StatusCreated,
+			animalrescue.AttributeRetryCount: 0,
+		}},
+		{"Adopters.GetAdopterByID", map[string]interface{}{
+			animalrescue.AttributeEntity:     "adopter",
+			animalrescue.AttributeEntityID:   *adopter.ID,
+			animalrescue.AttributeHTTPMethod: "GET",
+			animalrescue.AttributeHTTPStatus: http.StatusOK,
+			animalrescue.AttributeRetryCount: 0,
+		}},
+	}
+	if len(tracer.spans) != len(want) {
+		t.Fatalf("tracer started %d spans, want %d", len(tracer.spans), len(want))
+	}
+	for i, w := range want {
+		s := tracer.spans[i]
+		if s.name != w.name {
+			t.Errorf("span %d is named %q, want %q", i, s.name, w.name)
+		}
+		if !reflect.DeepEqual(s.attrs, w.attrs) {
+			t.Errorf("span %q has attributes %v, want %v", s.name, s.attrs, w.attrs)
+		}
+		if len(s.errs) != 0 {
+			t.Errorf("span %q recorded errors %v", s.name, s.errs)
+		}
+		if !s.ended {
+			t.Errorf("span %q was not ended", s.name)
+		}
+	}
+
+	if got, want := headers(), []string{"Adopters.CreateAdopter", "Adopters.GetAdopterByID"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("requests carried trace headers %q, want %q", got, want)
+	}
+}
+
+func TestTracer_childOfCallerSpan(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tracer, _ := tracedClient(t, srv)
+
+	ctx, parent := tracer.Start(context.Background(), "caller")
+	if _, _, err := client.Adoptees.ListAll(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("tracer started %d spans, want 2", len(tracer.spans))
+	}
+	if s := tracer.spans[1]; s.parent != parent {
+		t.Errorf("span %q has parent %v, want the caller's span", s.name, s.parent)
+	}
+}
+
+func TestTracer_recordsErrors(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tracer, _ := tracedClient(t, srv)
+
+	_, _, err := client.Adopters.GetAdopterByID(context.Background(), 42)
+	if !errors.Is(err, animalrescue.ErrNotFound) {
+		t.Fatalf("GetAdopterByID returned %v, want ErrNotFound", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("tracer started %d spans, want 1", len(tracer.spans))
+	}
+	s := tracer.spans[0]
+	if len(s.errs) != 1 || s.errs[0] != err {
+		t.Errorf("span recorded errors %v, want [%v]", s.errs, err)
+	}
+	if got := s.attrs[animalrescue.AttributeHTTPStatus]; got != http.StatusNotFound {
+		t.Errorf("span has status %v, want %d", got, http.StatusNotFound)
+	}
+	if !s.ended {
+		t.Error("span was not ended")
+	}
+}
+
+func TestTracer_recordsValidationErrors(t *testing.T) {
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	client, tracer, headers := tracedClient(t, srv, animalrescue.WithValidation())
+
+	_, _, err := client.Adopters.CreateAdopter(context.Background(), animalrescue.NewAdopter{Email: animalrescue.String("jane")})
+	if !errors.Is(err, animalrescue.ErrValidation) {
+		t.Fatalf("CreateAdopter returned %v, want ErrValidation", err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("tracer started %d spans, want 1", len(tracer.spans))
+	}
+	if s := tracer.spans[0]; len(s.errs) != 1 || s.errs[0] != err || !s.ended {
+		t.Errorf("span recorded errors %v, ended %v, want [%v] and ended", s.errs, s.ended, err)
+	}
+	if n := len(headers()); n != 0 {
+		t.Errorf("%d requests were sent, want none", n)
+	}
+}
@@ -0,0 +1,156 @@
+package animalrescue
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// A validator is implemented by the New* types sent in create and edit
+// requests.
+type validator interface {
+	Validate() error
+}
+
+// validate runs v.Validate if the client is configured to validate
+// requests, recording any error on the span of the service call in ctx.
+func (c *Client) validate(ctx context.Context, v validator) error {
+	if !c.ValidateRequests {
+		return nil
+	}
+	err := v.Validate()
+	if err != nil {
+		callFrom(ctx).span.RecordError(err)
+	}
+	return err
+}
+
+// fieldErrors collects the field errors of one resource.
+type fieldErrors struct {
+	resource string
+	errs     []Error
+}
+
+func (f *fieldErrors) add(field, code, format string, args ...interface{}) {
+	f.errs = append(f.errs, Error{
+		Resource: f.resource,
+		Field:    field,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// err returns the collected errors as a *ValidationError, or nil if there
+// are none.
+func (f *fieldErrors) err() error {
+	if len(f.errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: f.errs}
+}
+
+// Validate checks the fields of a that are set, and returns a
+// *ValidationError listing those the API would reject. Fields that are not
+// set are not checked, since they are left unchanged by an edit.
+func (a NewAdopter) Validate() error {
+	f := &fieldErrors{resource: "Adopter"}
+	if a.FirstName != nil && strings.TrimSpace(*a.FirstName) == "" {
+		f.add("first_name", CodeMissingField, "first name is empty")
+	}
+	if a.LastName != nil && strings.TrimSpace(*a.LastName) == "" {
+		f.add("last_name", CodeMissingField, "last name is empty")
+	}
+	if a.Email != nil && !validEmail(*a.Email) {
+		f.add("email", CodeInvalid, "%q is not an email address", *a.Email)
+	}
+	if a.Phone != nil && !validPhone(*a.Phone) {
+		f.add("phone", CodeInvalid, "%q is not a phone number", *a.Phone)
+	}
+	if a.Gender != nil && !individualGender(*a.Gender) {
+		f.add("gender", CodeInvalid, "unknown gender %q", *a.Gender)
+	}
+	if a.Birthdate != nil && !a.Birthdate.IsZero() {
+		if msg := checkBirthdate(*a.Birthdate, time.Now()); msg != "" {
+			f.add("birthdate", CodeInvalid, "%s", msg)
+		}
+	}
+	return f.err()
+}
+
+// Validate checks the fields of a that are set, and returns a
+// *ValidationError listing those the API would reject.
+func (a NewAdoptee) Validate() error {
+	f := &fieldErrors{resource: "Adoptee"}
+	if a.Gender != "" && !individualGender(a.Gender) {
+		f.add("gender", CodeInvalid, "unknown gender %q", a.Gender)
+	}
+	if a.Age != "" && !ParseAgeGroup(string(a.Age)).Known() {
+		f.add("age", CodeInvalid, "unknown age %q", a.Age)
+	}
+	return f.err()
+}
+
+// individualGender reports whether g spells the gender of an adopter or
+// adoptee: a known gender other than GenderAny, which only pet preferences
+// may state.
+func individualGender(g Gender) bool {
+	g = ParseGender(string(g))
+	return g.Known() && g != GenderAny
+}
+
+// Validate checks the fields of pp that are set, and returns a
+// *ValidationError listing those the API would reject.
+func (pp NewPetPreference) Validate() error {
+	f := &fieldErrors{resource: "PetPreference"}
+	if pp.Gender != "" && !ParseGender(string(pp.Gender)).Known() {
+		f.add("gender", CodeInvalid, "unknown gender %q", pp.Gender)
+	}
+	if pp.Age != "" && !ParseAgeGroup(string(pp.Age)).Known() {
+		f.add("age", CodeInvalid, "unknown age %q", pp.Age)
+	}
+	return f.err()
+}
+
+// validEmail reports whether s is a bare email address, such as
+// "jane@example.com", with a dot in its domain.
+func validEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return false
+	}
+	at := strings.LastIndex(s, "@")
+	domain := s[at+1:]
+	return strings.Contains(domain, ".") && !strings.HasSuffix(domain, ".")
+}
+
+// validPhone reports whether s is a phone number of 7 to 15 digits, as
+// allowed by E.164, optionally starting with "+" and grouped with spaces,
+// dots, dashes or parentheses.
+func validPhone(s string) bool {
+	digits := 0
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '.' || r == '-' || r == '(' || r == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// checkBirthdate returns why d is not a possible birthdate as of now, or ""
+// if it is one.
+func checkBirthdate(d Timestamp, now time.Time) string {
+	switch {
+	case d.After(now):
+		return fmt.Sprintf("birthdate %v is in the future", d.Format(dateLayout))
+	case d.Before(now.AddDate(-130, 0, 0)):
+		return fmt.Sprintf("birthdate %v is more than 130 years ago", d.Format(dateLayout))
+	}
+	return ""
+}
@@ -0,0 +1,237 @@
+package animalrescue
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidate_gender(t *testing.T) {
+	tests := []struct {
+		gender        Gender
+		individual    bool // valid for adopters and adoptees
+		petPreference bool
+	}{
+		{"male", true, true},
+		{"F", true, true},
+		{" Unknown ", true, true},
+		{"any", false, true},
+		{"either", false, true},
+		{"robot", false, false},
+	}
+
+	for _, tt := range tests {
+		g := tt.gender
+		if err := (NewAdopter{Gender: &g}).Validate(); (err == nil) != tt.individual {
+			t.Errorf("NewAdopter{Gender: %q}.Validate() = %v, want valid %v", g, err, tt.individual)
+		}
+		if err := (NewAdoptee{Gender: g}).Validate(); (err == nil) != tt.individual {
+			t.Errorf("NewAdoptee{Gender: %q}.Validate() = %v, want valid %v", g, err, tt.individual)
+		}
+		if err := (NewPetPreference{Gender: g}).Validate(); (err == nil) != tt.petPreference {
+			t.Errorf("NewPetPreference{Gender: %q}.Validate() = %v, want valid %v", g, err, tt.petPreference)
+		}
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		valid bool
+	}{
+		{"jane@example.com", true},
+		{"jane.doe+rescue@mail.example.co.uk", true},
+		{"", false},
+		{"jane", false},
+		{"jane@", false},
+		{"@example.com", false},
+		{"jane@localhost", false},
+		{"jane@example.", false},
+		{"jane@@example.com", false},
+		{"Jane <jane@example.com>", false},
+		{" jane@example.com", false},
+		{"jane doe@example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := validEmail(tt.email); got != tt.valid {
+			t.Errorf("validEmail(%q) = %v, want %v", tt.email, got, tt.valid)
+		}
+	}
+}
+
+func TestValidPhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		valid bool
+	}{
+		{"5035550123", true},
+		{"+1 (503) 555-0123", true},
+		{"503.555.0123", true},
+		{"555-0123", true},           // 7 digits
+		{"+123456789012345", true},   // 15 digits
+		{"+1234567890123456", false}, // 16 digits
+		{"555-012", false},           // 6 digits
+		{"", false},
+		{"1+5035550123", false},
+		{"503-555-0123 ext. 4", false},
+		{"503/555/0123", false},
+	}
+
+	for _, tt := range tests {
+		if got := validPhone(tt.phone); got != tt.valid {
+			t.Errorf("validPhone(%q) = %v, want %v", tt.phone, got, tt.valid)
+		}
+	}
+}
+
+func TestCheckBirthdate(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		birthdate time.Time
+		want      string
+	}{
+		{time.Date(1985, 7, 4, 0, 0, 0, 0, time.UTC), ""},
+		{time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), ""},
+		{time.Date(1890, 5, 2, 0, 0, 0, 0, time.UTC), ""},
+		{time.Date(2020, 5, 2, 0, 0, 0, 0, time.UTC), "birthdate 2020-05-02 is in the future"},
+		{time.Date(1890, 4, 30, 0, 0, 0, 0, time.UTC), "birthdate 1890-04-30 is more than 130 years ago"},
+	}
+
+	for _, tt := range tests {
+		if got := checkBirthdate(Timestamp{tt.birthdate}, now); got != tt.want {
+			t.Errorf("checkBirthdate(%v) = %q, want %q", tt.birthdate.Format(dateLayout), got, tt.want)
+		}
+	}
+}
+
+func TestNewAdopter_Validate(t *testing.T) {
+	future := Timestamp{time.Now().AddDate(1, 0, 0)}
+	tests := []struct {
+		name    string
+		adopter NewAdopter
+		fields  []string // fields with errors, in order
+	}{
+		{"empty edit", NewAdopter{}, nil},
+		{
+			"valid",
+			NewAdopter{
+				FirstName: String("Jane"),
+				LastName:  String("Doe"),
+				Email:     String("jane@example.com"),
+				Phone:     String("+1 503 555 0123"),
+				Birthdate: &Timestamp{time.Date(1985, 7, 4, 0, 0, 0, 0, time.UTC)},
+			},
+			nil,
+		},
+		{"zero birthdate", NewAdopter{Birthdate: &Timestamp{}}, nil},
+		{
+			"invalid",
+			NewAdopter{
+				FirstName: String(" "),
+				LastName:  String(""),
+				Email:     String("jane"),
+				Phone:     String("call me"),
+				Birthdate: &future,
+			},
+			[]string{"first_name", "last_name", "email", "phone", "birthdate"},
+		},
+	}
+
+	for _, tt := range tests {
+		err := tt.adopter.Validate()
+		if tt.fields == nil {
+			if err != nil {
+				t.Errorf("%v: Validate returned error: %v", tt.name, err)
+			}
+			continue
+		}
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("%v: Validate returned %v, want a *ValidationError", tt.name, err)
+			continue
+		}
+		if got := verr.Fields(); !reflect.DeepEqual(got, tt.fields) {
+			t.Errorf("%v: Validate reported fields %q, want %q", tt.name, got, tt.fields)
+		}
+		for _, e := range verr.Errors {
+			if e.Resource != "Adopter" || e.Message == "" {
+				t.Errorf("%v: Validate reported %+v, want a described Adopter error", tt.name, e)
+			}
+		}
+	}
+}
+
+// countingTransport answers every request with a JSON object, counting
+// them.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.requests++
+	c.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"id":1}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_ValidateRequests(t *testing.T) {
+	invalid := NewAdopter{Email: String("jane")}
+	valid := NewAdopter{Email: String("jane@example.com")}
+	calls := []struct {
+		name string
+		call func(c *Client, a NewAdopter) error
+	}{
+		{"CreateAdopter", func(c *Client, a NewAdopter) error {
+			_, _, err := c.Adopters.CreateAdopter(context.Background(), a)
+			return err
+		}},
+		{"EditAdopterByID", func(c *Client, a NewAdopter) error {
+			_, _, err := c.Adopters.EditAdopterByID(context.Background(), 1, a)
+			return err
+		}},
+	}
+
+	for _, call := range calls {
+		tr := &countingTransport{}
+		c := NewClient(&http.Client{Transport: tr})
+		c.ValidateRequests = true
+
+		err := call.call(c, invalid)
+		var verr *ValidationError
+		if !errors.As(err, &verr) || !verr.HasField("email") {
+			t.Errorf("%v with an invalid email returned %v, want a *ValidationError for email", call.name, err)
+		}
+		if tr.requests != 0 {
+			t.Errorf("%v sent %d requests for an invalid adopter, want none", call.name, tr.requests)
+		}
+
+		if err := call.call(c, valid); err != nil {
+			t.Errorf("%v with a valid adopter returned error: %v", call.name, err)
+		}
+		if tr.requests != 1 {
+			t.Errorf("%v sent %d requests for a valid adopter, want 1", call.name, tr.requests)
+		}
+
+		// Without ValidateRequests, the API is left to reject it.
+		c.ValidateRequests = false
+		if err := call.call(c, invalid); err != nil {
+			t.Errorf("%v without validation returned error: %v", call.name, err)
+		}
+		if tr.requests != 2 {
+			t.Errorf("%v without validation sent %d requests in all, want 2", call.name, tr.requests)
+		}
+	}
+}
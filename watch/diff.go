@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"reflect"
+	"strings"
+)
+
+// A Change is the change of one field of an updated entity.
+type Change struct {
+	Field string      // JSON name of the field, such as "first_name"
+	Old   interface{} // value before the update
+	New   interface{} // value after the update
+}
+
+// Diff returns the changes between old and new, two pointers to entities
+// of the same type, field by field. Nested entities and lists are compared
+// as a whole. Fields holding pointers, such as Adopter.FirstName, are
+// reported by the values they point to, or nil. It returns nil if the
+// entities are equal.
+func Diff(old, new interface{}) []Change {
+	ov, nv := reflect.Indirect(reflect.ValueOf(old)), reflect.Indirect(reflect.ValueOf(new))
+	if !ov.IsValid() || !nv.IsValid() || ov.Type() != nv.Type() || ov.Kind() != reflect.Struct {
+		if reflect.DeepEqual(old, new) {
+			return nil
+		}
+		return []Change{{Old: old, New: new}}
+	}
+
+	var changes []Change
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		o, n := ov.Field(i).Interface(), nv.Field(i).Interface()
+		if !reflect.DeepEqual(o, n) {
+			changes = append(changes, Change{Field: name, Old: indirect(ov.Field(i)), New: indirect(nv.Field(i))})
+		}
+	}
+	return changes
+}
+
+// indirect returns the value v points to if it is a pointer, or nil if it
+// is a nil pointer, and v itself otherwise.
+func indirect(v reflect.Value) interface{} {
+	if v.Kind() != reflect.Ptr {
+		return v.Interface()
+	}
+	if v.IsNil() {
+		return nil
+	}
+	return v.Elem().Interface()
+}
@@ -0,0 +1,45 @@
+package watch
+
+import (
+	"reflect"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+func TestDiff(t *testing.T) {
+	id := int64(1)
+	tests := []struct {
+		name     string
+		old, new interface{}
+		want     []Change
+	}{
+		{
+			name: "equal",
+			old:  &animalrescue.Adopter{ID: &id, FirstName: animalrescue.String("Jane")},
+			new:  &animalrescue.Adopter{ID: &id, FirstName: animalrescue.String("Jane")},
+		},
+		{
+			name: "pointer fields",
+			old:  &animalrescue.Adopter{ID: &id, FirstName: animalrescue.String("Jane")},
+			new:  &animalrescue.Adopter{ID: &id, FirstName: animalrescue.String("Janet"), City: animalrescue.String("Austin")},
+			want: []Change{
+				{Field: "first_name", Old: "Jane", New: "Janet"},
+				{Field: "city", Old: nil, New: "Austin"},
+			},
+		},
+		{
+			name: "value fields",
+			old:  &animalrescue.Adoptee{ID: 2, Name: "Rex", Age: animalrescue.AgePuppy},
+			new:  &animalrescue.Adoptee{ID: 2, Name: "Rex", Age: animalrescue.AgeYoung},
+			want: []Change{{Field: "age", Old: animalrescue.AgePuppy, New: animalrescue.AgeYoung}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := Diff(tt.old, tt.new)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: Diff returned %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package watch
+
+import (
+	"context"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// A Source lists the entities watched by a Watcher.
+type Source interface {
+	// List returns every entity currently in the source, keyed by ID. The
+	// entities are pointers to one of the animalrescue entity types.
+	List(ctx context.Context) (map[int64]interface{}, error)
+}
+
+// SourceFunc adapts an ordinary function to the Source interface.
+type SourceFunc func(ctx context.Context) (map[int64]interface{}, error)
+
+// List calls f(ctx).
+func (f SourceFunc) List(ctx context.Context) (map[int64]interface{}, error) {
+	return f(ctx)
+}
+
+// Adopters returns a Source listing the adopters matching opts, which may be
+// nil.
+func Adopters(c *animalrescue.Client, opts *animalrescue.AdopterListOptions) Source {
+	return SourceFunc(func(ctx context.Context) (map[int64]interface{}, error) {
+		m := make(map[int64]interface{})
+		it := c.Adopters.Iter(ctx, opts)
+		for it.Next() {
+			if a := it.Value(); a != nil && a.ID != nil {
+				m[*a.ID] = a
+			}
+		}
+		return m, it.Err()
+	})
+}
+
+// Adoptees returns a Source listing the adoptees matching opts, which may be
+// nil.
+func Adoptees(c *animalrescue.Client, opts *animalrescue.AdopteeListOptions) Source {
+	return SourceFunc(func(ctx context.Context) (map[int64]interface{}, error) {
+		m := make(map[int64]interface{})
+		it := c.Adoptees.Iter(ctx, opts)
+		for it.Next() {
+			if a := it.Value(); a != nil {
+				m[int64(a.ID)] = a
+			}
+		}
+		return m, it.Err()
+	})
+}
+
+// Adoptions returns a Source listing the adoptions matching opts, which may
+// be nil.
+func Adoptions(c *animalrescue.Client, opts *animalrescue.AdoptionListOptions) Source {
+	return SourceFunc(func(ctx context.Context) (map[int64]interface{}, error) {
+		m := make(map[int64]interface{})
+		it := c.Adoptions.Iter(ctx, opts)
+		for it.Next() {
+			if a := it.Value(); a != nil {
+				m[int64(a.ID)] = a
+			}
+		}
+		return m, it.Err()
+	})
+}
+
+// PetPreferences returns a Source listing the pet preferences, starting
+// from the page described by opts, which may be nil.
+func PetPreferences(c *animalrescue.Client, opts *animalrescue.ListOptions) Source {
+	return SourceFunc(func(ctx context.Context) (map[int64]interface{}, error) {
+		m := make(map[int64]interface{})
+		it := c.PetPreferences.Iter(ctx, opts)
+		for it.Next() {
+			if p := it.Value(); p != nil {
+				m[int64(p.ID)] = p
+			}
+		}
+		return m, it.Err()
+	})
+}
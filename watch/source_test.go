@@ -0,0 +1,50 @@
+package watch
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSources_skipNull(t *testing.T) {
+	client, err := animalrescue.NewClientWithOptions(
+		animalrescue.WithHTTPClient(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`[null, {"id": 1}]`)),
+				Request:    req,
+			}, nil
+		})}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sources := map[string]Source{
+		"adopters":        Adopters(client, nil),
+		"adoptees":        Adoptees(client, nil),
+		"adoptions":       Adoptions(client, nil),
+		"pet preferences": PetPreferences(client, nil),
+	}
+	for name, s := range sources {
+		m, err := s.List(context.Background())
+		if err != nil {
+			t.Errorf("%v: List returned error: %v", name, err)
+			continue
+		}
+		if len(m) != 1 || m[1] == nil {
+			t.Errorf("%v: List returned %v, want only the entity with ID 1", name, m)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package watch reports changes to Animal Rescue API entities by polling.
+//
+// A Watcher lists the entities of a Source at a regular interval, compares
+// each listing with the previous one by ID, and emits an Event for every
+// entity that was added, updated or removed in between:
+//
+//	w := watch.New(watch.Adoptees(client, nil))
+//	w.Interval = time.Minute
+//	for e := range w.Watch(ctx) {
+//		switch e.Type {
+//		case watch.Added:
+//			fmt.Println("new arrival:", e.Entity.(*animalrescue.Adoptee).Name)
+//		case watch.Updated:
+//			for _, c := range e.Changes {
+//				fmt.Printf("%v: %v -> %v\n", c.Field, c.Old, c.New)
+//			}
+//		}
+//	}
+//	if err := w.Err(); err != nil && err != context.Canceled {
+//		log.Fatal(err)
+//	}
+package watch
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// EventType is the kind of change reported by an Event.
+type EventType int
+
+// Event types.
+const (
+	Added EventType = iota + 1
+	Updated
+	Removed
+)
+
+func (t EventType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Updated:
+		return "updated"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// An Event reports the change of one entity between two listings.
+type Event struct {
+	Type EventType
+	ID   int64
+
+	// Entity is the entity as of the latest listing, or the last listing
+	// it was seen in for Removed events.
+	Entity interface{}
+
+	// Previous is the entity as of the previous listing, for Updated
+	// events.
+	Previous interface{}
+
+	// Changes lists the fields that changed, for Updated events.
+	Changes []Change
+}
+
+const (
+	// DefaultInterval is the default time between two listings.
+	DefaultInterval = 30 * time.Second
+
+	// DefaultMaxBackoff is the default maximum time between two listings
+	// after errors.
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// A Watcher polls a Source for changes. Its fields must not be modified
+// once Watch has been called, and Watch must be called at most once.
+type Watcher struct {
+	Source Source
+
+	// Interval is the time between two listings.
+	// Default: DefaultInterval.
+	Interval time.Duration
+
+	// Jitter randomly varies each interval by up to this fraction of it,
+	// so that many watchers do not poll in lockstep. It must be between
+	// 0 and 1.
+	Jitter float64
+
+	// MaxBackoff caps the time between two listings after consecutive
+	// errors, which doubles with each of them.
+	// Default: DefaultMaxBackoff.
+	MaxBackoff time.Duration
+
+	// EmitInitial makes the watcher emit the entities of the first listing
+	// as Added events. Otherwise the first listing only serves as the
+	// baseline for the next.
+	EmitInitial bool
+
+	// OnError, if set, is called with each error that the watcher recovers
+	// from, and the time it waits before listing again.
+	OnError func(err error, wait time.Duration)
+
+	mu  sync.Mutex
+	err error
+}
+
+// New returns a Watcher polling src with the default settings.
+func New(src Source) *Watcher {
+	return &Watcher{Source: src}
+}
+
+// Watch starts polling the source and returns the channel on which events
+// are emitted. The events of one listing are emitted ordered by ID. The
+// channel is closed once ctx is canceled or an unrecoverable error occurs;
+// Err then reports why.
+//
+// Authentication failures and listings of missing resources are not
+// recovered from. Rate-limited listings are retried once the rate limit
+// resets, and other failures after a backoff.
+func (w *Watcher) Watch(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		err := w.run(ctx, events)
+		w.mu.Lock()
+		w.err = err
+		w.mu.Unlock()
+	}()
+	return events
+}
+
+// Err returns the reason the event channel was closed: the context error,
+// or the error that stopped the watcher. It returns nil while the watcher
+// is running.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *Watcher) run(ctx context.Context, events chan<- Event) error {
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var (
+		prev     map[int64]interface{}
+		failures int
+	)
+	for {
+		cur, err := w.Source.List(ctx)
+		var wait time.Duration
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil:
+			if permanent(err) {
+				return err
+			}
+			failures++
+			wait = w.backoff(err, failures)
+			if w.OnError != nil {
+				w.OnError(err, wait)
+			}
+		default:
+			failures = 0
+			if prev != nil || w.EmitInitial {
+				for _, e := range diff(prev, cur) {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+			prev = cur
+			wait = w.interval(rnd)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// interval returns the time to wait after a successful listing.
+func (w *Watcher) interval(rnd *rand.Rand) time.Duration {
+	d := w.Interval
+	if d <= 0 {
+		d = DefaultInterval
+	}
+	if w.Jitter > 0 {
+		d += time.Duration((rnd.Float64()*2 - 1) * w.Jitter * float64(d))
+	}
+	return d
+}
+
+// backoff returns the time to wait after the given number of consecutive
+// failed listings, the last of which failed with err.
+func (w *Watcher) backoff(err error, failures int) time.Duration {
+	var rerr *animalrescue.RateLimitError
+	if errors.As(err, &rerr) {
+		if d := time.Until(rerr.Rate.Reset.Time); d > 0 {
+			return d
+		}
+	}
+	var aerr *animalrescue.AbuseRateLimitError
+	if errors.As(err, &aerr) && aerr.RetryAfter != nil {
+		return *aerr.RetryAfter
+	}
+
+	d := w.Interval
+	if d <= 0 {
+		d = DefaultInterval
+	}
+	max := w.MaxBackoff
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	return d
+}
+
+// permanent reports whether err will not go away by listing again.
+func permanent(err error) bool {
+	return errors.Is(err, animalrescue.ErrUnauthorized) || errors.Is(err, animalrescue.ErrNotFound)
+}
+
+// diff returns the events turning prev into cur, ordered by ID.
+func diff(prev, cur map[int64]interface{}) []Event {
+	var events []Event
+	for id, e := range cur {
+		old, ok := prev[id]
+		if !ok {
+			events = append(events, Event{Type: Added, ID: id, Entity: e})
+			continue
+		}
+		if changes := Diff(old, e); len(changes) > 0 {
+			events = append(events, Event{Type: Updated, ID: id, Entity: e, Previous: old, Changes: changes})
+		}
+	}
+	for id, e := range prev {
+		if _, ok := cur[id]; !ok {
+			events = append(events, Event{Type: Removed, ID: id, Entity: e})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events
+}
@@ -0,0 +1,278 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// listing is the result of one List call of a script.
+type listing struct {
+	entities map[int64]interface{}
+	err      error
+}
+
+// script is a Source returning the listings sent on it in turn, and
+// blocking until the next is sent.
+type script chan listing
+
+func (s script) List(ctx context.Context) (map[int64]interface{}, error) {
+	select {
+	case l := <-s:
+		return l.entities, l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// adoptees returns a listing of adoptees with the given names, whose IDs
+// are their positions from 1. Empty names are left out.
+func adoptees(names ...string) map[int64]interface{} {
+	m := make(map[int64]interface{})
+	for i, name := range names {
+		if name != "" {
+			m[int64(i+1)] = &animalrescue.Adoptee{ID: i + 1, Name: name}
+		}
+	}
+	return m
+}
+
+// next returns the next event of events, failing the test if none comes.
+func next(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		if !ok {
+			t.Fatal("event channel closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event emitted")
+	}
+	return Event{}
+}
+
+// closed waits for events to be closed, failing the test if it is not or if
+// an event is emitted.
+func closed(t *testing.T, events <-chan Event) {
+	t.Helper()
+	select {
+	case e, ok := <-events:
+		if ok {
+			t.Fatalf("unexpected %v event for %d", e.Type, e.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event channel not closed")
+	}
+}
+
+type wantEvent struct {
+	typ     EventType
+	id      int64
+	changes []Change
+}
+
+func checkEvents(t *testing.T, events <-chan Event, want []wantEvent) {
+	t.Helper()
+	for _, w := range want {
+		e := next(t, events)
+		if e.Type != w.typ || e.ID != w.id || !reflect.DeepEqual(e.Changes, w.changes) {
+			t.Errorf("got %v event for %d with changes %v, want %v event for %d with changes %v",
+				e.Type, e.ID, e.Changes, w.typ, w.id, w.changes)
+		}
+	}
+}
+
+func TestWatcher_events(t *testing.T) {
+	src := make(script, 3)
+	src <- listing{entities: adoptees("Rex")}
+	src <- listing{entities: adoptees("Max", "Bella")}
+	src <- listing{entities: adoptees("", "Bella")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{Source: src, Interval: time.Millisecond}
+	events := w.Watch(ctx)
+
+	// The first listing is only the baseline.
+	checkEvents(t, events, []wantEvent{
+		{Updated, 1, []Change{{Field: "name", Old: "Rex", New: "Max"}}},
+		{Added, 2, nil},
+		{Removed, 1, nil},
+	})
+
+	cancel()
+	closed(t, events)
+	if err := w.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+}
+
+func TestWatcher_emitInitial(t *testing.T) {
+	src := make(script, 2)
+	src <- listing{entities: adoptees("Rex", "", "Bella")}
+	src <- listing{entities: adoptees("Rex", "", "Bella")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{Source: src, Interval: time.Millisecond, EmitInitial: true}
+	events := w.Watch(ctx)
+
+	checkEvents(t, events, []wantEvent{
+		{Added, 1, nil},
+		{Added, 3, nil},
+	})
+
+	// The unchanged second listing emits nothing.
+	cancel()
+	closed(t, events)
+}
+
+func TestWatcher_backoff(t *testing.T) {
+	failed := errors.New("503 Service Unavailable")
+	retryAfter := 4 * time.Millisecond
+	src := make(script, 7)
+	for _, err := range []error{
+		failed,
+		failed,
+		failed,
+		failed,
+		&animalrescue.AbuseRateLimitError{RetryAfter: &retryAfter},
+		nil,
+		failed,
+	} {
+		src <- listing{entities: adoptees("Rex"), err: err}
+	}
+
+	type failure struct {
+		err  error
+		wait time.Duration
+	}
+	failures := make(chan failure, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{
+		Source:     src,
+		Interval:   time.Millisecond,
+		MaxBackoff: 5 * time.Millisecond,
+		OnError: func(err error, wait time.Duration) {
+			failures <- failure{err, wait}
+		},
+	}
+	events := w.Watch(ctx)
+
+	// Backoffs double up to MaxBackoff, follow Retry-After and start over
+	// after a successful listing.
+	want := []time.Duration{
+		time.Millisecond,
+		2 * time.Millisecond,
+		4 * time.Millisecond,
+		5 * time.Millisecond,
+		retryAfter,
+		time.Millisecond,
+	}
+	for i, wait := range want {
+		select {
+		case f := <-failures:
+			if f.wait != wait {
+				t.Errorf("failure %d: waited %v, want %v", i+1, f.wait, wait)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("failure %d not reported", i+1)
+		}
+	}
+
+	cancel()
+	closed(t, events)
+}
+
+func TestWatcher_rateLimit(t *testing.T) {
+	reset := time.Now().Add(time.Hour)
+	src := make(script, 1)
+	src <- listing{err: &animalrescue.RateLimitError{
+		Rate: animalrescue.Rate{Limit: 60, Reset: animalrescue.Timestamp{Time: reset}},
+	}}
+
+	waits := make(chan time.Duration, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watcher{
+		Source:   src,
+		Interval: time.Millisecond,
+		OnError: func(err error, wait time.Duration) {
+			waits <- wait
+		},
+	}
+	events := w.Watch(ctx)
+
+	select {
+	case wait := <-waits:
+		if wait <= 59*time.Minute || wait > time.Hour {
+			t.Errorf("waited %v after a rate limit resetting in an hour", wait)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rate limit not reported")
+	}
+
+	// Canceling ctx stops the watcher while it waits.
+	cancel()
+	closed(t, events)
+	if err := w.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+}
+
+func TestWatcher_permanentError(t *testing.T) {
+	for _, sentinel := range []error{animalrescue.ErrUnauthorized, animalrescue.ErrNotFound} {
+		err := fmt.Errorf("listing adoptees: %w", sentinel)
+		src := make(script, 2)
+		src <- listing{entities: adoptees("Rex")}
+		src <- listing{err: err}
+
+		w := &Watcher{
+			Source:   src,
+			Interval: time.Millisecond,
+			OnError: func(err error, wait time.Duration) {
+				t.Errorf("OnError called with %v, which is permanent", err)
+			},
+		}
+		events := w.Watch(context.Background())
+
+		closed(t, events)
+		if got := w.Err(); got != err {
+			t.Errorf("Err() = %v, want %v", got, err)
+		}
+	}
+}
+
+func TestWatcher_canceledWhileEmitting(t *testing.T) {
+	src := make(script, 1)
+	src <- listing{entities: adoptees("Rex", "Bella")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher{Source: src, Interval: time.Millisecond, EmitInitial: true}
+	events := w.Watch(ctx)
+
+	// The second event is never received.
+	checkEvents(t, events, []wantEvent{{Added, 1, nil}})
+	cancel()
+	select {
+	case e, ok := <-events:
+		// The second event may have been ready before the cancellation.
+		if ok && e.ID == 2 {
+			closed(t, events)
+		} else if ok {
+			t.Errorf("unexpected %v event for %d", e.Type, e.ID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event channel not closed")
+	}
+	if err := w.Err(); err != context.Canceled {
+		t.Errorf("Err() = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Event types, as sent in the "type" field of a payload.
+const (
+	TypeAdoptionCreated = "adoption.created"
+	TypeAdopteeUpdated  = "adoptee.updated"
+	TypeAdopterDeleted  = "adopter.deleted"
+)
+
+// An Event is one of the typed events returned by ParseEvent:
+// *AdoptionCreatedEvent, *AdopteeUpdatedEvent, *AdopterDeletedEvent, or
+// *UnknownEvent for types this package does not know.
+type Event interface {
+	// Meta returns the fields common to all events.
+	Meta() *EventMeta
+}
+
+// EventMeta holds the fields common to all events.
+type EventMeta struct {
+	// ID uniquely identifies the event. Deliveries that are retried keep
+	// their ID, so it can be used to ignore duplicates.
+	ID        string                  `json:"id"`
+	Type      string                  `json:"type"`
+	CreatedAt *animalrescue.Timestamp `json:"created_at,omitempty"`
+}
+
+// Meta implements the Event interface.
+func (m *EventMeta) Meta() *EventMeta {
+	return m
+}
+
+// AdoptionCreatedEvent is sent when an adoption is created.
+type AdoptionCreatedEvent struct {
+	EventMeta
+	Adoption *animalrescue.Adoption
+}
+
+// AdopteeUpdatedEvent is sent when an adoptee is edited. Adoptee holds its
+// new state.
+type AdopteeUpdatedEvent struct {
+	EventMeta
+	Adoptee *animalrescue.Adoptee
+}
+
+// AdopterDeletedEvent is sent when an adopter is deleted.
+type AdopterDeletedEvent struct {
+	EventMeta
+	AdopterID int64
+}
+
+// UnknownEvent is an event of a type this package does not know. Data holds
+// its undecoded payload.
+type UnknownEvent struct {
+	EventMeta
+	Data json.RawMessage
+}
+
+// payload is the JSON body of a webhook delivery.
+type payload struct {
+	EventMeta
+	Data json.RawMessage `json:"data"`
+}
+
+// ParseEvent decodes the body of a webhook delivery into a typed Event. It
+// does not verify the signature of the delivery; see VerifySignature. Events
+// of a known type with missing or null data are rejected.
+func ParseEvent(body []byte) (Event, error) {
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("webhooks: invalid payload: %v", err)
+	}
+
+	var (
+		event Event
+		data  interface{}
+	)
+	switch p.Type {
+	case TypeAdoptionCreated:
+		e := &AdoptionCreatedEvent{EventMeta: p.EventMeta}
+		event, data = e, &e.Adoption
+	case TypeAdopteeUpdated:
+		e := &AdopteeUpdatedEvent{EventMeta: p.EventMeta}
+		event, data = e, &e.Adoptee
+	case TypeAdopterDeleted:
+		e := &AdopterDeletedEvent{EventMeta: p.EventMeta}
+		event, data = e, &struct {
+			ID *int64 `json:"id"`
+		}{&e.AdopterID}
+	default:
+		return &UnknownEvent{EventMeta: p.EventMeta, Data: p.Data}, nil
+	}
+
+	if len(p.Data) == 0 || string(p.Data) == "null" {
+		return nil, fmt.Errorf("webhooks: %v event without data", p.Type)
+	}
+	if err := json.Unmarshal(p.Data, data); err != nil {
+		return nil, fmt.Errorf("webhooks: invalid %v data: %v", p.Type, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,258 @@
+// Package webhooks receives the webhook deliveries of the Animal Rescue API.
+//
+// A Handler verifies the signature of each delivery, decodes it into a
+// typed event and passes it to the callbacks registered for its type:
+//
+//	h := webhooks.NewHandler([]byte(os.Getenv("WEBHOOK_SECRET")))
+//	h.OnAdoptionCreated(func(ctx context.Context, e *webhooks.AdoptionCreatedEvent) error {
+//		log.Printf("adoptee %v was adopted", e.Adoption.Adoptee.Name)
+//		return nil
+//	})
+//	http.Handle("/webhooks", h)
+//
+// Deliveries are signed with HMAC-SHA256 over the delivery timestamp and
+// body, using a secret shared with the API. Deliveries whose timestamp is
+// too far from the current time are rejected, so that a captured delivery
+// cannot be replayed later.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+)
+
+// Headers of a webhook delivery.
+const (
+	// HeaderTimestamp holds the time the delivery was sent, in Unix
+	// seconds.
+	HeaderTimestamp = "X-AnimalRescue-Timestamp"
+
+	// HeaderSignature holds the signature of the delivery, as computed by
+	// Sign.
+	HeaderSignature = "X-AnimalRescue-Signature"
+)
+
+const (
+	// DefaultTolerance is the default maximum difference between the
+	// timestamp of a delivery and the time it is received.
+	DefaultTolerance = 5 * time.Minute
+
+	// DefaultMaxBodyBytes is the default maximum size of a delivery body.
+	DefaultMaxBodyBytes = 1 << 20
+)
+
+// Errors returned by VerifySignature.
+var (
+	ErrMissingSignature    = errors.New("webhooks: missing signature or timestamp")
+	ErrInvalidSignature    = errors.New("webhooks: invalid signature")
+	ErrTimestampOutOfRange = errors.New("webhooks: timestamp outside of tolerance")
+)
+
+// Sign returns the value of the HeaderSignature header for a delivery of
+// body sent at time t, which must also be sent in the HeaderTimestamp
+// header. It is useful for sending deliveries in tests.
+func Sign(secret []byte, t time.Time, body []byte) string {
+	return "sha256=" + hex.EncodeToString(mac(secret, t.Unix(), body))
+}
+
+func mac(secret []byte, timestamp int64, body []byte) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	m.Write([]byte("."))
+	m.Write(body)
+	return m.Sum(nil)
+}
+
+// VerifySignature checks that the delivery with the given headers and body
+// was signed with secret, and sent no more than tolerance from now.
+func VerifySignature(secret []byte, header http.Header, body []byte, tolerance time.Duration) error {
+	return verify(secret, header, body, tolerance, time.Now())
+}
+
+func verify(secret []byte, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	ts, sig := header.Get(HeaderTimestamp), header.Get(HeaderSignature)
+	if ts == "" || sig == "" {
+		return ErrMissingSignature
+	}
+	timestamp, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	// Several signatures may be sent while the secret is being rotated.
+	ok := false
+	want := mac(secret, timestamp, body)
+	for _, s := range strings.Split(sig, ",") {
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "sha256=") {
+			continue
+		}
+		got, err := hex.DecodeString(strings.TrimPrefix(s, "sha256="))
+		if err == nil && hmac.Equal(got, want) {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+
+	if d := now.Sub(time.Unix(timestamp, 0)); d > tolerance || d < -tolerance {
+		return ErrTimestampOutOfRange
+	}
+	return nil
+}
+
+// Handler is an http.Handler that receives webhook deliveries and
+// dispatches them to callbacks. Callbacks of an event are called in the
+// order they were registered; if one returns an error, the remaining ones
+// are skipped and the delivery is answered with 500 Internal Server Error,
+// so that the API sends it again later. Deliveries are otherwise answered
+// with 204 No Content, including those of events without callbacks.
+//
+// Callbacks may be registered while the handler is serving.
+type Handler struct {
+	secret []byte
+
+	// Tolerance is the maximum difference between the timestamp of a
+	// delivery and the time it is received.
+	// Default: DefaultTolerance.
+	Tolerance time.Duration
+
+	// MaxBodyBytes is the maximum size of a delivery body.
+	// Default: DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+
+	// ErrorLog, if set, receives the reasons deliveries were rejected and
+	// the errors returned by callbacks.
+	ErrorLog animalrescue.Logger
+
+	mu        sync.RWMutex
+	callbacks map[string][]func(context.Context, Event) error
+	any       []func(context.Context, Event) error
+}
+
+// NewHandler returns a Handler verifying deliveries with secret.
+func NewHandler(secret []byte) *Handler {
+	return &Handler{
+		secret:    secret,
+		callbacks: make(map[string][]func(context.Context, Event) error),
+	}
+}
+
+func (h *Handler) on(typ string, fn func(context.Context, Event) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callbacks[typ] = append(h.callbacks[typ], fn)
+}
+
+// OnAdoptionCreated registers fn to be called with each AdoptionCreatedEvent.
+func (h *Handler) OnAdoptionCreated(fn func(context.Context, *AdoptionCreatedEvent) error) {
+	h.on(TypeAdoptionCreated, func(ctx context.Context, e Event) error {
+		return fn(ctx, e.(*AdoptionCreatedEvent))
+	})
+}
+
+// OnAdopteeUpdated registers fn to be called with each AdopteeUpdatedEvent.
+func (h *Handler) OnAdopteeUpdated(fn func(context.Context, *AdopteeUpdatedEvent) error) {
+	h.on(TypeAdopteeUpdated, func(ctx context.Context, e Event) error {
+		return fn(ctx, e.(*AdopteeUpdatedEvent))
+	})
+}
+
+// OnAdopterDeleted registers fn to be called with each AdopterDeletedEvent.
+func (h *Handler) OnAdopterDeleted(fn func(context.Context, *AdopterDeletedEvent) error) {
+	h.on(TypeAdopterDeleted, func(ctx context.Context, e Event) error {
+		return fn(ctx, e.(*AdopterDeletedEvent))
+	})
+}
+
+// OnEvent registers fn to be called with every event, including those of
+// unknown types, after the callbacks registered for its type.
+func (h *Handler) OnEvent(fn func(context.Context, Event) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.any = append(h.any, fn)
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	max := h.MaxBodyBytes
+	if max <= 0 {
+		max = DefaultMaxBodyBytes
+	}
+	// One byte past the limit is read to tell bodies over it apart from
+	// failed reads.
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		h.logf("reading delivery: %v", err)
+		http.Error(w, "cannot read request body", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > max {
+		h.logf("rejected delivery: body larger than %d bytes", max)
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	tolerance := h.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	if err := verify(h.secret, r.Header, body, tolerance, time.Now()); err != nil {
+		h.logf("rejected delivery: %v", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event, err := ParseEvent(body)
+	if err != nil {
+		h.logf("rejected delivery: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), event); err != nil {
+		h.logf("handling %v event %v: %v", event.Meta().Type, event.Meta().ID, err)
+		http.Error(w, "event not handled", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) dispatch(ctx context.Context, event Event) error {
+	h.mu.RLock()
+	fns := append(append([]func(context.Context, Event) error(nil), h.callbacks[event.Meta().Type]...), h.any...)
+	h.mu.RUnlock()
+
+	for _, fn := range fns {
+		if err := fn(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) logf(format string, v ...interface{}) {
+	if h.ErrorLog != nil {
+		h.ErrorLog.Printf("webhooks: "+format, v...)
+	}
+}
@@ -0,0 +1,177 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	animalrescue "github.com/anGie44/go-animal-rescue"
+	"github.com/anGie44/go-animal-rescue/animalrescuetest"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{"id":"evt_1","type":"adopter.deleted","data":{"id":1}}`)
+	now := time.Unix(1588336200, 0)
+	sig := Sign(secret, now, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		want      error
+	}{
+		{name: "valid", signature: sig},
+		{name: "rotated secret", signature: Sign([]byte("old"), now, body) + ", " + sig},
+		{name: "wrong secret", signature: Sign([]byte("guess"), now, body), want: ErrInvalidSignature},
+		{name: "tampered body", signature: sig, body: []byte(`{"id":"evt_1","type":"adopter.deleted","data":{"id":2}}`), want: ErrInvalidSignature},
+		{name: "other timestamp", timestamp: "1588336201", signature: sig, want: ErrInvalidSignature},
+		{name: "missing signature", want: ErrMissingSignature},
+		{name: "missing timestamp", timestamp: "-", signature: sig, want: ErrMissingSignature},
+		{name: "invalid timestamp", timestamp: "yesterday", signature: sig, want: ErrInvalidSignature},
+		{name: "unknown scheme", signature: "sha1=" + strings.TrimPrefix(sig, "sha256="), want: ErrInvalidSignature},
+		{name: "invalid hex", signature: "sha256=zz", want: ErrInvalidSignature},
+		{name: "too old", timestamp: "1588335899", signature: Sign(secret, now.Add(-301*time.Second), body), want: ErrTimestampOutOfRange},
+		{name: "too far ahead", timestamp: "1588336501", signature: Sign(secret, now.Add(301*time.Second), body), want: ErrTimestampOutOfRange},
+		{name: "within tolerance", timestamp: "1588335900", signature: Sign(secret, now.Add(-300*time.Second), body)},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		switch tt.timestamp {
+		case "":
+			header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+		case "-":
+		default:
+			header.Set(HeaderTimestamp, tt.timestamp)
+		}
+		if tt.signature != "" {
+			header.Set(HeaderSignature, tt.signature)
+		}
+		b := tt.body
+		if b == nil {
+			b = body
+		}
+		if err := verify(secret, header, b, DefaultTolerance, now); err != tt.want {
+			t.Errorf("%v: verify returned %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+// errReader is a request body whose reads fail.
+type errReader struct{}
+
+func (errReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestHandler(t *testing.T) {
+	// The adoption delivered is one created on the fake server.
+	srv := animalrescuetest.NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+	adopter, _, err := srv.Client().Adopters.CreateAdopter(ctx, animalrescue.NewAdopter{FirstName: animalrescue.String("Jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoptee, _, err := srv.Client().Adoptees.CreateAdoptee(ctx, animalrescue.NewAdoptee{Name: "Rex"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adoption, _, err := srv.Client().Adoptions.CreateAdoption(ctx, animalrescue.NewAdoption{Adopter: adopter, Adoptee: adoptee})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(adoption)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := `{"id":"evt_1","type":"adoption.created","data":` + string(data) + `}`
+
+	secret := []byte("s3cret")
+	tests := []struct {
+		name     string
+		method   string
+		body     string
+		secret   []byte
+		fail     bool // whether the callbacks fail
+		readErr  bool // whether reading the body fails after it
+		status   int
+		received []string // types of the events received by OnEvent
+	}{
+		{name: "delivered", body: created, status: http.StatusNoContent, received: []string{TypeAdoptionCreated}},
+		{name: "unknown type", body: `{"id":"evt_2","type":"adoptee.created","data":{}}`, status: http.StatusNoContent, received: []string{"adoptee.created"}},
+		{name: "wrong method", method: "GET", body: created, status: http.StatusMethodNotAllowed},
+		{name: "wrong secret", body: created, secret: []byte("guess"), status: http.StatusUnauthorized},
+		{name: "too large", body: `{"id":"evt_3","type":"adoptee.updated","data":"` + strings.Repeat("x", 1024) + `"}`, status: http.StatusRequestEntityTooLarge},
+		{name: "at the limit", body: `{"id":"evt_5","type":"adoptee.noted","data":"` + strings.Repeat("x", 1024-47) + `"}`, status: http.StatusNoContent, received: []string{"adoptee.noted"}},
+		{name: "read error", body: created, readErr: true, status: http.StatusBadRequest},
+		{name: "invalid payload", body: `{"id":"evt_4","type":"adoption.created"}`, status: http.StatusBadRequest},
+		{name: "null data", body: `{"id":"evt_6","type":"adoption.created","data":null}`, status: http.StatusBadRequest},
+		{name: "null adoptee", body: `{"id":"evt_7","type":"adoptee.updated","data": null }`, status: http.StatusBadRequest},
+		{name: "null adopter", body: `{"id":"evt_8","type":"adopter.deleted","data":null}`, status: http.StatusBadRequest},
+		{name: "callback error", body: created, fail: true, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(secret)
+			h.MaxBodyBytes = 1024
+			var (
+				got      *animalrescue.Adoption
+				received []string
+			)
+			h.OnAdoptionCreated(func(ctx context.Context, e *AdoptionCreatedEvent) error {
+				if tt.fail {
+					return errors.New("database is down")
+				}
+				got = e.Adoption
+				return nil
+			})
+			h.OnEvent(func(ctx context.Context, e Event) error {
+				received = append(received, e.Meta().Type)
+				return nil
+			})
+
+			method := tt.method
+			if method == "" {
+				method = "POST"
+			}
+			key := tt.secret
+			if key == nil {
+				key = secret
+			}
+			now := time.Now()
+			var body io.Reader = bytes.NewBufferString(tt.body)
+			if tt.readErr {
+				body = io.MultiReader(body, errReader{})
+			}
+			req := httptest.NewRequest(method, "/webhooks", body)
+			req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+			req.Header.Set(HeaderSignature, Sign(key, now, []byte(tt.body)))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("delivery answered with %d, want %d", rec.Code, tt.status)
+			}
+			if strings.Join(received, ",") != strings.Join(tt.received, ",") {
+				t.Errorf("OnEvent received %v, want %v", received, tt.received)
+			}
+			if tt.status == http.StatusNoContent && len(received) > 0 && received[0] == TypeAdoptionCreated {
+				if got == nil || got.ID != adoption.ID || got.Adoptee == nil || got.Adoptee.Name != "Rex" {
+					t.Errorf("OnAdoptionCreated received %v, want %v", got, adoption)
+				}
+			}
+		})
+	}
+}